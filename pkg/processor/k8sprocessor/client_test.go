@@ -15,6 +15,7 @@
 package k8sprocessor
 
 import (
+	"sync/atomic"
 	"time"
 
 	"go.uber.org/zap"
@@ -30,11 +31,16 @@ import (
 // fakeClient is used as a replacement for WatchClient in test cases.
 type fakeClient struct {
 	Pods         map[kube.PodIdentifier]*kube.Pod
+	Services     map[string]map[string]string
 	Rules        kube.ExtractionRules
 	Filters      kube.Filters
 	Associations []kube.Association
 	Informer     cache.SharedInformer
 	StopCh       chan struct{}
+
+	PodEventHandler kube.PodEventHandler
+	Synced          atomic.Bool
+	SyncErr         error
 }
 
 func selectors() (labels.Selector, fields.Selector) {
@@ -63,6 +69,7 @@ func newFakeClient(
 	ls, fs := selectors()
 	return &fakeClient{
 		Pods:         map[kube.PodIdentifier]*kube.Pod{},
+		Services:     map[string]map[string]string{},
 		Rules:        rules,
 		Filters:      filters,
 		Associations: associations,
@@ -79,6 +86,43 @@ func (f *fakeClient) GetPodAttributes(identifier kube.PodIdentifier) (map[string
 	return p.Attributes, ok
 }
 
+func (f *fakeClient) GetServiceAttributes(ip string) (map[string]string, bool) {
+	s, ok := f.Services[ip]
+	return s, ok
+}
+
+func (f *fakeClient) IsPodExcluded(identifier kube.PodIdentifier) bool {
+	p, ok := f.Pods[identifier]
+	return ok && p.Excluded
+}
+
+func (f *fakeClient) SetPodEventHandler(handler kube.PodEventHandler) {
+	f.PodEventHandler = handler
+}
+
+func (f *fakeClient) HasSynced() bool {
+	return f.Synced.Load()
+}
+
+func (f *fakeClient) SyncError() error {
+	return f.SyncErr
+}
+
+func (f *fakeClient) Snapshot() kube.CacheSnapshot {
+	snapshot := kube.CacheSnapshot{
+		Synced: f.Synced.Load(),
+		Pods:   map[kube.PodIdentifier]kube.PodSnapshot{},
+	}
+	for id, pod := range f.Pods {
+		snapshot.Pods[id] = kube.PodSnapshot{
+			Name:       pod.Name,
+			Namespace:  pod.Namespace,
+			Attributes: pod.Attributes,
+		}
+	}
+	return snapshot
+}
+
 // Start is a noop for FakeClient.
 func (f *fakeClient) Start() {
 	if f.Informer != nil {
@@ -15,6 +15,9 @@
 package k8sprocessor
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/k8sconfig"
 )
 
@@ -44,15 +47,52 @@ type Config struct {
 	// and logs with Pod metadata.
 	Association []PodAssociationConfig `mapstructure:"pod_association"`
 
-	// Exclude section allows to define names of pod that should be
+	// Exclude section allows to define pods that should be
 	// ignored while tagging.
 	Exclude ExcludeConfig `mapstructure:"exclude"`
 
 	// Limit is the page size for the list of pods to fetch from the API.
 	Limit int `mapstructure:"limit"`
+
+	// Destination section allows to resolve destination pods and services
+	// of network telemetry using span and log record attributes.
+	Destination DestinationConfig `mapstructure:"destination"`
+
+	// MetadataEvents section allows to emit a log record for every change
+	// of metadata extracted for a pod.
+	MetadataEvents MetadataEventsConfig `mapstructure:"metadata_events"`
+
+	// Debug section allows to expose the processor cache and its sync status over HTTP.
+	Debug DebugConfig `mapstructure:"debug"`
+
+	// WaitForMetadata makes the processor wait on start until the initial sync
+	// of pod, owner and namespace caches completes.
+	WaitForMetadata bool `mapstructure:"wait_for_metadata"`
+
+	// WaitForMetadataTimeout is the maximum time to wait for the initial sync.
+	// The processor fails to start if the caches aren't synced by then.
+	WaitForMetadataTimeout time.Duration `mapstructure:"wait_for_metadata_timeout"`
+
+	// StartupBuffer section allows to buffer data received before
+	// the initial sync of caches completes.
+	StartupBuffer StartupBufferConfig `mapstructure:"startup_buffer"`
 }
 
 func (cfg *Config) Validate() error {
+	if cfg.Filter.Namespace != "" && len(cfg.Filter.Namespaces) > 0 {
+		return fmt.Errorf("filter.namespace and filter.namespaces can't be used together")
+	}
+	if cfg.WaitForMetadata && cfg.WaitForMetadataTimeout <= 0 {
+		return fmt.Errorf("wait_for_metadata_timeout must be positive, got %s", cfg.WaitForMetadataTimeout)
+	}
+	if cfg.StartupBuffer.Enabled {
+		if cfg.StartupBuffer.MaxItems <= 0 {
+			return fmt.Errorf("startup_buffer.max_items must be positive, got %d", cfg.StartupBuffer.MaxItems)
+		}
+		if cfg.StartupBuffer.Timeout <= 0 {
+			return fmt.Errorf("startup_buffer.timeout must be positive, got %s", cfg.StartupBuffer.Timeout)
+		}
+	}
 	return cfg.APIConfig.Validate()
 }
 
@@ -66,7 +106,9 @@ type ExtractConfig struct {
 	//   namespace, podName, podUID, deployment, node and startTime
 	//
 	// Specifying anything other than these values will result in an error.
-	// By default all of the fields are extracted and added to spans and metrics.
+	// By default all of the fields are extracted and added to spans and metrics,
+	// except for Service details (serviceType, serviceClusterIP, servicePorts)
+	// and ingressHost which need to be requested explicitly.
 	Metadata []string `mapstructure:"metadata"`
 
 	// Tags allow to specify output name used for each of the kubernetes tags
@@ -98,6 +140,24 @@ type ExtractConfig struct {
 	// documentation for more details.
 	NamespaceLabels []FieldExtractConfig `mapstructure:"namespace_labels"`
 
+	// ServiceAnnotations allows extracting data from annotations of services
+	// the pod belongs to and record it as resource attributes.
+	// It is a list of FieldExtractConfig type. See FieldExtractConfig
+	// documentation for more details.
+	ServiceAnnotations []FieldExtractConfig `mapstructure:"service_annotations"`
+
+	// ServiceLabels allows extracting data from labels of services
+	// the pod belongs to and record it as resource attributes.
+	// It is a list of FieldExtractConfig type. See FieldExtractConfig
+	// documentation for more details.
+	ServiceLabels []FieldExtractConfig `mapstructure:"service_labels"`
+
+	// Templates allows building attributes from templates referencing
+	// other extracted pod, owner and namespace metadata.
+	// It is a list of TemplateExtractConfig type. See TemplateExtractConfig
+	// documentation for more details.
+	Templates []TemplateExtractConfig `mapstructure:"templates"`
+
 	// Delimiter is going to be used to join multiple values for metadata.
 	// For example if given pod is associated with more than one service,
 	// delimiter is going to separate them in string.
@@ -155,6 +215,26 @@ type FieldExtractConfig struct {
 	Regex   string `mapstructure:"regex"`
 }
 
+// TemplateExtractConfig allows specifying an attribute computed from a template.
+// The template contains names of already extracted attributes in `%{}` placeholders,
+// for example:
+//
+//	processors:
+//	  k8s_tagger:
+//	    extract:
+//	      templates:
+//	        - tag_name: team
+//	          template: "%{k8s.namespace.label.team}/%{k8s.deployment.name}"
+//
+// Templates are evaluated when pod attributes are looked up, in the order they
+// are defined, so a template can reference attributes created by preceding templates.
+// Placeholders referencing attributes which were not extracted are replaced
+// with an empty string. Templates with all placeholders empty are skipped.
+type TemplateExtractConfig struct {
+	TagName  string `mapstructure:"tag_name"`
+	Template string `mapstructure:"template"`
+}
+
 // FilterConfig section allows specifying filters to filter
 // pods by labels, fields, namespaces, nodes, etc.
 type FilterConfig struct {
@@ -186,6 +266,12 @@ type FilterConfig struct {
 	// Namespace filters all pods by the provided namespace. All other pods are ignored.
 	Namespace string `mapstructure:"namespace"`
 
+	// Namespaces filters all pods by the provided list of namespaces. All other pods are ignored.
+	// Unlike Namespace, pods and their owners are watched using separate informers for each
+	// of the namespaces, so that only permissions to list and watch objects in these namespaces
+	// are needed. It can't be used together with Namespace.
+	Namespaces []string `mapstructure:"namespaces"`
+
 	// Fields allows to filter pods by generic k8s fields.
 	// Only the following operations are supported:
 	//    - equals
@@ -234,18 +320,92 @@ type PodAssociationConfig struct {
 	Name string `mapstructure:"name"`
 }
 
+// DestinationConfig allows resolving destination IP addresses found in span and
+// log record attributes (e.g. `net.peer.ip`) into pod and service metadata.
+type DestinationConfig struct {
+	// Attributes is a list of span and log record attributes which may contain
+	// the destination IP address. They are checked in the specified order
+	// and the first one resolved to a pod or a service is used.
+	Attributes []string `mapstructure:"attributes"`
+
+	// Prefix is prepended to the names of attributes describing the destination,
+	// e.g. `k8s.pod.name` is added as `destination.k8s.pod.name` by default.
+	Prefix string `mapstructure:"prefix"`
+}
+
+// DefaultDestinationPrefix is default value for Prefix for DestinationConfig
+const DefaultDestinationPrefix string = "destination."
+
 // DefaultDelimiter is default value for Delimiter for ExtractConfig
 const DefaultDelimiter string = ", "
 
 // DefaultLimit is default value for Limit for Config
 const DefaultLimit int = 200
 
-// ExcludeConfig represent a list of Pods to exclude
+// DefaultWaitForMetadataTimeout is default value for WaitForMetadataTimeout for Config
+const DefaultWaitForMetadataTimeout = 10 * time.Second
+
+// DefaultStartupBufferMaxItems is default value for MaxItems for StartupBufferConfig
+const DefaultStartupBufferMaxItems int = 10000
+
+// DefaultStartupBufferTimeout is default value for Timeout for StartupBufferConfig
+const DefaultStartupBufferTimeout = time.Minute
+
+// ExcludeConfig represent rules for Pods to exclude.
+// A Pod is excluded if it matches any of the rules.
 type ExcludeConfig struct {
+	// Pods is a list of Pod name regexes to exclude.
 	Pods []ExcludePodConfig `mapstructure:"pods"`
+
+	// Namespaces is a list of Namespace name regexes whose Pods should be excluded.
+	Namespaces []ExcludeNamespaceConfig `mapstructure:"namespaces"`
+
+	// Labels is a list of label selectors. Pods matching any of them are excluded.
+	// The same operations as in FilterConfig.Labels are supported.
+	Labels []FieldFilterConfig `mapstructure:"labels"`
+
+	// Mode determines what happens with telemetry from excluded Pods.
+	// Allowed values are:
+	//   - skip_enrichment (default): telemetry is passed through without Pod metadata
+	//   - drop: telemetry is dropped
+	Mode string `mapstructure:"mode"`
 }
 
 // ExcludePodConfig represent a Pod name to ignore
 type ExcludePodConfig struct {
 	Name string `mapstructure:"name"`
 }
+
+// ExcludeNamespaceConfig represent a Namespace name whose Pods should be ignored
+type ExcludeNamespaceConfig struct {
+	Name string `mapstructure:"name"`
+}
+
+// MetadataEventsConfig allows emitting Pod metadata change events as logs.
+// Events are only emitted by the processor used in a logs pipeline.
+type MetadataEventsConfig struct {
+	// Enabled enables emitting a log record for every pod add, update and delete
+	// with the difference of extracted attributes.
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// DebugConfig allows exposing the processor cache for debugging.
+type DebugConfig struct {
+	// Endpoint is the address the debug HTTP server listens on, e.g. localhost:8089.
+	// The server is disabled when it's empty.
+	Endpoint string `mapstructure:"endpoint"`
+}
+
+// StartupBufferConfig allows buffering data received before the initial sync of caches completes.
+// Buffered data is tagged and sent once the caches are synced or the timeout passes.
+type StartupBufferConfig struct {
+	// Enabled enables the startup buffer.
+	Enabled bool `mapstructure:"enabled"`
+
+	// MaxItems is the maximum number of spans, metric data points or log records buffered.
+	// Data which doesn't fit into the buffer is processed immediately.
+	MaxItems int `mapstructure:"max_items"`
+
+	// Timeout is the maximum time data is buffered for.
+	Timeout time.Duration `mapstructure:"timeout"`
+}
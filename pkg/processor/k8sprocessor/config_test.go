@@ -17,6 +17,7 @@ package k8sprocessor
 import (
 	"path"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -47,6 +48,14 @@ func TestLoadConfig(t *testing.T) {
 			APIConfig: k8sconfig.APIConfig{AuthType: k8sconfig.AuthTypeServiceAccount},
 			Limit:     200,
 			Extract:   ExtractConfig{Delimiter: ", "},
+			Destination: DestinationConfig{
+				Prefix: "destination.",
+			},
+			WaitForMetadataTimeout: 10 * time.Second,
+			StartupBuffer: StartupBufferConfig{
+				MaxItems: 10000,
+				Timeout:  time.Minute,
+			},
 		},
 		p0,
 	)
@@ -81,6 +90,9 @@ func TestLoadConfig(t *testing.T) {
 				NamespaceLabels: []FieldExtractConfig{
 					{TagName: "namespace_labels_%s", Key: "*"},
 				},
+				Templates: []TemplateExtractConfig{
+					{TagName: "team", Template: "%{namespace_labels_team}/%{k8s.deployment.name}"},
+				},
 				Tags: map[string]string{
 					"containerId": "my.namespace.containerId",
 				},
@@ -126,8 +138,86 @@ func TestLoadConfig(t *testing.T) {
 					{Name: "jaeger-agent"},
 					{Name: "jaeger-collector"},
 				},
+				Namespaces: []ExcludeNamespaceConfig{
+					{Name: "^kube-system$"},
+				},
+				Labels: []FieldFilterConfig{
+					{Key: "app", Value: "excluded"},
+				},
+				Mode: "drop",
+			},
+			Destination: DestinationConfig{
+				Attributes: []string{"net.peer.ip", "destination.ip"},
+				Prefix:     "dst.",
+			},
+			MetadataEvents: MetadataEventsConfig{
+				Enabled: true,
+			},
+			WaitForMetadata:        true,
+			WaitForMetadataTimeout: 30 * time.Second,
+			StartupBuffer: StartupBufferConfig{
+				Enabled:  true,
+				MaxItems: 1000,
+				Timeout:  time.Minute,
 			},
 		},
 		p1,
 	)
 }
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(cfg *Config)
+		err    string
+	}{
+		{
+			name:   "default",
+			modify: func(cfg *Config) {},
+		},
+		{
+			name: "namespace and namespaces",
+			modify: func(cfg *Config) {
+				cfg.Filter.Namespace = "ns1"
+				cfg.Filter.Namespaces = []string{"ns2"}
+			},
+			err: "filter.namespace and filter.namespaces can't be used together",
+		},
+		{
+			name: "wait for metadata without timeout",
+			modify: func(cfg *Config) {
+				cfg.WaitForMetadata = true
+				cfg.WaitForMetadataTimeout = 0
+			},
+			err: "wait_for_metadata_timeout must be positive, got 0s",
+		},
+		{
+			name: "startup buffer without max items",
+			modify: func(cfg *Config) {
+				cfg.StartupBuffer.Enabled = true
+				cfg.StartupBuffer.MaxItems = 0
+			},
+			err: "startup_buffer.max_items must be positive, got 0",
+		},
+		{
+			name: "startup buffer without timeout",
+			modify: func(cfg *Config) {
+				cfg.StartupBuffer.Enabled = true
+				cfg.StartupBuffer.Timeout = 0
+			},
+			err: "startup_buffer.timeout must be positive, got 0s",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewFactory().CreateDefaultConfig().(*Config)
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright 2020 OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sprocessor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/k8sprocessor/kube"
+)
+
+const (
+	debugCachePath = "/cache"
+	debugReadyPath = "/ready"
+)
+
+// debugServers holds the debug servers by endpoint. The same processor configuration
+// is usually used in multiple pipelines, so processors using the same endpoint share a server.
+var (
+	debugServersMu sync.Mutex
+	debugServers   = map[string]*debugServer{}
+)
+
+// debugServer exposes caches of the processors over HTTP.
+type debugServer struct {
+	logger *zap.Logger
+	server *http.Server
+
+	mu         sync.RWMutex
+	processors map[*kubernetesprocessor]struct{}
+}
+
+type debugCacheEntry struct {
+	Processor string             `json:"processor"`
+	Cache     kube.CacheSnapshot `json:"cache"`
+}
+
+type debugReadyResponse struct {
+	Ready bool `json:"ready"`
+}
+
+// registerDebugEndpoint adds the processor to the debug server listening on the endpoint,
+// starting the server if needed.
+func registerDebugEndpoint(endpoint string, kp *kubernetesprocessor) error {
+	debugServersMu.Lock()
+	defer debugServersMu.Unlock()
+
+	ds, ok := debugServers[endpoint]
+	if !ok {
+		listener, err := net.Listen("tcp", endpoint)
+		if err != nil {
+			return fmt.Errorf("failed to start debug endpoint on %s: %w", endpoint, err)
+		}
+		ds = newDebugServer(kp.logger)
+		go func() {
+			if err := ds.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				ds.logger.Error("Debug endpoint failed", zap.String("endpoint", endpoint), zap.Error(err))
+			}
+		}()
+		debugServers[endpoint] = ds
+		kp.logger.Info("Started debug endpoint", zap.String("endpoint", listener.Addr().String()))
+	}
+
+	ds.mu.Lock()
+	ds.processors[kp] = struct{}{}
+	ds.mu.Unlock()
+	return nil
+}
+
+// unregisterDebugEndpoint removes the processor from the debug server listening on the endpoint,
+// stopping the server if it was the last one.
+func unregisterDebugEndpoint(endpoint string, kp *kubernetesprocessor) error {
+	debugServersMu.Lock()
+	defer debugServersMu.Unlock()
+
+	ds, ok := debugServers[endpoint]
+	if !ok {
+		return nil
+	}
+
+	ds.mu.Lock()
+	delete(ds.processors, kp)
+	remaining := len(ds.processors)
+	ds.mu.Unlock()
+
+	if remaining > 0 {
+		return nil
+	}
+	delete(debugServers, endpoint)
+	return ds.server.Close()
+}
+
+func newDebugServer(logger *zap.Logger) *debugServer {
+	ds := &debugServer{
+		logger:     logger,
+		processors: map[*kubernetesprocessor]struct{}{},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(debugCachePath, ds.handleCache)
+	mux.HandleFunc(debugReadyPath, ds.handleReady)
+	ds.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return ds
+}
+
+// handleCache returns snapshots of caches of all the processors
+func (ds *debugServer) handleCache(w http.ResponseWriter, _ *http.Request) {
+	ds.mu.RLock()
+	entries := make([]debugCacheEntry, 0, len(ds.processors))
+	for kp := range ds.processors {
+		entries = append(entries, debugCacheEntry{
+			Processor: kp.debugName,
+			Cache:     kp.kc.Snapshot(),
+		})
+	}
+	ds.mu.RUnlock()
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Processor < entries[j].Processor
+	})
+	ds.writeJSON(w, http.StatusOK, entries)
+}
+
+// handleReady returns 200 once the initial sync of all the processors has completed and 503 before
+func (ds *debugServer) handleReady(w http.ResponseWriter, _ *http.Request) {
+	ready := true
+	ds.mu.RLock()
+	for kp := range ds.processors {
+		ready = ready && kp.kc.HasSynced()
+	}
+	ds.mu.RUnlock()
+
+	status := http.StatusOK
+	if !ready {
+		status = http.StatusServiceUnavailable
+	}
+	ds.writeJSON(w, status, debugReadyResponse{Ready: ready})
+}
+
+func (ds *debugServer) writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		ds.logger.Debug("Failed to write debug endpoint response", zap.Error(err))
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2020 OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sprocessor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/k8sprocessor/kube"
+)
+
+func TestDebugServerCache(t *testing.T) {
+	ds := newDebugServer(zap.NewNop())
+	kc := &fakeClient{
+		Pods: map[kube.PodIdentifier]*kube.Pod{
+			"1.1.1.1": {Name: "my-pod", Namespace: "default", Attributes: map[string]string{"k8s.pod.name": "my-pod"}},
+		},
+	}
+	kc.Synced.Store(true)
+	kp := &kubernetesprocessor{debugName: "k8s_tagger/logs", kc: kc}
+	ds.processors[kp] = struct{}{}
+
+	rec := httptest.NewRecorder()
+	ds.server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, debugCachePath, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var entries []debugCacheEntry
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &entries))
+	require.Len(t, entries, 1)
+	assert.Equal(t, "k8s_tagger/logs", entries[0].Processor)
+	assert.True(t, entries[0].Cache.Synced)
+	assert.Equal(t, kube.PodSnapshot{
+		Name:       "my-pod",
+		Namespace:  "default",
+		Attributes: map[string]string{"k8s.pod.name": "my-pod"},
+	}, entries[0].Cache.Pods["1.1.1.1"])
+}
+
+func TestDebugServerReady(t *testing.T) {
+	ds := newDebugServer(zap.NewNop())
+	synced := &fakeClient{}
+	synced.Synced.Store(true)
+	notSynced := &fakeClient{}
+	ds.processors[&kubernetesprocessor{kc: synced}] = struct{}{}
+	ds.processors[&kubernetesprocessor{kc: notSynced}] = struct{}{}
+
+	get := func() (int, debugReadyResponse) {
+		rec := httptest.NewRecorder()
+		ds.server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, debugReadyPath, nil))
+		var resp debugReadyResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		return rec.Code, resp
+	}
+
+	code, resp := get()
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.False(t, resp.Ready)
+
+	notSynced.Synced.Store(true)
+	code, resp = get()
+	assert.Equal(t, http.StatusOK, code)
+	assert.True(t, resp.Ready)
+}
+
+func TestDebugEndpointSharedBetweenProcessors(t *testing.T) {
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	cfg.Debug.Endpoint = "localhost:0"
+	m := newMultiTest(t, cfg, nil)
+
+	ctx := context.Background()
+	host := componenttest.NewNopHost()
+	require.NoError(t, m.tp.Start(ctx, host))
+	require.NoError(t, m.lp.Start(ctx, host))
+
+	require.Contains(t, debugServers, "localhost:0")
+	ds := debugServers["localhost:0"]
+	assert.Len(t, ds.processors, 2)
+
+	require.NoError(t, m.tp.Shutdown(ctx))
+	require.Contains(t, debugServers, "localhost:0")
+	require.NoError(t, m.lp.Shutdown(ctx))
+	assert.NotContains(t, debugServers, "localhost:0")
+}
+
+func TestDebugEndpointInvalid(t *testing.T) {
+	kp := &kubernetesprocessor{logger: zap.NewNop(), kc: &fakeClient{}}
+	assert.Error(t, registerDebugEndpoint("invalid:endpoint:1", kp))
+	assert.NotContains(t, debugServers, "invalid:endpoint:1")
+}
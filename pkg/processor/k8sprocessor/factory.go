@@ -56,6 +56,14 @@ func createDefaultConfig() component.Config {
 		Extract: ExtractConfig{
 			Delimiter: DefaultDelimiter,
 		},
+		Destination: DestinationConfig{
+			Prefix: DefaultDestinationPrefix,
+		},
+		WaitForMetadataTimeout: DefaultWaitForMetadataTimeout,
+		StartupBuffer: StartupBufferConfig{
+			MaxItems: DefaultStartupBufferMaxItems,
+			Timeout:  DefaultStartupBufferTimeout,
+		},
 	}
 }
 
@@ -97,6 +105,8 @@ func createTracesProcessorWithOptions(
 	if err != nil {
 		return nil, err
 	}
+	kp.debugName = params.ID.String() + "/traces"
+	kp.nextTraces = next
 
 	return processorhelper.NewTraces(
 		ctx,
@@ -120,6 +130,8 @@ func createMetricsProcessorWithOptions(
 	if err != nil {
 		return nil, err
 	}
+	kp.debugName = params.ID.String() + "/metrics"
+	kp.nextMetrics = nextMetricsConsumer
 
 	return processorhelper.NewMetrics(
 		ctx,
@@ -143,6 +155,9 @@ func createLogsProcessorWithOptions(
 	if err != nil {
 		return nil, err
 	}
+	kp.debugName = params.ID.String() + "/logs"
+	kp.nextLogs = nextLogsConsumer
+	kp.enableMetadataEvents(nextLogsConsumer)
 
 	return processorhelper.NewLogs(
 		ctx,
@@ -194,6 +209,9 @@ func createProcessorOpts(cfg component.Config) []Option {
 	opts = append(opts, WithExtractNamespaceLabels(oCfg.Extract.NamespaceLabels...))
 	opts = append(opts, WithExtractAnnotations(oCfg.Extract.Annotations...))
 	opts = append(opts, WithExtractNamespaceAnnotations(oCfg.Extract.NamespaceAnnotations...))
+	opts = append(opts, WithExtractServiceLabels(oCfg.Extract.ServiceLabels...))
+	opts = append(opts, WithExtractServiceAnnotations(oCfg.Extract.ServiceAnnotations...))
+	opts = append(opts, WithExtractTemplates(oCfg.Extract.Templates...))
 	opts = append(opts, WithExtractTags(oCfg.Extract.Tags))
 
 	if oCfg.OwnerLookupEnabled {
@@ -203,6 +221,7 @@ func createProcessorOpts(cfg component.Config) []Option {
 	// filters
 	opts = append(opts, WithFilterNode(oCfg.Filter.Node, oCfg.Filter.NodeFromEnvVar))
 	opts = append(opts, WithFilterNamespace(oCfg.Filter.Namespace))
+	opts = append(opts, WithFilterNamespaces(oCfg.Filter.Namespaces...))
 	opts = append(opts, WithFilterLabels(oCfg.Filter.Labels...))
 	opts = append(opts, WithFilterFields(oCfg.Filter.Fields...))
 	opts = append(opts, WithAPIConfig(oCfg.APIConfig))
@@ -215,5 +234,19 @@ func createProcessorOpts(cfg component.Config) []Option {
 
 	opts = append(opts, WithExcludes(oCfg.Exclude))
 
+	opts = append(opts, WithDestination(oCfg.Destination))
+
+	opts = append(opts, WithMetadataEvents(oCfg.MetadataEvents))
+
+	opts = append(opts, WithDebugEndpoint(oCfg.Debug.Endpoint))
+
+	if oCfg.WaitForMetadata {
+		opts = append(opts, WithWaitForMetadata(oCfg.WaitForMetadataTimeout))
+	}
+
+	if oCfg.StartupBuffer.Enabled {
+		opts = append(opts, WithStartupBuffer(oCfg.StartupBuffer.MaxItems, oCfg.StartupBuffer.Timeout))
+	}
+
 	return opts
 }
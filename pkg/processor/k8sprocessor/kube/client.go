@@ -21,6 +21,7 @@ import (
 	"time"
 
 	"go.uber.org/zap"
+	"golang.org/x/exp/slices"
 	api_v1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
@@ -40,7 +41,7 @@ type WatchClient struct {
 	deleteMut   sync.Mutex
 	logger      *zap.Logger
 	kc          kubernetes.Interface
-	informer    cache.SharedInformer
+	informers   []*namespacedInformer
 	deleteQueue []deleteRequest
 	stopCh      chan struct{}
 	op          OwnerAPI
@@ -54,6 +55,8 @@ type WatchClient struct {
 	Filters      Filters
 	Associations []Association
 	Exclude      Excludes
+
+	podEventHandler PodEventHandler
 }
 
 // New initializes a new k8s Client.
@@ -105,7 +108,7 @@ func New(
 			newOwnerProviderFunc = newOwnerProvider
 		}
 
-		c.op, err = newOwnerProviderFunc(logger, c.kc, labelSelector, fieldSelector, rules, c.Filters.Namespace, deleteInterval, gracePeriod)
+		c.op, err = newOwnerProviderFunc(logger, c.kc, labelSelector, fieldSelector, rules, c.Filters.namespaces(), deleteInterval, gracePeriod)
 		if err != nil {
 			return nil, err
 		}
@@ -125,7 +128,10 @@ func New(
 		fieldSelector = addNodeSelector(fieldSelector, filters.Node)
 	}
 
-	c.informer = newInformer(logger, c.kc, c.Filters.Namespace, labelSelector, fieldSelector, c.limit)
+	for _, namespace := range c.Filters.namespaces() {
+		informer := newInformer(logger, c.kc, namespace, labelSelector, fieldSelector, c.limit)
+		c.informers = append(c.informers, newNamespacedInformer("Pod", namespace, informer))
+	}
 	return c, err
 }
 
@@ -135,28 +141,42 @@ func (c *WatchClient) Start() {
 		c.op.Start()
 	}
 
-	_, err := c.informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
-		AddFunc:    c.handlePodAdd,
-		UpdateFunc: c.handlePodUpdate,
-		DeleteFunc: c.handlePodDelete,
-	})
-	if err != nil {
-		c.logger.Error("error adding event handler to pod informer", zap.Error(err))
-	}
-	err = c.informer.SetTransform(
-		func(object interface{}) (interface{}, error) {
-			originalPod, success := object.(*api_v1.Pod)
-			if !success {
-				return object.(cache.DeletedFinalStateUnknown), nil
-			} else {
-				return removeUnnecessaryPodData(originalPod, c.Rules), nil
-			}
-		},
-	)
-	if err != nil {
-		c.logger.Warn("error setting Pod data transformer, continuing without it", zap.Error(err))
+	var wg sync.WaitGroup
+	for _, informer := range c.informers {
+		_, err := informer.informer.AddEventHandler(cache.ResourceEventHandlerDetailedFuncs{
+			AddFunc:    c.handleInformerPodAdd,
+			UpdateFunc: c.handlePodUpdate,
+			DeleteFunc: c.handlePodDelete,
+		})
+		if err != nil {
+			c.logger.Error("error adding event handler to pod informer", zap.Error(err))
+		}
+		err = informer.informer.SetTransform(
+			func(object interface{}) (interface{}, error) {
+				originalPod, success := object.(*api_v1.Pod)
+				if !success {
+					return object.(cache.DeletedFinalStateUnknown), nil
+				} else {
+					transformedPod := removeUnnecessaryPodData(originalPod, c.Rules)
+					// labels are needed to evaluate the exclusion rules
+					if len(c.Exclude.Labels) > 0 {
+						transformedPod.Labels = originalPod.Labels
+					}
+					return transformedPod, nil
+				}
+			},
+		)
+		if err != nil {
+			c.logger.Warn("error setting Pod data transformer, continuing without it", zap.Error(err))
+		}
+
+		wg.Add(1)
+		go func(informer *namespacedInformer) {
+			defer wg.Done()
+			informer.run(c.logger, c.stopCh)
+		}(informer)
 	}
-	c.informer.Run(c.stopCh)
+	wg.Wait()
 }
 
 // Stop signals the the k8s watcher/informer to stop watching for new events.
@@ -169,9 +189,15 @@ func (c *WatchClient) Stop() {
 }
 
 func (c *WatchClient) handlePodAdd(obj interface{}) {
+	c.handleInformerPodAdd(obj, false)
+}
+
+// handleInformerPodAdd handles a Pod added to the informer. Pods from the initial list existed
+// before the informer started, so they are cached without emitting Pod events.
+func (c *WatchClient) handleInformerPodAdd(obj interface{}, isInInitialList bool) {
 	observability.RecordPodAdded()
 	if pod, ok := obj.(*api_v1.Pod); ok {
-		c.addOrUpdatePod(pod)
+		c.addOrUpdatePod(pod, !isInInitialList)
 	} else {
 		c.logger.Error("object received was not of type api_v1.Pod", zap.Any("received", obj))
 	}
@@ -185,7 +211,7 @@ func (c *WatchClient) handlePodUpdate(old, new interface{}) {
 	observability.RecordPodUpdated()
 	if pod, ok := new.(*api_v1.Pod); ok {
 		// TODO: update or remove based on whether container is ready/unready?.
-		c.addOrUpdatePod(pod)
+		c.addOrUpdatePod(pod, true)
 	} else {
 		c.logger.Error("object received was not of type api_v1.Pod", zap.Any("received", new))
 	}
@@ -218,6 +244,12 @@ func (c *WatchClient) handlePodDelete(obj interface{}) {
 		return
 	}
 
+	if c.podEventHandler != nil {
+		if previous, ok := c.lookupPodForEvent(pod); ok {
+			c.podEventHandler(newPodEvent(PodEventDeleted, previous, nil))
+		}
+	}
+
 	c.forgetPod(pod)
 }
 
@@ -279,6 +311,21 @@ func (c *WatchClient) getPod(identifier PodIdentifier) (*Pod, bool) {
 	return pod, true
 }
 
+// IsPodExcluded takes an IP address or Pod UID and returns true if the Pod the identifier
+// is associated with matches the exclusion rules
+func (c *WatchClient) IsPodExcluded(identifier PodIdentifier) bool {
+	c.m.RLock()
+	defer c.m.RUnlock()
+	pod, ok := c.Pods[identifier]
+	return ok && pod.Excluded
+}
+
+// SetPodEventHandler sets a handler called for every change of attributes extracted for a Pod.
+// It has to be called before Start.
+func (c *WatchClient) SetPodEventHandler(handler PodEventHandler) {
+	c.podEventHandler = handler
+}
+
 // GetPodAttributes takes an IP address or Pod UID and returns the metadata attributes of the Pod the
 // identifier is associated with
 func (c *WatchClient) GetPodAttributes(identifier PodIdentifier) (map[string]string, bool) {
@@ -292,6 +339,7 @@ func (c *WatchClient) GetPodAttributes(identifier PodIdentifier) (map[string]str
 	// TODO: clean up the locking in this function and the ones it calls
 	c.m.RLock()
 	defer c.m.RUnlock()
+	c.extractTemplatesIntoTags(pod.Attributes, ownerAttributes)
 	attributes := make(map[string]string, len(pod.Attributes))
 	for key, value := range pod.Attributes {
 		attributes[key] = value
@@ -302,6 +350,22 @@ func (c *WatchClient) GetPodAttributes(identifier PodIdentifier) (map[string]str
 	return attributes, ok
 }
 
+// GetServiceAttributes takes a cluster IP address and returns the name and namespace of the
+// Service the IP address is associated with
+func (c *WatchClient) GetServiceAttributes(ip string) (map[string]string, bool) {
+	if c.op == nil || !c.Rules.ServiceIPLookup {
+		return nil, false
+	}
+	service := c.op.GetServiceByIP(ip)
+	if service == nil {
+		return nil, false
+	}
+	return map[string]string{
+		c.Rules.Tags.ServiceName: service.Name,
+		c.Rules.Tags.Namespace:   service.Namespace,
+	}, true
+}
+
 func (c *WatchClient) extractPodAttributes(pod *api_v1.Pod) map[string]string {
 	tags := map[string]string{}
 	if c.Rules.PodName {
@@ -342,7 +406,7 @@ func (c *WatchClient) extractPodAttributes(pod *api_v1.Pod) map[string]string {
 		}
 	}
 
-	// Owner metadata is updated on every query.
+	// Owner and namespace metadata is updated on every query.
 
 	if len(pod.Status.ContainerStatuses) > 0 {
 		cs := pod.Status.ContainerStatuses[0]
@@ -369,19 +433,6 @@ func (c *WatchClient) extractPodAttributes(pod *api_v1.Pod) map[string]string {
 		c.extractLabelsIntoTags(r, pod.Labels, tags)
 	}
 
-	if (len(c.Rules.NamespaceLabels) > 0 || len(c.Rules.NamespaceAnnotations) > 0) && c.Rules.OwnerLookupEnabled {
-		namespace := c.op.GetNamespace(pod)
-		if namespace != nil {
-			for _, r := range c.Rules.NamespaceLabels {
-				c.extractLabelsIntoTags(r, namespace.Labels, tags)
-			}
-
-			for _, r := range c.Rules.NamespaceAnnotations {
-				c.extractLabelsIntoTags(r, namespace.Annotations, tags)
-			}
-		}
-	}
-
 	for _, r := range c.Rules.Annotations {
 		c.extractLabelsIntoTags(r, pod.Annotations, tags)
 	}
@@ -431,14 +482,87 @@ func (c *WatchClient) getPodOwnerMetadataAttributes(pod *Pod) map[string]string
 			}
 		}
 
-		if c.Rules.ServiceName {
+		if len(c.Rules.NamespaceLabels) > 0 || len(c.Rules.NamespaceAnnotations) > 0 {
+			if namespace := c.op.GetNamespace(pod); namespace != nil {
+				for _, r := range c.Rules.NamespaceLabels {
+					c.extractLabelsIntoTags(r, namespace.Labels, attributes)
+				}
+				for _, r := range c.Rules.NamespaceAnnotations {
+					c.extractLabelsIntoTags(r, namespace.Annotations, attributes)
+				}
+			}
+		}
+
+		if c.Rules.watchEndpointSlices() {
 			services := c.op.GetServices(pod.Name)
-			attributes[c.Rules.Tags.ServiceName] = strings.Join(services, c.delimiter)
+			if c.Rules.ServiceName {
+				attributes[c.Rules.Tags.ServiceName] = strings.Join(services, c.delimiter)
+			}
+			c.extractServiceAttributes(pod, services, attributes)
 		}
 	}
 	return attributes
 }
 
+// extractServiceAttributes adds metadata of the given Services and Ingresses routing to them
+// to the attributes. Values coming from multiple Services are joined using the delimiter.
+func (c *WatchClient) extractServiceAttributes(pod *Pod, serviceNames []string, attributes map[string]string) {
+	values := map[string][]string{}
+	addValue := func(tag string, value string) {
+		if value != "" && slices.Index(values[tag], value) == -1 {
+			values[tag] = append(values[tag], value)
+		}
+	}
+
+	for _, serviceName := range serviceNames {
+		if c.Rules.IngressHost {
+			for _, host := range c.op.GetIngressHosts(pod.Namespace, serviceName) {
+				addValue(c.Rules.Tags.IngressHost, host)
+			}
+		}
+
+		if !c.Rules.extractServiceMetadata() {
+			continue
+		}
+
+		service := c.op.GetService(pod.Namespace, serviceName)
+		if service == nil {
+			c.logger.Debug("missing Service data for Pod, cache may be out of sync",
+				zap.String("pod", pod.Name),
+				zap.String("service", serviceName),
+			)
+			continue
+		}
+
+		if c.Rules.ServiceType {
+			addValue(c.Rules.Tags.ServiceType, string(service.Spec.Type))
+		}
+		if c.Rules.ServiceClusterIP {
+			addValue(c.Rules.Tags.ServiceClusterIP, service.Spec.ClusterIP)
+		}
+		if c.Rules.ServicePorts {
+			for _, port := range service.Spec.Ports {
+				addValue(c.Rules.Tags.ServicePorts, fmt.Sprintf("%d/%s", port.Port, port.Protocol))
+			}
+		}
+
+		tags := map[string]string{}
+		for _, r := range c.Rules.ServiceLabels {
+			c.extractLabelsIntoTags(r, service.Labels, tags)
+		}
+		for _, r := range c.Rules.ServiceAnnotations {
+			c.extractLabelsIntoTags(r, service.Annotations, tags)
+		}
+		for tag, value := range tags {
+			addValue(tag, value)
+		}
+	}
+
+	for tag, tagValues := range values {
+		attributes[tag] = strings.Join(tagValues, c.delimiter)
+	}
+}
+
 // This function removes all data from the Pod except what is required by extraction rules
 func removeUnnecessaryPodData(pod *api_v1.Pod, rules ExtractionRules) *api_v1.Pod {
 
@@ -531,7 +655,7 @@ func (c *WatchClient) extractField(v string, r FieldExtractionRule) string {
 	return ""
 }
 
-func (c *WatchClient) addOrUpdatePod(pod *api_v1.Pod) {
+func (c *WatchClient) addOrUpdatePod(pod *api_v1.Pod, emitEvent bool) {
 	newPod := &Pod{
 		Name:            pod.Name,
 		Namespace:       pod.Namespace,
@@ -543,10 +667,23 @@ func (c *WatchClient) addOrUpdatePod(pod *api_v1.Pod) {
 
 	if c.shouldIgnorePod(pod) {
 		newPod.Ignore = true
+		// Pods in host network mode share the IP address with the node,
+		// so they are never marked as excluded
+		newPod.Excluded = !pod.Spec.HostNetwork && c.isExcludedPod(pod)
 	} else {
 		newPod.Attributes = c.extractPodAttributes(pod)
 	}
 
+	if c.podEventHandler != nil && !newPod.Ignore {
+		// owner metadata and templates are resolved on demand, so a snapshot is kept to report its changes
+		newPod.ownerAttributes = c.getPodOwnerMetadataAttributes(newPod)
+		c.extractTemplatesIntoTags(newPod.Attributes, newPod.ownerAttributes)
+
+		if emitEvent {
+			c.emitPodEvent(pod, newPod)
+		}
+	}
+
 	c.m.Lock()
 	defer c.m.Unlock()
 
@@ -575,6 +712,32 @@ func (c *WatchClient) addOrUpdatePod(pod *api_v1.Pod) {
 	}
 }
 
+// extractTemplatesIntoTags renders the template extraction rules using pod attributes and
+// owner metadata and stores the results in the latter. Owner metadata is resolved on demand,
+// so templates are rendered every time as well, rather than getting stuck with partial results
+// rendered before the owner caches were synced. Templates are rendered in order, so a template
+// can reference the result of a preceding one. Templates with all placeholders empty are skipped.
+func (c *WatchClient) extractTemplatesIntoTags(podAttributes map[string]string, ownerAttributes map[string]string) {
+	if len(c.Rules.Templates) == 0 {
+		return
+	}
+
+	attributes := make(map[string]string, len(podAttributes)+len(ownerAttributes))
+	for key, value := range podAttributes {
+		attributes[key] = value
+	}
+	for key, value := range ownerAttributes {
+		attributes[key] = value
+	}
+
+	for _, r := range c.Rules.Templates {
+		if value, ok := r.Render(attributes); ok {
+			attributes[r.Name] = value
+			ownerAttributes[r.Name] = value
+		}
+	}
+}
+
 type Namer interface {
 	GetName() string
 	GetNamespace() string
@@ -584,21 +747,105 @@ func generatePodIDFromName(p Namer) PodIdentifier {
 	return PodIdentifier(fmt.Sprintf("%s.%s", p.GetName(), p.GetNamespace()))
 }
 
-func (c *WatchClient) forgetPod(pod *api_v1.Pod) {
-	p, ok := c.getPod(PodIdentifier(pod.Status.PodIP))
-	if ok && p.Name == pod.Name {
-		c.appendDeleteQueue(PodIdentifier(pod.Status.PodIP), pod.Name)
+// emitPodEvent sends an event with changes of the Pod attributes, comparing them with the cached Pod.
+func (c *WatchClient) emitPodEvent(pod *api_v1.Pod, newPod *Pod) {
+	previous, ok := c.lookupPodForEvent(pod)
+	if !ok {
+		c.podEventHandler(newPodEvent(PodEventAdded, nil, newPod))
+	} else if event := newPodEvent(PodEventUpdated, previous, newPod); event.hasChanges() {
+		c.podEventHandler(event)
 	}
+}
 
-	p, ok = c.getPod(PodIdentifier(pod.UID))
-	if ok && p.Name == pod.Name {
-		c.appendDeleteQueue(PodIdentifier(pod.UID), pod.Name)
+// lookupPodForEvent returns the cached, not ignored, Pod with the same UID, or name if UID is not set.
+func (c *WatchClient) lookupPodForEvent(pod *api_v1.Pod) (*Pod, bool) {
+	identifier := PodIdentifier(pod.UID)
+	if identifier == "" {
+		identifier = generatePodIDFromName(pod)
 	}
 
-	id := generatePodIDFromName(pod)
-	p, ok = c.getPod(id)
-	if ok && p.Name == pod.Name {
-		c.appendDeleteQueue(id, pod.Name)
+	c.m.RLock()
+	defer c.m.RUnlock()
+	p, ok := c.Pods[identifier]
+	if !ok || p.Ignore || p.Name != pod.Name {
+		return nil, false
+	}
+	return p, true
+}
+
+// newPodEvent creates an event with the difference between attributes, including owner metadata,
+// of previous and current Pod. Either of them can be nil.
+func newPodEvent(eventType PodEventType, previous *Pod, current *Pod) PodEvent {
+	event := PodEvent{
+		Type:      eventType,
+		Timestamp: time.Now(),
+		Added:     map[string]string{},
+		Removed:   map[string]string{},
+		Changed:   map[string]AttributeChange{},
+	}
+
+	var previousAttributes, currentAttributes map[string]string
+	if previous != nil {
+		event.Name, event.Namespace, event.PodUID = previous.Name, previous.Namespace, previous.PodUID
+		previousAttributes = previous.eventAttributes()
+	}
+	if current != nil {
+		event.Name, event.Namespace, event.PodUID = current.Name, current.Namespace, current.PodUID
+		currentAttributes = current.eventAttributes()
+	}
+
+	for key, value := range currentAttributes {
+		old, ok := previousAttributes[key]
+		switch {
+		case !ok:
+			event.Added[key] = value
+		case old != value:
+			event.Changed[key] = AttributeChange{Old: old, New: value}
+		}
+	}
+	for key, value := range previousAttributes {
+		if _, ok := currentAttributes[key]; !ok {
+			event.Removed[key] = value
+		}
+	}
+	return event
+}
+
+// eventAttributes returns attributes of the Pod along with the snapshot of its owner metadata.
+func (p *Pod) eventAttributes() map[string]string {
+	if len(p.ownerAttributes) == 0 {
+		return p.Attributes
+	}
+	attributes := make(map[string]string, len(p.Attributes)+len(p.ownerAttributes))
+	for key, value := range p.Attributes {
+		attributes[key] = value
+	}
+	for key, value := range p.ownerAttributes {
+		attributes[key] = value
+	}
+	return attributes
+}
+
+func (e PodEvent) hasChanges() bool {
+	return len(e.Added) > 0 || len(e.Removed) > 0 || len(e.Changed) > 0
+}
+
+// forgetPod queues all identifiers of the Pod for deletion. Unlike getPod, it doesn't skip
+// ignored Pods, so that excluded Pods are removed from the cache as well.
+func (c *WatchClient) forgetPod(pod *api_v1.Pod) {
+	identifiers := []PodIdentifier{
+		PodIdentifier(pod.Status.PodIP),
+		PodIdentifier(pod.UID),
+		generatePodIDFromName(pod),
+	}
+
+	for _, identifier := range identifiers {
+		c.m.RLock()
+		p, ok := c.Pods[identifier]
+		c.m.RUnlock()
+		if ok && p.Name == pod.Name {
+			c.appendDeleteQueue(identifier, pod.Name)
+		}
 	}
 }
 
@@ -630,12 +877,29 @@ func (c *WatchClient) shouldIgnorePod(pod *api_v1.Pod) bool {
 	}
 
 	// Check if user requested the pod to be ignored through configuration
+	return c.isExcludedPod(pod)
+}
+
+// isExcludedPod returns true if the pod matches any of the exclusion rules
+func (c *WatchClient) isExcludedPod(pod *api_v1.Pod) bool {
 	for _, excludedPod := range c.Exclude.Pods {
 		if excludedPod.Name.MatchString(pod.Name) {
 			return true
 		}
 	}
 
+	for _, excludedNamespace := range c.Exclude.Namespaces {
+		if excludedNamespace.Name.MatchString(pod.Namespace) {
+			return true
+		}
+	}
+
+	for _, selector := range c.Exclude.Labels {
+		if selector.Matches(labels.Set(pod.Labels)) {
+			return true
+		}
+	}
+
 	return false
 }
 
@@ -15,6 +15,7 @@
 package kube
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"regexp"
@@ -28,6 +29,7 @@ import (
 	"go.uber.org/zap/zapcore"
 	"go.uber.org/zap/zaptest/observer"
 	api_v1 "k8s.io/api/core/v1"
+	discovery_v1 "k8s.io/api/discovery/v1"
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
@@ -168,7 +170,7 @@ func TestBadFilters(t *testing.T) {
 
 func TestClientStartStop(t *testing.T) {
 	c, _ := newTestClient(t)
-	ctr := c.informer.GetController()
+	ctr := c.informers[0].informer.GetController()
 	require.IsType(t, &FakeController{}, ctr)
 	fctr := ctr.(*FakeController)
 	require.NotNil(t, fctr)
@@ -239,6 +241,218 @@ func TestPodHostNetwork(t *testing.T) {
 	assert.True(t, got.Ignore)
 }
 
+func TestPodExcludes(t *testing.T) {
+	c, _ := newTestClient(t)
+	requirement, err := labels.NewRequirement("app", selection.Equals, []string{"excluded"})
+	require.NoError(t, err)
+	c.Exclude.Namespaces = []ExcludeNamespaces{{Name: regexp.MustCompile(`^kube-system$`)}}
+	c.Exclude.Labels = []labels.Selector{labels.NewSelector().Add(*requirement)}
+
+	testCases := []struct {
+		name     string
+		pod      *api_v1.Pod
+		ip       string
+		excluded bool
+	}{
+		{
+			name: "not excluded",
+			pod: &api_v1.Pod{
+				ObjectMeta: meta_v1.ObjectMeta{Name: "podA", Namespace: "default"},
+				Status:     api_v1.PodStatus{PodIP: "1.1.1.1"},
+			},
+			ip: "1.1.1.1",
+		},
+		{
+			name: "excluded by name",
+			pod: &api_v1.Pod{
+				ObjectMeta: meta_v1.ObjectMeta{Name: "jaeger-agent-b2zdv", Namespace: "default"},
+				Status:     api_v1.PodStatus{PodIP: "1.1.1.2"},
+			},
+			ip:       "1.1.1.2",
+			excluded: true,
+		},
+		{
+			name: "excluded by namespace",
+			pod: &api_v1.Pod{
+				ObjectMeta: meta_v1.ObjectMeta{Name: "podC", Namespace: "kube-system"},
+				Status:     api_v1.PodStatus{PodIP: "1.1.1.3"},
+			},
+			ip:       "1.1.1.3",
+			excluded: true,
+		},
+		{
+			name: "excluded by label",
+			pod: &api_v1.Pod{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Name:      "podD",
+					Namespace: "default",
+					Labels:    map[string]string{"app": "excluded"},
+				},
+				Status: api_v1.PodStatus{PodIP: "1.1.1.4"},
+			},
+			ip:       "1.1.1.4",
+			excluded: true,
+		},
+		{
+			name: "ignored by annotation is not excluded",
+			pod: &api_v1.Pod{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Name:        "podE",
+					Namespace:   "default",
+					Annotations: map[string]string{"opentelemetry.io/k8s-processor/ignore": "true"},
+				},
+				Status: api_v1.PodStatus{PodIP: "1.1.1.5"},
+			},
+			ip: "1.1.1.5",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c.handlePodAdd(tc.pod)
+			got, ok := c.Pods[PodIdentifier(tc.ip)]
+			require.True(t, ok)
+			assert.Equal(t, tc.excluded, got.Excluded)
+			assert.Equal(t, tc.excluded, c.IsPodExcluded(PodIdentifier(tc.ip)))
+		})
+	}
+	assert.False(t, c.IsPodExcluded("unknown"))
+}
+
+func TestPodEvents(t *testing.T) {
+	c, _ := newTestClientWithRulesAndFilters(t, ExtractionRules{
+		PodName: true,
+		Labels:  []FieldExtractionRule{{Name: "app", Key: "app"}},
+		Tags:    NewExtractionFieldTags(),
+	}, Filters{})
+	var events []PodEvent
+	c.SetPodEventHandler(func(e PodEvent) {
+		events = append(events, e)
+	})
+
+	pod := &api_v1.Pod{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "podA",
+			Namespace: "namespace",
+			UID:       "aaa",
+			Labels:    map[string]string{"app": "v1"},
+		},
+		Status: api_v1.PodStatus{PodIP: "1.1.1.1"},
+	}
+	c.handlePodAdd(pod)
+	require.Len(t, events, 1)
+	assert.Equal(t, PodEventAdded, events[0].Type)
+	assert.Equal(t, "podA", events[0].Name)
+	assert.Equal(t, "namespace", events[0].Namespace)
+	assert.Equal(t, "aaa", events[0].PodUID)
+	assert.Equal(t, map[string]string{"k8s.pod.name": "podA", "app": "v1"}, events[0].Added)
+
+	// no event without changes
+	c.handlePodUpdate(pod, pod.DeepCopy())
+	require.Len(t, events, 1)
+
+	updated := pod.DeepCopy()
+	updated.Labels["app"] = "v2"
+	c.handlePodUpdate(pod, updated)
+	require.Len(t, events, 2)
+	assert.Equal(t, PodEventUpdated, events[1].Type)
+	assert.Empty(t, events[1].Added)
+	assert.Empty(t, events[1].Removed)
+	assert.Equal(t, map[string]AttributeChange{"app": {Old: "v1", New: "v2"}}, events[1].Changed)
+
+	removed := updated.DeepCopy()
+	removed.Labels = nil
+	c.handlePodUpdate(updated, removed)
+	require.Len(t, events, 3)
+	assert.Equal(t, map[string]string{"app": "v2"}, events[2].Removed)
+
+	c.handlePodDelete(removed)
+	require.Len(t, events, 4)
+	assert.Equal(t, PodEventDeleted, events[3].Type)
+	assert.Equal(t, map[string]string{"k8s.pod.name": "podA"}, events[3].Removed)
+
+	// ignored pods don't emit events
+	c.handlePodAdd(&api_v1.Pod{
+		ObjectMeta: meta_v1.ObjectMeta{Name: "jaeger-agent", Namespace: "namespace", UID: "bbb"},
+		Status:     api_v1.PodStatus{PodIP: "1.1.1.2"},
+	})
+	assert.Len(t, events, 4)
+}
+
+func TestPodEventsInitialList(t *testing.T) {
+	c, _ := newTestClientWithRulesAndFilters(t, ExtractionRules{
+		Labels: []FieldExtractionRule{{Name: "app", Key: "app"}},
+		Tags:   NewExtractionFieldTags(),
+	}, Filters{})
+	var events []PodEvent
+	c.SetPodEventHandler(func(e PodEvent) {
+		events = append(events, e)
+	})
+
+	pod := &api_v1.Pod{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "podA",
+			Namespace: "namespace",
+			UID:       "aaa",
+			Labels:    map[string]string{"app": "v1"},
+		},
+		Status: api_v1.PodStatus{PodIP: "1.1.1.1"},
+	}
+	// pods listed when the informer starts already existed
+	c.handleInformerPodAdd(pod, true)
+	assert.Empty(t, events)
+	assert.Contains(t, c.Pods, PodIdentifier("aaa"))
+
+	updated := pod.DeepCopy()
+	updated.Labels["app"] = "v2"
+	c.handlePodUpdate(pod, updated)
+	require.Len(t, events, 1)
+	assert.Equal(t, PodEventUpdated, events[0].Type)
+	assert.Equal(t, map[string]AttributeChange{"app": {Old: "v1", New: "v2"}}, events[0].Changed)
+}
+
+func TestPodEventsOwners(t *testing.T) {
+	c, _ := newTestClientWithRulesAndFilters(t, ExtractionRules{
+		DeploymentName:     true,
+		StatefulSetName:    true,
+		OwnerLookupEnabled: true,
+		Tags:               NewExtractionFieldTags(),
+	}, Filters{})
+	var events []PodEvent
+	c.SetPodEventHandler(func(e PodEvent) {
+		events = append(events, e)
+	})
+
+	pod := &api_v1.Pod{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "podA",
+			Namespace: "kube-system",
+			UID:       "aaa",
+			OwnerReferences: []meta_v1.OwnerReference{
+				{Kind: "StatefulSet", Name: "snug-sts", UID: "f15f0585-a0bc-43a3-96e4-dd2eace75391"},
+			},
+		},
+		Status: api_v1.PodStatus{PodIP: "1.1.1.1"},
+	}
+	c.handlePodAdd(pod)
+	require.Len(t, events, 1)
+	assert.Equal(t, map[string]string{"k8s.statefulset.name": "snug-sts"}, events[0].Added)
+
+	adopted := pod.DeepCopy()
+	adopted.OwnerReferences = []meta_v1.OwnerReference{
+		{Kind: "ReplicaSet", Name: "dearest-deploy-77c99ccb96", UID: "1a1658f9-7818-11e9-90f1-02324f7e0d1e"},
+	}
+	c.handlePodUpdate(pod, adopted)
+	require.Len(t, events, 2)
+	assert.Equal(t, PodEventUpdated, events[1].Type)
+	assert.Equal(t, map[string]string{"k8s.deployment.name": "dearest-deploy"}, events[1].Added)
+	assert.Equal(t, map[string]string{"k8s.statefulset.name": "snug-sts"}, events[1].Removed)
+
+	c.handlePodDelete(adopted)
+	require.Len(t, events, 3)
+	assert.Equal(t, map[string]string{"k8s.deployment.name": "dearest-deploy"}, events[2].Removed)
+}
+
 func TestPodAddOutOfSync(t *testing.T) {
 	c, _ := newTestClient(t)
 	assert.Equal(t, len(c.Pods), 0)
@@ -389,6 +603,30 @@ func TestDeleteLoop(t *testing.T) {
 	<-c.stopCh
 }
 
+func TestDeleteExcludedPod(t *testing.T) {
+	c, _ := newTestClient(t)
+
+	pod := &api_v1.Pod{}
+	pod.Name = "jaeger-agent-b2zdv"
+	pod.Namespace = "default"
+	pod.UID = "aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee"
+	pod.Status.PodIP = "1.1.1.1"
+	c.handlePodAdd(pod)
+	require.True(t, c.IsPodExcluded(PodIdentifier(pod.Status.PodIP)))
+	assert.Equal(t, len(c.Pods), 3)
+
+	c.handlePodDelete(pod)
+	assert.Equal(t, len(c.deleteQueue), 3)
+
+	go c.deleteLoop(time.Millisecond, 0)
+	defer close(c.stopCh)
+	assert.Eventually(t, func() bool {
+		c.m.RLock()
+		defer c.m.RUnlock()
+		return len(c.Pods) == 0
+	}, time.Second, time.Millisecond)
+}
+
 func TestGetIgnoredPod(t *testing.T) {
 	c, _ := newTestClient(t)
 	pod := &api_v1.Pod{}
@@ -862,6 +1100,72 @@ func TestExtractionRules(t *testing.T) {
 				"namespace_annotations_annotation": "namespace_annotation_value",
 			},
 		},
+		{
+			name: "service metadata",
+			rules: ExtractionRules{
+				ServiceType:        true,
+				ServiceClusterIP:   true,
+				ServicePorts:       true,
+				IngressHost:        true,
+				OwnerLookupEnabled: true,
+				Tags:               NewExtractionFieldTags(),
+				ServiceLabels: []FieldExtractionRule{
+					{
+						Name: "k8s.service.label.%s",
+						Key:  "*",
+					},
+				},
+				ServiceAnnotations: []FieldExtractionRule{
+					{
+						Name: "service_annotation",
+						Key:  "annotation",
+					},
+				},
+			},
+			attributes: map[string]string{
+				"k8s.service.type":        "ClusterIP",
+				"k8s.service.cluster_ip":  "10.0.0.1",
+				"k8s.service.ports":       "80/TCP",
+				"k8s.ingress.host":        "foo.example.com_bar.example.com",
+				"k8s.service.label.label": "foo_label_value_bar_label_value",
+				"service_annotation":      "foo_annotation_value_bar_annotation_value",
+			},
+		},
+		{
+			name: "templates",
+			podOwner: &meta_v1.OwnerReference{
+				Kind: "ReplicaSet",
+				Name: "dearest-deploy-77c99ccb96",
+				UID:  "1a1658f9-7818-11e9-90f1-02324f7e0d1e",
+			},
+			rules: ExtractionRules{
+				DeploymentName:     true,
+				OwnerLookupEnabled: true,
+				Tags:               NewExtractionFieldTags(),
+				NamespaceLabels: []FieldExtractionRule{
+					{
+						Name: "k8s.namespace.label.%s",
+						Key:  "*",
+					},
+				},
+				Templates: []TemplateExtractionRule{
+					{
+						Name:     "team",
+						Template: "%{k8s.namespace.label.label}/%{k8s.deployment.name}",
+					},
+					{
+						Name:     "owner",
+						Template: "%{team}:%{missing}",
+					},
+				},
+			},
+			attributes: map[string]string{
+				"k8s.deployment.name":       "dearest-deploy",
+				"k8s.namespace.label.label": "namespace_label_value",
+				"team":                      "namespace_label_value/dearest-deploy",
+				"owner":                     "namespace_label_value/dearest-deploy:",
+			},
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -890,6 +1194,28 @@ func TestExtractionRules(t *testing.T) {
 	}
 }
 
+func TestGetServiceAttributes(t *testing.T) {
+	c, _ := newTestClientWithRulesAndFilters(t, ExtractionRules{
+		OwnerLookupEnabled: true,
+		Tags:               NewExtractionFieldTags(),
+	}, Filters{})
+
+	// Services are not resolved unless the lookup is enabled
+	_, ok := c.GetServiceAttributes("10.0.0.1")
+	assert.False(t, ok)
+
+	c.Rules.ServiceIPLookup = true
+	attributes, ok := c.GetServiceAttributes("10.0.0.1")
+	require.True(t, ok)
+	assert.Equal(t, map[string]string{
+		"k8s.service.name":   "foo",
+		"k8s.namespace.name": "kube-system",
+	}, attributes)
+
+	_, ok = c.GetServiceAttributes("10.0.0.2")
+	assert.False(t, ok)
+}
+
 func TestFilters(t *testing.T) {
 	testCases := []struct {
 		name    string
@@ -947,7 +1273,7 @@ func TestFilters(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			c, _ := newTestClientWithRulesAndFilters(t, ExtractionRules{}, tc.filters)
-			inf := c.informer.(*FakeInformer)
+			inf := c.informers[0].informer.(*FakeInformer)
 			assert.Equal(t, tc.filters.Namespace, inf.namespace)
 			assert.Equal(t, tc.labels, inf.labelSelector.String())
 			assert.Equal(t, tc.fields, inf.fieldSelector.String())
@@ -955,6 +1281,30 @@ func TestFilters(t *testing.T) {
 	}
 }
 
+func TestNamespacesFilter(t *testing.T) {
+	filters := Filters{
+		Namespaces: []string{"ns1", "ns2"},
+	}
+	c, _ := newTestClientWithRulesAndFilters(t, ExtractionRules{OwnerLookupEnabled: true}, filters)
+
+	// a Pod informer is created for each of the namespaces
+	require.Len(t, c.informers, 2)
+	assert.Equal(t, "ns1", c.informers[0].informer.(*FakeInformer).namespace)
+	assert.Equal(t, "ns2", c.informers[1].informer.(*FakeInformer).namespace)
+	assert.Equal(t, map[string]bool{"Pod/ns1": true, "Pod/ns2": true, "Namespace": true}, c.Snapshot().Informers)
+
+	ownerProvider := c.op.(*fakeOwnerCache)
+	assert.Equal(t, []string{"ns1", "ns2"}, ownerProvider.namespaces)
+}
+
+func TestFiltersNamespaces(t *testing.T) {
+	assert.Equal(t, []string{""}, Filters{}.namespaces())
+	assert.Equal(t, []string{"ns1"}, Filters{Namespace: "ns1"}.namespaces())
+	assert.Equal(t, []string{"ns1", "ns2"}, Filters{Namespaces: []string{"ns1", "ns2"}}.namespaces())
+	assert.Equal(t, []string{"ns1", "ns2"}, Filters{Namespace: "ns2", Namespaces: []string{"ns1", "ns2"}}.namespaces())
+	assert.Equal(t, []string{"ns1", "ns2"}, Filters{Namespace: "ns2", Namespaces: []string{"ns1"}}.namespaces())
+}
+
 func TestNodeFilterDoesntApplyToOwners(t *testing.T) {
 	filters := Filters{
 		Node: "ec2-test",
@@ -962,7 +1312,7 @@ func TestNodeFilterDoesntApplyToOwners(t *testing.T) {
 	c, _ := newTestClientWithRulesAndFilters(t, ExtractionRules{OwnerLookupEnabled: true}, filters)
 
 	// verify that the Pod informer has the Node selector set
-	inf := c.informer.(*FakeInformer)
+	inf := c.informers[0].informer.(*FakeInformer)
 	assert.Equal(t, "", inf.labelSelector.String())
 	assert.Equal(t, "spec.nodeName=ec2-test", inf.fieldSelector.String())
 
@@ -1301,6 +1651,98 @@ func Test_PodsGetAddedAndDeletedFromCache(t *testing.T) {
 	})
 }
 
+func Test_ExtractServiceMetadataWithoutServiceName(t *testing.T) {
+	const (
+		namespace = "kube-system"
+	)
+
+	logger, err := zap.NewDevelopment()
+	require.NoError(t, err)
+	client, err := New(
+		logger,
+		k8sconfig.APIConfig{},
+		ExtractionRules{
+			ServiceType:        true,
+			OwnerLookupEnabled: true,
+			Tags:               NewExtractionFieldTags(),
+		},
+		Filters{},
+		[]Association{},
+		Excludes{},
+		newFakeAPIClientset,
+		NewFakeInformer,
+		newOwnerProvider,
+		"_",
+		10,
+		10*time.Millisecond,
+		10*time.Millisecond,
+	)
+	require.NoError(t, err)
+
+	c := client.(*WatchClient)
+	fakeClient := c.kc.(*fake.Clientset)
+	endpointSliceWatchEstablished := waitForWatchToBeEstablished(fakeClient, "endpointslices")
+	serviceWatchEstablished := waitForWatchToBeEstablished(fakeClient, "services")
+
+	c.op.Start()
+	t.Cleanup(func() {
+		c.op.Stop()
+	})
+
+	<-endpointSliceWatchEstablished
+	<-serviceWatchEstablished
+
+	pod := &api_v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-pod",
+			Namespace: namespace,
+			UID:       "f15f0585-a0bc-43a3-96e4-dd2eace75392",
+		},
+	}
+	_, err = fakeClient.CoreV1().Services(namespace).Create(context.Background(),
+		&api_v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "my-service",
+				Namespace: namespace,
+			},
+			Spec: api_v1.ServiceSpec{
+				Type: api_v1.ServiceTypeNodePort,
+			},
+		}, metav1.CreateOptions{})
+	require.NoError(t, err)
+	_, err = fakeClient.DiscoveryV1().EndpointSlices(namespace).Create(context.Background(),
+		&discovery_v1.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "my-service-abc",
+				Namespace: namespace,
+				Labels: map[string]string{
+					"kubernetes.io/service-name": "my-service",
+				},
+			},
+			Endpoints: []discovery_v1.Endpoint{
+				{
+					TargetRef: &api_v1.ObjectReference{
+						Name:      pod.Name,
+						Namespace: namespace,
+						Kind:      "Pod",
+						UID:       pod.UID,
+					},
+				},
+			},
+		}, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	c.handlePodAdd(pod)
+
+	// Services of the pod are resolved even though their names are not extracted
+	assert.Eventually(t, func() bool {
+		attributes, ok := c.GetPodAttributes(PodIdentifier(pod.UID))
+		return ok && attributes["k8s.service.type"] == "NodePort"
+	}, 5*time.Second, 5*time.Millisecond)
+	attributes, _ := c.GetPodAttributes(PodIdentifier(pod.UID))
+	assert.NotContains(t, attributes, "k8s.service.name")
+}
+
 func newTestClientWithRulesAndFilters(t *testing.T, e ExtractionRules, f Filters) (*WatchClient, *observer.ObservedLogs) {
 	observedLogger, logs := observer.New(zapcore.WarnLevel)
 	logger := zap.New(observedLogger)
@@ -1393,3 +1835,71 @@ func TestServiceInfoArrivesLate(t *testing.T) {
 	// Desired behavior: we get all three service names in response:
 	assert.Equal(t, "firstService, secondService, thirdService", serviceName)
 }
+
+func TestTemplatesOwnerArrivesLate(t *testing.T) {
+	// Concept: we insert a pod before its owner and namespace are cached,
+	// then cache them and see that templates are rendered with their metadata
+	logger, err := zap.NewDevelopment()
+	require.NoError(t, err)
+
+	cache := OwnerCache{
+		objectOwners: map[string]*ObjectOwner{},
+		podServices:  map[string][]string{},
+		namespaces:   map[string]*api_v1.Namespace{},
+		logger:       logger,
+		stopCh:       make(chan struct{}),
+	}
+
+	var client = &WatchClient{
+		logger: logger,
+		op:     &cache,
+		Rules: ExtractionRules{
+			OwnerLookupEnabled: true,
+			DeploymentName:     true,
+			Tags:               NewExtractionFieldTags(),
+			NamespaceLabels: []FieldExtractionRule{
+				{
+					Name: "k8s.namespace.label.%s",
+					Key:  "*",
+				},
+			},
+			Templates: []TemplateExtractionRule{
+				{
+					Name:     "team",
+					Template: "%{k8s.namespace.label.team}/%{k8s.deployment.name}",
+				},
+			},
+		},
+		Pods: map[PodIdentifier]*Pod{},
+	}
+
+	pod := &api_v1.Pod{}
+	pod.Name = "pod"
+	pod.Namespace = "namespace"
+	pod.UID = "aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee"
+	pod.OwnerReferences = []meta_v1.OwnerReference{
+		{Kind: "Deployment", Name: "deployment", UID: "deployment-uid"},
+	}
+
+	client.handlePodAdd(pod)
+
+	// Template with all placeholders empty is skipped
+	attributes, ok := client.GetPodAttributes(PodIdentifier(pod.UID))
+	require.True(t, ok)
+	assert.NotContains(t, attributes, "team")
+
+	cache.cacheObject("Deployment", &meta_v1.ObjectMeta{Name: "deployment", Namespace: "namespace", UID: "deployment-uid"})
+
+	attributes, ok = client.GetPodAttributes(PodIdentifier(pod.UID))
+	require.True(t, ok)
+	assert.Equal(t, "/deployment", attributes["team"])
+
+	cache.upsertNamespace(&api_v1.Namespace{
+		ObjectMeta: meta_v1.ObjectMeta{Name: "namespace", Labels: map[string]string{"team": "observability"}},
+	})
+
+	attributes, ok = client.GetPodAttributes(PodIdentifier(pod.UID))
+	require.True(t, ok)
+	assert.Equal(t, "observability/deployment", attributes["team"])
+	assert.Equal(t, "observability", attributes["k8s.namespace.label.team"])
+}
@@ -33,7 +33,7 @@ type fakeOwnerCache struct {
 	labelSelector   labels.Selector
 	fieldSelector   fields.Selector
 	extractionRules ExtractionRules
-	namespace       string
+	namespaces      []string
 }
 
 // NewOwnerProvider creates new instance of the owners api
@@ -42,14 +42,14 @@ func newFakeOwnerProvider(logger *zap.Logger,
 	labelSelector labels.Selector,
 	fieldSelector fields.Selector,
 	extractionRules ExtractionRules,
-	namespace string,
+	namespaces []string,
 	_ time.Duration, _ time.Duration,
 ) (OwnerAPI, error) {
 	ownerCache := fakeOwnerCache{
 		labelSelector:   labelSelector,
 		fieldSelector:   fieldSelector,
 		extractionRules: extractionRules,
-		namespace:       namespace,
+		namespaces:      namespaces,
 	}
 	ownerCache.objectOwners = map[string]*ObjectOwner{}
 	ownerCache.logger = logger
@@ -108,13 +108,50 @@ func (op *fakeOwnerCache) Start() {}
 // Stop
 func (op *fakeOwnerCache) Stop() {}
 
+// InformersSynced
+func (op *fakeOwnerCache) InformersSynced() map[string]bool {
+	return map[string]bool{"Namespace": true}
+}
+
 // GetServices fetches list of services for a given pod
 func (op *fakeOwnerCache) GetServices(podName string) []string {
 	return []string{"foo", "bar"}
 }
 
+// GetService returns a Service
+func (op *fakeOwnerCache) GetService(namespace string, name string) *api_v1.Service {
+	return &api_v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   namespace,
+			Labels:      map[string]string{"label": name + "_label_value"},
+			Annotations: map[string]string{"annotation": name + "_annotation_value"},
+		},
+		Spec: api_v1.ServiceSpec{
+			Type:      api_v1.ServiceTypeClusterIP,
+			ClusterIP: "10.0.0.1",
+			Ports: []api_v1.ServicePort{
+				{Port: 80, Protocol: api_v1.ProtocolTCP},
+			},
+		},
+	}
+}
+
+// GetServiceByIP returns a Service for the well known test IP
+func (op *fakeOwnerCache) GetServiceByIP(ip string) *api_v1.Service {
+	if ip != "10.0.0.1" {
+		return nil
+	}
+	return op.GetService("kube-system", "foo")
+}
+
+// GetIngressHosts returns hosts of Ingresses routing to the Service
+func (op *fakeOwnerCache) GetIngressHosts(namespace string, serviceName string) []string {
+	return []string{serviceName + ".example.com"}
+}
+
 // GetNamespace returns a namespace
-func (op *fakeOwnerCache) GetNamespace(pod *api_v1.Pod) *api_v1.Namespace {
+func (op *fakeOwnerCache) GetNamespace(pod *Pod) *api_v1.Namespace {
 	namespace := api_v1.Namespace{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        pod.Namespace,
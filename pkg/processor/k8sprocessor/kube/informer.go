@@ -16,10 +16,13 @@ package kube
 
 import (
 	"context"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"go.uber.org/zap"
 	api_v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
@@ -95,3 +98,78 @@ func informerWatchFuncWithSelectors(client kubernetes.Interface, namespace strin
 		return client.CoreV1().Pods(namespace).Watch(context.Background(), opts)
 	}
 }
+
+// namespacedInformer wraps an informer of a single kind of objects in a single namespace,
+// so that it can be stopped on its own when permissions to list and watch the objects are missing.
+type namespacedInformer struct {
+	kind      string
+	namespace string
+	informer  cache.SharedInformer
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	// forbidden is set when the informer was stopped because of missing permissions
+	forbidden atomic.Bool
+}
+
+func newNamespacedInformer(kind string, namespace string, informer cache.SharedInformer) *namespacedInformer {
+	return &namespacedInformer{
+		kind:      kind,
+		namespace: namespace,
+		informer:  informer,
+		stopCh:    make(chan struct{}),
+	}
+}
+
+// name identifies the informer by the kind of objects and the namespace it watches
+func (i *namespacedInformer) name() string {
+	if i.namespace == "" {
+		return i.kind
+	}
+	return i.kind + "/" + i.namespace
+}
+
+// run runs the informer until it or the parent is stopped.
+func (i *namespacedInformer) run(logger *zap.Logger, parentStopCh <-chan struct{}) {
+	err := i.informer.SetWatchErrorHandlerWithContext(func(ctx context.Context, r *cache.Reflector, err error) {
+		if !apierrors.IsForbidden(err) {
+			cache.DefaultWatchErrorHandler(ctx, r, err)
+			return
+		}
+		// report missing permissions only once, instead of retrying indefinitely
+		if i.forbidden.CompareAndSwap(false, true) {
+			logger.Error(
+				"Missing permissions to list and watch objects, metadata from them won't be available. "+
+					"Grant the permissions and restart the collector to enable it",
+				zap.String("kind", i.kind),
+				zap.String("namespace", i.namespace),
+				zap.Error(err),
+			)
+			i.stop()
+		}
+	})
+	if err != nil {
+		logger.Warn("error setting watch error handler, continuing without it", zap.String("kind", i.kind), zap.Error(err))
+	}
+
+	go func() {
+		select {
+		case <-parentStopCh:
+			i.stop()
+		case <-i.stopCh:
+		}
+	}()
+	i.informer.Run(i.stopCh)
+}
+
+func (i *namespacedInformer) stop() {
+	i.stopOnce.Do(func() {
+		close(i.stopCh)
+	})
+}
+
+// hasSynced returns true once the initial sync has completed, or the informer
+// was stopped because of missing permissions, so that it doesn't block waiting for the sync.
+func (i *namespacedInformer) hasSynced() bool {
+	return i.forbidden.Load() || i.informer.HasSynced()
+}
@@ -15,15 +15,26 @@
 package kube
 
 import (
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 	api_v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/cache"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/k8sconfig"
@@ -110,3 +121,72 @@ func Test_fakeInformer(t *testing.T) {
 	store := i.GetStore()
 	assert.NoError(t, store.Add(api_v1.Pod{}))
 }
+
+func forbidListAndWatch(client *fake.Clientset, resource string) {
+	forbidden := apierrors.NewForbidden(schema.GroupResource{Resource: resource}, "", errors.New("access denied"))
+	client.PrependReactor("list", resource, func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, forbidden
+	})
+	client.PrependWatchReactor(resource, func(k8stesting.Action) (bool, watch.Interface, error) {
+		return true, nil, forbidden
+	})
+}
+
+func Test_namespacedInformerForbidden(t *testing.T) {
+	observedLogger, logs := observer.New(zapcore.ErrorLevel)
+	logger := zap.New(observedLogger)
+	client, err := newFakeAPIClientset(k8sconfig.APIConfig{})
+	require.NoError(t, err)
+	forbidListAndWatch(client.(*fake.Clientset), "pods")
+
+	informer := newNamespacedInformer(
+		"Pod",
+		"testns",
+		newSharedInformer(logger, client, "testns", labels.Everything(), fields.Everything(), 10),
+	)
+	assert.Equal(t, "Pod/testns", informer.name())
+	assert.False(t, informer.hasSynced())
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	done := make(chan struct{})
+	go func() {
+		informer.run(logger, stopCh)
+		close(done)
+	}()
+
+	// the informer stops on its own, instead of retrying
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("informer didn't stop")
+	}
+	assert.True(t, informer.forbidden.Load())
+	assert.True(t, informer.hasSynced())
+	assert.Equal(t, 1, logs.FilterMessageSnippet("Missing permissions").Len())
+}
+
+func Test_namespacedInformerStop(t *testing.T) {
+	client, err := newFakeAPIClientset(k8sconfig.APIConfig{})
+	require.NoError(t, err)
+	informer := newNamespacedInformer(
+		"Pod",
+		"",
+		newSharedInformer(zap.NewNop(), client, "", labels.Everything(), fields.Everything(), 10),
+	)
+	assert.Equal(t, "Pod", informer.name())
+
+	stopCh := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		informer.run(zap.NewNop(), stopCh)
+		close(done)
+	}()
+	close(stopCh)
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("informer didn't stop")
+	}
+	assert.False(t, informer.forbidden.Load())
+}
@@ -19,9 +19,11 @@ import (
 	"time"
 
 	conventions "go.opentelemetry.io/otel/semconv/v1.18.0"
+	"golang.org/x/exp/slices"
 
 	"go.uber.org/zap"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/client-go/kubernetes"
 
@@ -43,6 +45,10 @@ const (
 	defaultTagPodUID          = "k8s.pod.uid"
 	defaultTagReplicaSetName  = "k8s.replicaset.name"
 	defaultTagServiceName     = "k8s.service.name"
+	defaultTagServiceType     = "k8s.service.type"
+	defaultTagServiceIP       = "k8s.service.cluster_ip"
+	defaultTagServicePorts    = "k8s.service.ports"
+	defaultTagIngressHost     = "k8s.ingress.host"
 	defaultTagStatefulSetName = "k8s.statefulset.name"
 	defaultTagStartTime       = "k8s.pod.startTime"
 )
@@ -58,6 +64,13 @@ const (
 // Client defines the main interface that allows querying pods by metadata.
 type Client interface {
 	GetPodAttributes(PodIdentifier) (map[string]string, bool)
+	GetServiceAttributes(ip string) (map[string]string, bool)
+	IsPodExcluded(PodIdentifier) bool
+	SetPodEventHandler(PodEventHandler)
+	HasSynced() bool
+	// SyncError returns an error if the cache can't ever be synced
+	SyncError() error
+	Snapshot() CacheSnapshot
 	Start()
 	Stop()
 }
@@ -85,16 +98,56 @@ type APIClientsetProvider func(config k8sconfig.APIConfig) (kubernetes.Interface
 
 // Pod represents a kubernetes pod.
 type Pod struct {
-	Attributes      map[string]string
-	StartTime       *metav1.Time
-	Name            string
-	Namespace       string
-	Address         string
-	PodUID          string
-	Ignore          bool
+	Attributes map[string]string
+	StartTime  *metav1.Time
+	Name       string
+	Namespace  string
+	Address    string
+	PodUID     string
+	Ignore     bool
+	// Excluded is set for pods matching the exclusion rules
+	Excluded        bool
 	OwnerReferences *[]metav1.OwnerReference
+
+	// ownerAttributes is a snapshot of metadata resolved on demand, i.e. owner and namespace metadata
+	// and attributes rendered from templates, kept only to report its changes in Pod events
+	ownerAttributes map[string]string
+}
+
+// PodEventType is the kind of change of Pod metadata.
+type PodEventType string
+
+const (
+	PodEventAdded   PodEventType = "added"
+	PodEventUpdated PodEventType = "updated"
+	PodEventDeleted PodEventType = "deleted"
+)
+
+// PodEvent describes a change of attributes extracted for a Pod.
+type PodEvent struct {
+	Type      PodEventType
+	Timestamp time.Time
+	Name      string
+	Namespace string
+	PodUID    string
+	// Added contains attributes which were not present before
+	Added map[string]string
+	// Removed contains attributes which are no longer present, with their last values
+	Removed map[string]string
+	// Changed contains attributes which have a different value than before
+	Changed map[string]AttributeChange
 }
 
+// AttributeChange holds the previous and current value of an attribute.
+type AttributeChange struct {
+	Old string
+	New string
+}
+
+// PodEventHandler is called for every change of attributes extracted for a Pod.
+// It is called synchronously from the informer, so it should not block.
+type PodEventHandler func(PodEvent)
+
 func (p Pod) GetName() string {
 	return p.Name
 }
@@ -116,13 +169,28 @@ type deleteRequest struct {
 // for performance reasons. We can support adding additional custom filters
 // in future if there is a real need.
 type Filters struct {
-	Node            string
-	Namespace       string
+	Node      string
+	Namespace string
+	// Namespaces is a list of namespaces to watch, each with its own informers.
+	// It is used in clusters where pods and their owners can't be watched cluster-wide.
+	Namespaces      []string
 	Fields          []FieldFilter
 	Labels          []FieldFilter
 	NamespaceLabels []FieldFilter
 }
 
+// namespaces returns the namespaces to watch, where an empty string stands for all namespaces.
+func (f Filters) namespaces() []string {
+	if len(f.Namespaces) == 0 {
+		return []string{f.Namespace}
+	}
+	namespaces := append([]string{}, f.Namespaces...)
+	if f.Namespace != "" && !slices.Contains(namespaces, f.Namespace) {
+		namespaces = append(namespaces, f.Namespace)
+	}
+	return namespaces
+}
+
 // FieldFilter represents exactly one filter by field rule.
 type FieldFilter struct {
 	// Key matches the field name.
@@ -156,13 +224,42 @@ type ExtractionRules struct {
 	Namespace       bool
 	NodeName        bool
 
+	ServiceType      bool
+	ServiceClusterIP bool
+	ServicePorts     bool
+	IngressHost      bool
+
 	OwnerLookupEnabled bool
 
+	// ServiceIPLookup enables indexing Services by their cluster IPs,
+	// so that they can be resolved with GetServiceAttributes.
+	ServiceIPLookup bool
+
 	Tags                 ExtractionFieldTags
 	Annotations          []FieldExtractionRule
 	NamespaceAnnotations []FieldExtractionRule
 	Labels               []FieldExtractionRule
 	NamespaceLabels      []FieldExtractionRule
+	ServiceAnnotations   []FieldExtractionRule
+	ServiceLabels        []FieldExtractionRule
+	Templates            []TemplateExtractionRule
+}
+
+// extractServiceMetadata returns true if any Service metadata other than the name is extracted
+func (r ExtractionRules) extractServiceMetadata() bool {
+	return r.ServiceType || r.ServiceClusterIP || r.ServicePorts ||
+		len(r.ServiceLabels) > 0 || len(r.ServiceAnnotations) > 0
+}
+
+// watchEndpointSlices returns true if Services of pods need to be known,
+// as they are only resolved from EndpointSlices
+func (r ExtractionRules) watchEndpointSlices() bool {
+	return r.ServiceName || r.IngressHost || r.extractServiceMetadata()
+}
+
+// watchServices returns true if Service objects need to be cached
+func (r ExtractionRules) watchServices() bool {
+	return r.extractServiceMetadata() || r.ServiceIPLookup
 }
 
 // ExtractionFieldTags is used to describe selected exported key names for the extracted data
@@ -183,6 +280,11 @@ type ExtractionFieldTags struct {
 	ServiceName     string
 	StartTime       string
 	StatefulSetName string
+
+	ServiceType      string
+	ServiceClusterIP string
+	ServicePorts     string
+	IngressHost      string
 }
 
 // NewExtractionFieldTags builds a new instance of tags with default values
@@ -204,6 +306,10 @@ func NewExtractionFieldTags() ExtractionFieldTags {
 	tags.ServiceName = defaultTagServiceName
 	tags.StartTime = defaultTagStartTime
 	tags.StatefulSetName = defaultTagStatefulSetName
+	tags.ServiceType = defaultTagServiceType
+	tags.ServiceClusterIP = defaultTagServiceIP
+	tags.ServicePorts = defaultTagServicePorts
+	tags.IngressHost = defaultTagIngressHost
 	return tags
 }
 
@@ -219,6 +325,36 @@ type FieldExtractionRule struct {
 	Key string
 }
 
+// TemplateExtractionRule is used to build an attribute from a template
+// referencing other extracted attributes.
+type TemplateExtractionRule struct {
+	// Name is used as the attribute name.
+	Name string
+	// Template contains attribute names in `%{}` placeholders, which are
+	// replaced with the values of already extracted attributes.
+	Template string
+}
+
+// templatePlaceholderRegex matches `%{attribute}` placeholders in templates.
+var templatePlaceholderRegex = regexp.MustCompile(`%\{([^{}]+)\}`)
+
+// Render fills the template placeholders with values from the given attributes.
+// Placeholders referencing missing attributes are replaced with an empty string.
+// It returns false if the template has placeholders and all of them are replaced with an empty string,
+// as such a result only consists of separators.
+func (r TemplateExtractionRule) Render(attributes map[string]string) (string, bool) {
+	placeholders, resolved := 0, 0
+	value := templatePlaceholderRegex.ReplaceAllStringFunc(r.Template, func(placeholder string) string {
+		value := attributes[placeholder[2:len(placeholder)-1]]
+		placeholders++
+		if value != "" {
+			resolved++
+		}
+		return value
+	})
+	return value, placeholders == 0 || resolved > 0
+}
+
 // Associations represent a list of rules for Pod metadata associations with resources
 type Associations struct {
 	Associations []Association
@@ -232,10 +368,17 @@ type Association struct {
 
 // Excludes represent a list of Pods to ignore
 type Excludes struct {
-	Pods []ExcludePods
+	Pods       []ExcludePods
+	Namespaces []ExcludeNamespaces
+	Labels     []labels.Selector
 }
 
 // ExcludePods represent a Pod name to ignore
 type ExcludePods struct {
 	Name *regexp.Regexp
 }
+
+// ExcludeNamespaces represent a Namespace name whose Pods should be ignored
+type ExcludeNamespaces struct {
+	Name *regexp.Regexp
+}
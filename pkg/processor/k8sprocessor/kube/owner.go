@@ -23,6 +23,7 @@ import (
 	"golang.org/x/exp/slices"
 	api_v1 "k8s.io/api/core/v1"
 	discovery_v1 "k8s.io/api/discovery/v1"
+	networking_v1 "k8s.io/api/networking/v1"
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
@@ -43,7 +44,7 @@ type OwnerProvider func(
 	labelSelector labels.Selector,
 	fieldSelector fields.Selector,
 	extractionRules ExtractionRules,
-	namespace string,
+	namespaces []string,
 	deleteInterval time.Duration,
 	gracePeriod time.Duration,
 ) (OwnerAPI, error)
@@ -60,8 +61,12 @@ type ObjectOwner struct {
 // OwnerAPI describes functions that could allow retrieving owner info
 type OwnerAPI interface {
 	GetOwners(pod *Pod) []*ObjectOwner
-	GetNamespace(pod *api_v1.Pod) *api_v1.Namespace
+	GetNamespace(pod *Pod) *api_v1.Namespace
 	GetServices(podName string) []string
+	GetService(namespace string, name string) *api_v1.Service
+	GetServiceByIP(ip string) *api_v1.Service
+	GetIngressHosts(namespace string, serviceName string) []string
+	InformersSynced() map[string]bool
 	Start()
 	Stop()
 }
@@ -77,13 +82,23 @@ type OwnerCache struct {
 	namespaces map[string]*api_v1.Namespace
 	nsMutex    sync.RWMutex
 
+	services      map[string]*api_v1.Service
+	serviceIPs    map[string]string
+	servicesMutex sync.RWMutex
+
+	// serviceIngressHosts maps a Service key to the hosts of each Ingress routing to it
+	serviceIngressHosts map[string]map[string][]string
+	// ingressServices maps an Ingress key to the keys of Services it routes to
+	ingressServices map[string][]string
+	ingressMutex    sync.RWMutex
+
 	deleteQueue []ownerCacheEviction
 	deleteMu    sync.Mutex
 
 	logger *zap.Logger
 
 	stopCh    chan struct{}
-	informers []cache.SharedIndexInformer
+	informers []*namespacedInformer
 }
 
 func newOwnerCache(logger *zap.Logger) OwnerCache {
@@ -91,8 +106,14 @@ func newOwnerCache(logger *zap.Logger) OwnerCache {
 		objectOwners: map[string]*ObjectOwner{},
 		podServices:  map[string][]string{},
 		namespaces:   map[string]*api_v1.Namespace{},
-		logger:       logger,
-		stopCh:       make(chan struct{}),
+
+		services:            map[string]*api_v1.Service{},
+		serviceIPs:          map[string]string{},
+		serviceIngressHosts: map[string]map[string][]string{},
+		ingressServices:     map[string][]string{},
+
+		logger: logger,
+		stopCh: make(chan struct{}),
 	}
 }
 
@@ -100,7 +121,7 @@ func newOwnerCache(logger *zap.Logger) OwnerCache {
 func (op *OwnerCache) Start() {
 	op.logger.Info("Staring K8S resource informers", zap.Int("#infomers", len(op.informers)))
 	for _, informer := range op.informers {
-		go informer.Run(op.stopCh)
+		go informer.run(op.logger, op.stopCh)
 	}
 }
 
@@ -109,13 +130,30 @@ func (op *OwnerCache) Stop() {
 	close(op.stopCh)
 }
 
+// InformersSynced returns the initial sync status of informers by the kind of objects
+// and the namespace they watch
+func (op *OwnerCache) InformersSynced() map[string]bool {
+	synced := make(map[string]bool, len(op.informers))
+	for _, informer := range op.informers {
+		synced[informer.name()] = informer.hasSynced()
+	}
+	return synced
+}
+
+// deleteQueueLen returns the number of objects waiting to be evicted from the cache
+func (op *OwnerCache) deleteQueueLen() int {
+	op.deleteMu.Lock()
+	defer op.deleteMu.Unlock()
+	return len(op.deleteQueue)
+}
+
 func newOwnerProvider(
 	logger *zap.Logger,
 	client kubernetes.Interface,
 	labelSelector labels.Selector,
 	fieldSelector fields.Selector,
 	extractionRules ExtractionRules,
-	namespace string,
+	namespaces []string,
 	deleteInterval time.Duration,
 	gracePeriod time.Duration,
 ) (OwnerAPI, error) {
@@ -123,24 +161,64 @@ func newOwnerProvider(
 	ownerCache := newOwnerCache(logger)
 	go ownerCache.deleteLoop(deleteInterval, gracePeriod)
 
-	factory := informers.NewSharedInformerFactoryWithOptions(client, watchSyncPeriod,
-		informers.WithNamespace(namespace),
-		informers.WithTweakListOptions(func(opts *meta_v1.ListOptions) {
-			opts.LabelSelector = labelSelector.String()
-			opts.FieldSelector = fieldSelector.String()
-			// Unset resource version to get the latest data
-			opts.ResourceVersion = ""
-		}))
+	var cronJobVersion string
+	if extractionRules.CronJobName {
+		cronJobVersion = getCronJobVersion(logger, client)
+	}
+
+	if len(namespaces) == 0 {
+		namespaces = []string{""}
+	}
+
+	// informers are created per namespace, so that only permissions
+	// to watch objects in these namespaces are needed
+	for _, namespace := range namespaces {
+		factory := informers.NewSharedInformerFactoryWithOptions(client, watchSyncPeriod,
+			informers.WithNamespace(namespace),
+			informers.WithTweakListOptions(func(opts *meta_v1.ListOptions) {
+				opts.LabelSelector = labelSelector.String()
+				opts.FieldSelector = fieldSelector.String()
+				// Unset resource version to get the latest data
+				opts.ResourceVersion = ""
+			}))
+
+		if namespace == "" {
+			ownerCache.addNamespaceInformer(namespace, factory)
+		} else {
+			// Namespaces are cluster scoped, so only the watched one is selected by its name,
+			// which can be allowed using resourceNames in RBAC rules
+			namespaceFactory := informers.NewSharedInformerFactoryWithOptions(client, watchSyncPeriod,
+				informers.WithTweakListOptions(func(opts *meta_v1.ListOptions) {
+					opts.FieldSelector = fields.OneTermEqualSelector("metadata.name", namespace).String()
+					// Unset resource version to get the latest data
+					opts.ResourceVersion = ""
+				}))
+			ownerCache.addNamespaceInformer(namespace, namespaceFactory)
+		}
+
+		ownerCache.addOwnerInformers(namespace, factory, extractionRules, cronJobVersion)
+	}
+
+	return &ownerCache, nil
+}
 
-	ownerCache.addNamespaceInformer(factory)
+// addOwnerInformers adds informers for owner objects in the namespace, depending on the extraction rules
+func (op *OwnerCache) addOwnerInformers(
+	namespace string,
+	factory informers.SharedInformerFactory,
+	extractionRules ExtractionRules,
+	cronJobVersion string,
+) {
+	logger := op.logger.With(zap.String("namespace", namespace))
 
 	// Only enable DaemonSet informer when DaemonSet extraction rule is enabled
 	if extractionRules.DaemonSetName {
 		logger.Debug("adding informer for DaemonSet", zap.String("api_version", "apps/v1"))
-		ownerCache.addOwnerInformer("DaemonSet",
+		op.addOwnerInformer("DaemonSet",
+			namespace,
 			factory.Apps().V1().DaemonSets().Informer(),
-			ownerCache.cacheObject,
-			ownerCache.deleteObject,
+			op.cacheObject,
+			op.deleteObject,
 			nil,
 			nil,
 		)
@@ -149,10 +227,11 @@ func newOwnerProvider(
 	// Only enable ReplicaSet informer when ReplicaSet or DeploymentName extraction rule is enabled
 	if extractionRules.ReplicaSetName || extractionRules.DeploymentName {
 		logger.Debug("adding informer for ReplicaSet", zap.String("api_version", "apps/v1"))
-		ownerCache.addOwnerInformer("ReplicaSet",
+		op.addOwnerInformer("ReplicaSet",
+			namespace,
 			factory.Apps().V1().ReplicaSets().Informer(),
-			ownerCache.cacheObject,
-			ownerCache.deleteObject,
+			op.cacheObject,
+			op.deleteObject,
 			nil,
 			nil,
 		)
@@ -161,10 +240,11 @@ func newOwnerProvider(
 	// Only enable Deployment informer when Deployment extraction rule is enabled
 	if extractionRules.DeploymentName {
 		logger.Debug("adding informer for Deployment", zap.String("api_version", "apps/v1"))
-		ownerCache.addOwnerInformer("Deployment",
+		op.addOwnerInformer("Deployment",
+			namespace,
 			factory.Apps().V1().Deployments().Informer(),
-			ownerCache.cacheObject,
-			ownerCache.deleteObject,
+			op.cacheObject,
+			op.deleteObject,
 			nil,
 			nil,
 		)
@@ -173,23 +253,25 @@ func newOwnerProvider(
 	// Only enable StatefulSet informer when StatefulSet extraction rule is enabled
 	if extractionRules.StatefulSetName {
 		logger.Debug("adding informer for StatefulSet", zap.String("api_version", "apps/v1"))
-		ownerCache.addOwnerInformer("StatefulSet",
+		op.addOwnerInformer("StatefulSet",
+			namespace,
 			factory.Apps().V1().StatefulSets().Informer(),
-			ownerCache.cacheObject,
-			ownerCache.deleteObject,
+			op.cacheObject,
+			op.deleteObject,
 			nil,
 			nil,
 		)
 	}
 
-	// Only enable Endpoint informer when Endpoint extraction rule is enabled
-	if extractionRules.ServiceName {
+	// Only enable EndpointSlice informer when Services of pods are extracted, by name or by their metadata
+	if extractionRules.watchEndpointSlices() {
 		logger.Debug("adding informer for EndpointSlice", zap.String("api_version", "discovery.k8s.io/v1"))
-		ownerCache.addOwnerInformer("EndpointSlice",
+		op.addOwnerInformer("EndpointSlice",
+			namespace,
 			factory.Discovery().V1().EndpointSlices().Informer(),
-			ownerCache.cacheEndpointSlice,
-			ownerCache.deleteEndpointSlice,
-			ownerCache.updateEndpointSlice,
+			op.cacheEndpointSlice,
+			op.deleteEndpointSlice,
+			op.updateEndpointSlice,
 			func(object interface{}) (interface{}, error) {
 				originalES, success := object.(*discovery_v1.EndpointSlice)
 				if !success {
@@ -201,13 +283,55 @@ func newOwnerProvider(
 		)
 	}
 
+	// Only enable Service informer when Service metadata other than the name is extracted
+	// or Services need to be resolved by their IPs
+	if extractionRules.watchServices() {
+		logger.Debug("adding informer for Service", zap.String("api_version", "v1"))
+		op.addOwnerInformer("Service",
+			namespace,
+			factory.Core().V1().Services().Informer(),
+			op.cacheService,
+			op.deleteService,
+			nil,
+			func(object interface{}) (interface{}, error) {
+				originalService, success := object.(*api_v1.Service)
+				if !success {
+					return object.(cache.DeletedFinalStateUnknown), nil
+				} else {
+					return removeUnnecessaryServiceData(originalService, extractionRules), nil
+				}
+			},
+		)
+	}
+
+	// Only enable Ingress informer when Ingress host extraction rule is enabled
+	if extractionRules.IngressHost {
+		logger.Debug("adding informer for Ingress", zap.String("api_version", "networking.k8s.io/v1"))
+		op.addOwnerInformer("Ingress",
+			namespace,
+			factory.Networking().V1().Ingresses().Informer(),
+			op.cacheIngress,
+			op.deleteIngress,
+			nil,
+			func(object interface{}) (interface{}, error) {
+				originalIngress, success := object.(*networking_v1.Ingress)
+				if !success {
+					return object.(cache.DeletedFinalStateUnknown), nil
+				} else {
+					return removeUnnecessaryIngressData(originalIngress), nil
+				}
+			},
+		)
+	}
+
 	// Only enable Job informer when Job or CronJob extraction rule is enabled
 	if extractionRules.JobName || extractionRules.CronJobName {
 		logger.Debug("adding informer for Job", zap.String("api_version", "batch/v1"))
-		ownerCache.addOwnerInformer("Job",
+		op.addOwnerInformer("Job",
+			namespace,
 			factory.Batch().V1().Jobs().Informer(),
-			ownerCache.cacheObject,
-			ownerCache.deleteObject,
+			op.cacheObject,
+			op.deleteObject,
 			nil,
 			nil,
 		)
@@ -215,69 +339,62 @@ func newOwnerProvider(
 
 	// Only enable CronJob informer when CronJob extraction rule is enabled
 	// and when a particular API is available on the cluster
-	if extractionRules.CronJobName {
-		// Other resources used in remaining informers are all available in all supported
-		// cluster versions. Only CronJob from batch/v1 is available starting with k8s 1.21
-		// hence make this conditional on the supported batch API group version.
-		apiGroups, apiResList, err := client.Discovery().ServerGroupsAndResources()
-		if err != nil {
-			ownerCache.logger.Debug(
-				"failed to get server resources with client-go",
-				zap.Error(err),
-			)
-		} else {
-			enableCronJobInformer := func(informer cache.SharedIndexInformer) {
-				ownerCache.addOwnerInformer("CronJob",
-					informer,
-					ownerCache.cacheObject,
-					ownerCache.deleteObject,
-					nil,
-					nil,
-				)
-			}
+	var cronJobInformer cache.SharedIndexInformer
+	switch cronJobVersion {
+	case "batch/v1":
+		cronJobInformer = factory.Batch().V1().CronJobs().Informer()
+	case "batch/v1beta1":
+		cronJobInformer = factory.Batch().V1beta1().CronJobs().Informer()
+	}
+	if cronJobInformer != nil {
+		logger.Debug("adding informer for CronJob", zap.String("api_version", cronJobVersion))
+		op.addOwnerInformer("CronJob",
+			namespace,
+			cronJobInformer,
+			op.cacheObject,
+			op.deleteObject,
+			nil,
+			nil,
+		)
+	}
+}
 
-			handleAPIResources := func(informer cache.SharedIndexInformer, apiResources []meta_v1.APIResource) bool {
-				for _, apiR := range apiResources {
-					if apiR.Name == "cronjobs" && apiR.Kind == "CronJob" {
-						logger.Debug("adding informer for CronJob", zap.String("api_version", apiR.Version))
-						enableCronJobInformer(informer)
-						return true
-					}
-				}
-				return false
-			}
+// getCronJobVersion returns the preferred version of the batch API group if CronJobs are available in it.
+// Other resources used in the informers are all available in all supported
+// cluster versions. Only CronJob from batch/v1 is available starting with k8s 1.21
+// hence make this conditional on the supported batch API group version.
+func getCronJobVersion(logger *zap.Logger, client kubernetes.Interface) string {
+	apiGroups, apiResList, err := client.Discovery().ServerGroupsAndResources()
+	if err != nil {
+		logger.Debug(
+			"failed to get server resources with client-go",
+			zap.Error(err),
+		)
+		return ""
+	}
 
-			var preferredBatchVersion string
-			for _, g := range apiGroups {
-				if g.Name == "batch" {
-					preferredBatchVersion = g.PreferredVersion.GroupVersion
-					break
-				}
-			}
+	var preferredBatchVersion string
+	for _, g := range apiGroups {
+		if g.Name == "batch" {
+			preferredBatchVersion = g.PreferredVersion.GroupVersion
+			break
+		}
+	}
+	if preferredBatchVersion != "batch/v1" && preferredBatchVersion != "batch/v1beta1" {
+		return ""
+	}
 
-			if preferredBatchVersion != "" {
-			outerLoop:
-				for _, v := range apiResList {
-					if v.GroupVersion == preferredBatchVersion {
-						switch v.GroupVersion {
-						case "batch/v1":
-							informer := factory.Batch().V1().CronJobs().Informer()
-							if enabled := handleAPIResources(informer, v.APIResources); enabled {
-								break outerLoop
-							}
-						case "batch/v1beta1":
-							informer := factory.Batch().V1beta1().CronJobs().Informer()
-							if enabled := handleAPIResources(informer, v.APIResources); enabled {
-								break outerLoop
-							}
-						}
-					}
-				}
+	for _, v := range apiResList {
+		if v.GroupVersion != preferredBatchVersion {
+			continue
+		}
+		for _, apiR := range v.APIResources {
+			if apiR.Name == "cronjobs" && apiR.Kind == "CronJob" {
+				return preferredBatchVersion
 			}
 		}
 	}
-
-	return &ownerCache, nil
+	return ""
 }
 
 func (op *OwnerCache) upsertNamespace(obj interface{}) {
@@ -313,8 +430,8 @@ func (op *OwnerCache) deleteNamespace(obj interface{}) {
 	op.nsMutex.Unlock()
 }
 
-func (op *OwnerCache) addNamespaceInformer(factory informers.SharedInformerFactory) {
-	op.logger.Debug("adding informer for Namespace", zap.String("api_version", "v1"))
+func (op *OwnerCache) addNamespaceInformer(namespace string, factory informers.SharedInformerFactory) {
+	op.logger.Debug("adding informer for Namespace", zap.String("api_version", "v1"), zap.String("namespace", namespace))
 	informer := factory.Core().V1().Namespaces().Informer()
 	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj interface{}) {
@@ -334,7 +451,7 @@ func (op *OwnerCache) addNamespaceInformer(factory informers.SharedInformerFacto
 		op.logger.Error("error adding event handler to namespace informer", zap.Error(err))
 	}
 
-	op.informers = append(op.informers, informer)
+	op.informers = append(op.informers, newNamespacedInformer("Namespace", namespace, informer))
 }
 
 // deferredDelete returns a function that will handle deleting an object from
@@ -353,6 +470,7 @@ func (op *OwnerCache) deferredDelete(evict func(obj any)) func(any) {
 
 func (op *OwnerCache) addOwnerInformer(
 	kind string,
+	namespace string,
 	informer cache.SharedIndexInformer,
 	addFunc func(kind string, obj interface{}),
 	deleteFunc func(obj interface{}),
@@ -389,7 +507,7 @@ func (op *OwnerCache) addOwnerInformer(
 		}
 	}
 
-	op.informers = append(op.informers, informer)
+	op.informers = append(op.informers, newNamespacedInformer(kind, namespace, informer))
 }
 
 func (op *OwnerCache) deleteObject(obj interface{}) {
@@ -560,8 +678,173 @@ func (op *OwnerCache) cacheEndpointSlice(kind string, obj interface{}) {
 	op.genericEndpointSliceOp(obj, op.addServiceToPod)
 }
 
+func (op *OwnerCache) cacheService(kind string, obj interface{}) {
+	service := obj.(*api_v1.Service)
+	key := objectKey(service.Namespace, service.Name)
+
+	op.servicesMutex.Lock()
+	defer op.servicesMutex.Unlock()
+
+	if prev, ok := op.services[key]; ok {
+		op.removeServiceIPs(prev)
+	}
+	op.services[key] = service
+	for _, ip := range serviceClusterIPs(service) {
+		op.serviceIPs[ip] = key
+	}
+}
+
+func (op *OwnerCache) deleteService(obj interface{}) {
+	var service *api_v1.Service
+
+	switch obj := obj.(type) {
+	case *api_v1.Service:
+		service = obj
+	case cache.DeletedFinalStateUnknown:
+		prev, ok := obj.Obj.(*api_v1.Service)
+		if !ok {
+			op.logger.Error(
+				"object received was DeletedFinalStateUnknown but did not contain api_v1.Service",
+				zap.Any("received", obj),
+			)
+			return
+		}
+		service = prev
+	default:
+		op.logger.Error("object received was not of type api_v1.Service", zap.Any("received", obj))
+		return
+	}
+
+	key := objectKey(service.Namespace, service.Name)
+
+	op.servicesMutex.Lock()
+	if prev, ok := op.services[key]; ok {
+		op.removeServiceIPs(prev)
+	}
+	delete(op.services, key)
+	op.servicesMutex.Unlock()
+}
+
+// removeServiceIPs removes the cluster IPs of a given Service from the IP index.
+// It must be called with servicesMutex held.
+func (op *OwnerCache) removeServiceIPs(service *api_v1.Service) {
+	key := objectKey(service.Namespace, service.Name)
+	for _, ip := range serviceClusterIPs(service) {
+		// Sanity check: make sure the IP has not been assigned to another Service in the meantime
+		if op.serviceIPs[ip] == key {
+			delete(op.serviceIPs, ip)
+		}
+	}
+}
+
+func (op *OwnerCache) cacheIngress(kind string, obj interface{}) {
+	ingress := obj.(*networking_v1.Ingress)
+	ingressKey := objectKey(ingress.Namespace, ingress.Name)
+
+	hostsByService := map[string][]string{}
+	for _, rule := range ingress.Spec.Rules {
+		if rule.Host == "" || rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			if path.Backend.Service == nil {
+				continue
+			}
+			serviceKey := objectKey(ingress.Namespace, path.Backend.Service.Name)
+			if slices.Index(hostsByService[serviceKey], rule.Host) == -1 {
+				hostsByService[serviceKey] = append(hostsByService[serviceKey], rule.Host)
+			}
+		}
+	}
+
+	op.ingressMutex.Lock()
+	defer op.ingressMutex.Unlock()
+
+	op.removeIngressHosts(ingressKey)
+	for serviceKey, hosts := range hostsByService {
+		if _, ok := op.serviceIngressHosts[serviceKey]; !ok {
+			op.serviceIngressHosts[serviceKey] = map[string][]string{}
+		}
+		op.serviceIngressHosts[serviceKey][ingressKey] = hosts
+		op.ingressServices[ingressKey] = append(op.ingressServices[ingressKey], serviceKey)
+	}
+}
+
+func (op *OwnerCache) deleteIngress(obj interface{}) {
+	var ingress *networking_v1.Ingress
+
+	switch obj := obj.(type) {
+	case *networking_v1.Ingress:
+		ingress = obj
+	case cache.DeletedFinalStateUnknown:
+		prev, ok := obj.Obj.(*networking_v1.Ingress)
+		if !ok {
+			op.logger.Error(
+				"object received was DeletedFinalStateUnknown but did not contain Ingress",
+				zap.Any("received", obj),
+			)
+			return
+		}
+		ingress = prev
+	default:
+		op.logger.Error("object received was not of type Ingress", zap.Any("received", obj))
+		return
+	}
+
+	op.ingressMutex.Lock()
+	op.removeIngressHosts(objectKey(ingress.Namespace, ingress.Name))
+	op.ingressMutex.Unlock()
+}
+
+// removeIngressHosts removes the hosts of a given Ingress from all Services it routes to.
+// It must be called with ingressMutex held.
+func (op *OwnerCache) removeIngressHosts(ingressKey string) {
+	for _, serviceKey := range op.ingressServices[ingressKey] {
+		delete(op.serviceIngressHosts[serviceKey], ingressKey)
+		if len(op.serviceIngressHosts[serviceKey]) == 0 {
+			delete(op.serviceIngressHosts, serviceKey)
+		}
+	}
+	delete(op.ingressServices, ingressKey)
+}
+
+// GetService returns a cached Service object (if one is found) or nil otherwise
+func (op *OwnerCache) GetService(namespace string, name string) *api_v1.Service {
+	op.servicesMutex.RLock()
+	defer op.servicesMutex.RUnlock()
+	return op.services[objectKey(namespace, name)]
+}
+
+// GetServiceByIP returns a cached Service object with the given cluster IP (if one is found) or nil otherwise
+func (op *OwnerCache) GetServiceByIP(ip string) *api_v1.Service {
+	op.servicesMutex.RLock()
+	defer op.servicesMutex.RUnlock()
+	key, ok := op.serviceIPs[ip]
+	if !ok {
+		return nil
+	}
+	return op.services[key]
+}
+
+// GetIngressHosts returns a sorted slice of hosts of Ingresses routing to the given Service
+func (op *OwnerCache) GetIngressHosts(namespace string, serviceName string) []string {
+	op.ingressMutex.RLock()
+	defer op.ingressMutex.RUnlock()
+
+	hosts := []string{}
+	for _, ingressHosts := range op.serviceIngressHosts[objectKey(namespace, serviceName)] {
+		for _, host := range ingressHosts {
+			if slices.Index(hosts, host) == -1 {
+				hosts = append(hosts, host)
+			}
+		}
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
 // GetNamespaces returns a cached namespace object (if one is found) or nil otherwise
-func (op *OwnerCache) GetNamespace(pod *api_v1.Pod) *api_v1.Namespace {
+func (op *OwnerCache) GetNamespace(pod *Pod) *api_v1.Namespace {
 	op.nsMutex.RLock()
 	namespace, found := op.namespaces[pod.Namespace]
 	op.nsMutex.RUnlock()
@@ -713,3 +996,93 @@ func getServiceName(endpointSlice *discovery_v1.EndpointSlice) string {
 	serviceName := epLabels[endpointSliceServiceLabel]
 	return serviceName
 }
+
+// This function removes all data from the Service except what is required by extraction rules
+func removeUnnecessaryServiceData(service *api_v1.Service, rules ExtractionRules) *api_v1.Service {
+	// name and namespace are needed by the informer store
+	transformedService := api_v1.Service{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      service.GetName(),
+			Namespace: service.GetNamespace(),
+		},
+	}
+
+	if rules.ServiceType {
+		transformedService.Spec.Type = service.Spec.Type
+	}
+
+	if rules.ServiceClusterIP || rules.ServiceIPLookup {
+		transformedService.Spec.ClusterIP = service.Spec.ClusterIP
+	}
+
+	if rules.ServiceIPLookup {
+		transformedService.Spec.ClusterIPs = service.Spec.ClusterIPs
+	}
+
+	if rules.ServicePorts {
+		transformedService.Spec.Ports = service.Spec.Ports
+	}
+
+	if len(rules.ServiceLabels) > 0 {
+		transformedService.Labels = service.Labels
+	}
+
+	if len(rules.ServiceAnnotations) > 0 {
+		transformedService.Annotations = service.Annotations
+	}
+
+	return &transformedService
+}
+
+// This function removes all data from the Ingress except the hosts and backend Services of its rules
+func removeUnnecessaryIngressData(ingress *networking_v1.Ingress) *networking_v1.Ingress {
+	// name and namespace are needed by the informer store
+	transformedIngress := networking_v1.Ingress{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      ingress.GetName(),
+			Namespace: ingress.GetNamespace(),
+		},
+	}
+
+	for _, rule := range ingress.Spec.Rules {
+		if rule.Host == "" || rule.HTTP == nil {
+			continue
+		}
+		transformedRule := networking_v1.IngressRule{
+			Host: rule.Host,
+			IngressRuleValue: networking_v1.IngressRuleValue{
+				HTTP: &networking_v1.HTTPIngressRuleValue{},
+			},
+		}
+		for _, path := range rule.HTTP.Paths {
+			if path.Backend.Service == nil {
+				continue
+			}
+			transformedRule.HTTP.Paths = append(transformedRule.HTTP.Paths, networking_v1.HTTPIngressPath{
+				Backend: networking_v1.IngressBackend{
+					Service: &networking_v1.IngressServiceBackend{Name: path.Backend.Service.Name},
+				},
+			})
+		}
+		transformedIngress.Spec.Rules = append(transformedIngress.Spec.Rules, transformedRule)
+	}
+
+	return &transformedIngress
+}
+
+// serviceClusterIPs returns the cluster IPs of the Service, skipping headless Services
+func serviceClusterIPs(service *api_v1.Service) []string {
+	ips := []string{}
+	for _, ip := range append([]string{service.Spec.ClusterIP}, service.Spec.ClusterIPs...) {
+		if ip == "" || ip == api_v1.ClusterIPNone || slices.Index(ips, ip) >= 0 {
+			continue
+		}
+		ips = append(ips, ip)
+	}
+	return ips
+}
+
+// objectKey returns the key used to identify namespaced objects in the cache
+func objectKey(namespace string, name string) string {
+	return namespace + "/" + name
+}
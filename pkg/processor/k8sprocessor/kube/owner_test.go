@@ -14,6 +14,7 @@ import (
 	batch_v1 "k8s.io/api/batch/v1"
 	api_v1 "k8s.io/api/core/v1"
 	discovery_v1 "k8s.io/api/discovery/v1"
+	networking_v1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
@@ -70,7 +71,7 @@ func Test_OwnerProvider_GetOwners_ReplicaSet(t *testing.T) {
 			ReplicaSetName:     true,
 			Tags:               NewExtractionFieldTags(),
 		},
-		"kube-system",
+		[]string{"kube-system"},
 		// relatively short delete interval and grace periods for expediencey
 		time.Millisecond*10, time.Millisecond*500,
 	)
@@ -167,7 +168,7 @@ func Test_OwnerProvider_GetOwners_Deployment(t *testing.T) {
 			DeploymentName:     true,
 			Tags:               NewExtractionFieldTags(),
 		},
-		"kube-system",
+		[]string{"kube-system"},
 		time.Second*30, DefaultPodDeleteGracePeriod,
 	)
 	require.NoError(t, err)
@@ -280,7 +281,7 @@ func Test_OwnerProvider_GetOwners_Statefulset(t *testing.T) {
 			OwnerLookupEnabled: true,
 			Tags:               NewExtractionFieldTags(),
 		},
-		"kube-system",
+		[]string{"kube-system"},
 		time.Second*30, DefaultPodDeleteGracePeriod,
 	)
 	require.NoError(t, err)
@@ -367,7 +368,7 @@ func Test_OwnerProvider_GetOwners_Daemonset(t *testing.T) {
 			OwnerLookupEnabled: true,
 			Tags:               NewExtractionFieldTags(),
 		},
-		"kube-system",
+		[]string{"kube-system"},
 		time.Second*30, DefaultPodDeleteGracePeriod,
 	)
 	require.NoError(t, err)
@@ -459,7 +460,7 @@ func Test_OwnerProvider_GetServices(t *testing.T) {
 			OwnerLookupEnabled: true,
 			Tags:               NewExtractionFieldTags(),
 		},
-		namespace,
+		[]string{namespace},
 		time.Millisecond*10, gracePeriod,
 	)
 	require.NoError(t, err)
@@ -649,7 +650,7 @@ func Test_OwnerProvider_GetOwners_Job(t *testing.T) {
 			JobName:            true,
 			Tags:               NewExtractionFieldTags(),
 		},
-		"kube-system",
+		[]string{"kube-system"},
 		time.Second*30, DefaultPodDeleteGracePeriod,
 	)
 	require.NoError(t, err)
@@ -736,7 +737,7 @@ func Test_OwnerProvider_GetOwners_CronJob(t *testing.T) {
 			CronJobName:        true,
 			Tags:               NewExtractionFieldTags(),
 		},
-		"kube-system",
+		[]string{"kube-system"},
 		time.Second*30, DefaultPodDeleteGracePeriod,
 	)
 	require.NoError(t, err)
@@ -850,7 +851,7 @@ func Test_OwnerProvider_GetNamespace(t *testing.T) {
 			ReplicaSetName:     true,
 			Tags:               NewExtractionFieldTags(),
 		},
-		"kube-system",
+		[]string{"kube-system"},
 		// relatively short delete interval and grace periods for expediencey
 		time.Millisecond*10, gracePeriod,
 	)
@@ -889,7 +890,7 @@ func Test_OwnerProvider_GetNamespace(t *testing.T) {
 	require.NoError(t, err)
 
 	assert.Eventually(t, func() bool {
-		ns := op.GetNamespace(pod)
+		ns := op.GetNamespace(&Pod{Namespace: pod.Namespace})
 		if ns == nil {
 			return false
 		}
@@ -909,7 +910,7 @@ func Test_OwnerProvider_GetNamespace(t *testing.T) {
 
 	var ttd time.Duration
 	assert.Eventually(t, func() bool {
-		ns := op.GetNamespace(pod)
+		ns := op.GetNamespace(&Pod{Namespace: pod.Namespace})
 		if ns != nil {
 			return false
 		}
@@ -920,6 +921,224 @@ func Test_OwnerProvider_GetNamespace(t *testing.T) {
 	assert.GreaterOrEqual(t, ttd, gracePeriod)
 }
 
+func Test_OwnerProvider_GetService(t *testing.T) {
+	const (
+		namespace = "kube-system"
+	)
+
+	c, err := newFakeAPIClientset(k8sconfig.APIConfig{})
+	require.NoError(t, err)
+
+	logger, err := zap.NewDevelopment()
+	require.NoError(t, err)
+
+	op, err := newOwnerProvider(
+		logger,
+		c,
+		labels.Everything(),
+		fields.Everything(),
+		ExtractionRules{
+			ServiceType:        true,
+			ServicePorts:       true,
+			OwnerLookupEnabled: true,
+			Tags:               NewExtractionFieldTags(),
+		},
+		[]string{namespace},
+		// relatively short delete interval and grace periods for expediencey
+		time.Millisecond*10, time.Millisecond*100,
+	)
+	require.NoError(t, err)
+
+	client := c.(*fake.Clientset)
+	serviceWatchEstablished := waitForWatchToBeEstablished(client, "services")
+
+	op.Start()
+	t.Cleanup(func() {
+		op.Stop()
+	})
+
+	<-serviceWatchEstablished
+
+	_, err = c.CoreV1().Services(namespace).Create(context.Background(),
+		&api_v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "my-service",
+				Namespace: namespace,
+				Labels:    map[string]string{"app": "my-app"},
+			},
+			Spec: api_v1.ServiceSpec{
+				Type:      api_v1.ServiceTypeNodePort,
+				ClusterIP: "10.0.0.10",
+				Ports: []api_v1.ServicePort{
+					{Port: 443, Protocol: api_v1.ProtocolTCP},
+				},
+			},
+		}, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		return op.GetService(namespace, "my-service") != nil
+	}, 5*time.Second, 5*time.Millisecond)
+
+	service := op.GetService(namespace, "my-service")
+	assert.Equal(t, api_v1.ServiceTypeNodePort, service.Spec.Type)
+	assert.Equal(t, []api_v1.ServicePort{{Port: 443, Protocol: api_v1.ProtocolTCP}}, service.Spec.Ports)
+	// data not required by the extraction rules is removed
+	assert.Empty(t, service.Spec.ClusterIP)
+	assert.Empty(t, service.Labels)
+	assert.Nil(t, op.GetService("other-namespace", "my-service"))
+
+	err = c.CoreV1().Services(namespace).Delete(
+		context.Background(), "my-service", metav1.DeleteOptions{})
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		return op.GetService(namespace, "my-service") == nil
+	}, 5*time.Second, 5*time.Millisecond)
+}
+
+func Test_OwnerCache_GetServiceByIP(t *testing.T) {
+	op := newOwnerCache(zap.NewNop())
+
+	service := &api_v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-service",
+			Namespace: "default",
+		},
+		Spec: api_v1.ServiceSpec{
+			ClusterIP:  "10.0.0.10",
+			ClusterIPs: []string{"10.0.0.10", "fd00::10"},
+		},
+	}
+	headless := &api_v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "headless",
+			Namespace: "default",
+		},
+		Spec: api_v1.ServiceSpec{
+			ClusterIP: api_v1.ClusterIPNone,
+		},
+	}
+	op.cacheService("Service", service)
+	op.cacheService("Service", headless)
+
+	assert.Equal(t, service, op.GetServiceByIP("10.0.0.10"))
+	assert.Equal(t, service, op.GetServiceByIP("fd00::10"))
+	assert.Nil(t, op.GetServiceByIP(api_v1.ClusterIPNone))
+
+	// the IP of the Service changed
+	updated := service.DeepCopy()
+	updated.Spec.ClusterIP = "10.0.0.11"
+	updated.Spec.ClusterIPs = []string{"10.0.0.11"}
+	op.cacheService("Service", updated)
+
+	assert.Nil(t, op.GetServiceByIP("10.0.0.10"))
+	assert.Nil(t, op.GetServiceByIP("fd00::10"))
+	assert.Equal(t, updated, op.GetServiceByIP("10.0.0.11"))
+
+	op.deleteService(updated)
+	assert.Nil(t, op.GetServiceByIP("10.0.0.11"))
+	assert.Empty(t, op.serviceIPs)
+}
+
+func Test_OwnerProvider_GetIngressHosts(t *testing.T) {
+	const (
+		namespace = "kube-system"
+	)
+
+	c, err := newFakeAPIClientset(k8sconfig.APIConfig{})
+	require.NoError(t, err)
+
+	logger, err := zap.NewDevelopment()
+	require.NoError(t, err)
+
+	op, err := newOwnerProvider(
+		logger,
+		c,
+		labels.Everything(),
+		fields.Everything(),
+		ExtractionRules{
+			IngressHost:        true,
+			OwnerLookupEnabled: true,
+			Tags:               NewExtractionFieldTags(),
+		},
+		[]string{namespace},
+		// relatively short delete interval and grace periods for expediencey
+		time.Millisecond*10, time.Millisecond*100,
+	)
+	require.NoError(t, err)
+
+	client := c.(*fake.Clientset)
+	ingressWatchEstablished := waitForWatchToBeEstablished(client, "ingresses")
+
+	op.Start()
+	t.Cleanup(func() {
+		op.Stop()
+	})
+
+	<-ingressWatchEstablished
+
+	newRule := func(host string, services ...string) networking_v1.IngressRule {
+		rule := networking_v1.IngressRule{
+			Host: host,
+			IngressRuleValue: networking_v1.IngressRuleValue{
+				HTTP: &networking_v1.HTTPIngressRuleValue{},
+			},
+		}
+		for _, service := range services {
+			rule.HTTP.Paths = append(rule.HTTP.Paths, networking_v1.HTTPIngressPath{
+				Backend: networking_v1.IngressBackend{
+					Service: &networking_v1.IngressServiceBackend{Name: service},
+				},
+			})
+		}
+		return rule
+	}
+
+	ingress := &networking_v1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-ingress",
+			Namespace: namespace,
+		},
+		Spec: networking_v1.IngressSpec{
+			Rules: []networking_v1.IngressRule{
+				newRule("b.example.com", "my-service"),
+				newRule("a.example.com", "my-service", "other-service"),
+			},
+		},
+	}
+	_, err = c.NetworkingV1().Ingresses(namespace).Create(context.Background(), ingress, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		return len(op.GetIngressHosts(namespace, "my-service")) == 2
+	}, 5*time.Second, 5*time.Millisecond)
+	assert.Equal(t, []string{"a.example.com", "b.example.com"}, op.GetIngressHosts(namespace, "my-service"))
+	assert.Equal(t, []string{"a.example.com"}, op.GetIngressHosts(namespace, "other-service"))
+
+	// the Service is removed from one of the rules
+	ingress.Spec.Rules = []networking_v1.IngressRule{
+		newRule("b.example.com", "my-service"),
+		newRule("a.example.com", "other-service"),
+	}
+	_, err = c.NetworkingV1().Ingresses(namespace).Update(context.Background(), ingress, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		return len(op.GetIngressHosts(namespace, "my-service")) == 1
+	}, 5*time.Second, 5*time.Millisecond)
+	assert.Equal(t, []string{"b.example.com"}, op.GetIngressHosts(namespace, "my-service"))
+
+	err = c.NetworkingV1().Ingresses(namespace).Delete(
+		context.Background(), "my-ingress", metav1.DeleteOptions{})
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		return len(op.GetIngressHosts(namespace, "my-service")) == 0 &&
+			len(op.GetIngressHosts(namespace, "other-service")) == 0
+	}, 5*time.Second, 5*time.Millisecond)
+}
+
 func Test_OwnerCache_DeferredDeleteLoop(t *testing.T) {
 	logger, err := zap.NewDevelopment()
 	require.NoError(t, err)
@@ -0,0 +1,179 @@
+// Copyright 2020 OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kube
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// CacheSnapshot is a point in time copy of the client cache, used for debugging.
+type CacheSnapshot struct {
+	// Synced is true once the initial sync of all informers has completed
+	Synced bool `json:"synced"`
+	// Informers holds the initial sync status of informers by the kind of objects they watch,
+	// followed by the namespace if they only watch a single namespace. Informers stopped because
+	// of missing permissions are reported as synced.
+	Informers map[string]bool `json:"informers"`
+	// Pods holds the cached pods by identifier
+	Pods map[PodIdentifier]PodSnapshot `json:"pods"`
+	// DeleteQueue holds the pods waiting to be removed from the cache
+	DeleteQueue []DeleteQueueEntry `json:"delete_queue"`
+	// OwnerDeleteQueueLength is the number of owner objects waiting to be removed from the cache
+	OwnerDeleteQueueLength int `json:"owner_delete_queue_length"`
+}
+
+// PodSnapshot describes a cached pod.
+type PodSnapshot struct {
+	Name       string            `json:"name"`
+	Namespace  string            `json:"namespace"`
+	PodUID     string            `json:"uid"`
+	Address    string            `json:"address"`
+	Ignore     bool              `json:"ignore"`
+	Excluded   bool              `json:"excluded"`
+	Attributes map[string]string `json:"attributes"`
+	Owners     []OwnerSnapshot   `json:"owners,omitempty"`
+}
+
+// OwnerSnapshot describes an owner of a cached pod.
+type OwnerSnapshot struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	UID       string `json:"uid"`
+}
+
+// DeleteQueueEntry describes a pod waiting to be removed from the cache.
+type DeleteQueueEntry struct {
+	ID        PodIdentifier `json:"id"`
+	PodName   string        `json:"pod_name"`
+	Timestamp time.Time     `json:"timestamp"`
+}
+
+// HasSynced returns true once the initial sync of the pod informer,
+// and owner informers if owner lookup is enabled, has completed.
+// Owner informers stopped because of missing permissions count as synced, pod informers don't.
+func (c *WatchClient) HasSynced() bool {
+	for _, informer := range c.informers {
+		if !c.podInformerSynced(informer) {
+			return false
+		}
+	}
+	if c.op == nil {
+		return true
+	}
+	for _, synced := range c.op.InformersSynced() {
+		if !synced {
+			return false
+		}
+	}
+	return true
+}
+
+// SyncError returns an error if a pod informer was stopped because of missing permissions,
+// in which case the cache is never synced.
+func (c *WatchClient) SyncError() error {
+	for _, informer := range c.informers {
+		if !informer.forbidden.Load() {
+			continue
+		}
+		if informer.namespace == "" {
+			return errors.New("missing permissions to list and watch pods")
+		}
+		return fmt.Errorf("missing permissions to list and watch pods in namespace %s", informer.namespace)
+	}
+	return nil
+}
+
+func (c *WatchClient) podInformerSynced(informer *namespacedInformer) bool {
+	return !informer.forbidden.Load() && informer.hasSynced()
+}
+
+// Snapshot returns a copy of the cached data.
+func (c *WatchClient) Snapshot() CacheSnapshot {
+	snapshot := CacheSnapshot{
+		Informers: map[string]bool{},
+		Pods:      map[PodIdentifier]PodSnapshot{},
+	}
+	for _, informer := range c.informers {
+		snapshot.Informers[informer.name()] = c.podInformerSynced(informer)
+	}
+	if c.op != nil {
+		for kind, synced := range c.op.InformersSynced() {
+			snapshot.Informers[kind] = synced
+		}
+		if oc, ok := c.op.(*OwnerCache); ok {
+			snapshot.OwnerDeleteQueueLength = oc.deleteQueueLen()
+		}
+	}
+	snapshot.Synced = true
+	for _, synced := range snapshot.Informers {
+		snapshot.Synced = snapshot.Synced && synced
+	}
+
+	c.m.RLock()
+	pods := make(map[PodIdentifier]*Pod, len(c.Pods))
+	for id, pod := range c.Pods {
+		pods[id] = pod
+	}
+	for id, pod := range pods {
+		attributes := make(map[string]string, len(pod.Attributes))
+		for key, value := range pod.Attributes {
+			attributes[key] = value
+		}
+		snapshot.Pods[id] = PodSnapshot{
+			Name:       pod.Name,
+			Namespace:  pod.Namespace,
+			PodUID:     pod.PodUID,
+			Address:    pod.Address,
+			Ignore:     pod.Ignore,
+			Excluded:   pod.Excluded,
+			Attributes: attributes,
+		}
+	}
+	c.m.RUnlock()
+
+	// owners are looked up without holding the pods lock, as the owner cache has its own locking
+	if c.op != nil {
+		for id, pod := range pods {
+			if pod.OwnerReferences == nil {
+				continue
+			}
+			ps := snapshot.Pods[id]
+			for _, owner := range c.op.GetOwners(pod) {
+				ps.Owners = append(ps.Owners, OwnerSnapshot{
+					Kind:      owner.kind,
+					Name:      owner.name,
+					Namespace: owner.namespace,
+					UID:       string(owner.UID),
+				})
+			}
+			snapshot.Pods[id] = ps
+		}
+	}
+
+	c.deleteMut.Lock()
+	for _, d := range c.deleteQueue {
+		snapshot.DeleteQueue = append(snapshot.DeleteQueue, DeleteQueueEntry{
+			ID:        d.id,
+			PodName:   d.podName,
+			Timestamp: d.ts,
+		})
+	}
+	c.deleteMut.Unlock()
+
+	return snapshot
+}
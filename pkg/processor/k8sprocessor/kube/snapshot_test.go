@@ -0,0 +1,86 @@
+// Copyright 2020 OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kube
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	api_v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestSnapshot(t *testing.T) {
+	c, _ := newTestClientWithRulesAndFilters(t, ExtractionRules{
+		PodName:            true,
+		OwnerLookupEnabled: true,
+		Tags:               NewExtractionFieldTags(),
+	}, Filters{})
+
+	pod := &api_v1.Pod{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "podA",
+			Namespace: "kube-system",
+			UID:       "aaa",
+			OwnerReferences: []meta_v1.OwnerReference{{
+				Kind: "ReplicaSet",
+				Name: "dearest-deploy-77c99ccb96",
+				UID:  types.UID("1a1658f9-7818-11e9-90f1-02324f7e0d1e"),
+			}},
+		},
+		Status: api_v1.PodStatus{PodIP: "1.1.1.1"},
+	}
+	c.handlePodAdd(pod)
+	c.handlePodDelete(pod)
+
+	snapshot := c.Snapshot()
+	assert.True(t, snapshot.Synced)
+	assert.Equal(t, map[string]bool{"Pod": true, "Namespace": true}, snapshot.Informers)
+	assert.True(t, c.HasSynced())
+
+	require.Contains(t, snapshot.Pods, PodIdentifier("1.1.1.1"))
+	got := snapshot.Pods["1.1.1.1"]
+	assert.Equal(t, "podA", got.Name)
+	assert.Equal(t, "kube-system", got.Namespace)
+	assert.Equal(t, "aaa", got.PodUID)
+	assert.Equal(t, map[string]string{"k8s.pod.name": "podA"}, got.Attributes)
+	assert.Equal(t, []OwnerSnapshot{
+		{Kind: "ReplicaSet", Name: "dearest-deploy-77c99ccb96", Namespace: "kube-system", UID: "1a1658f9-7818-11e9-90f1-02324f7e0d1e"},
+		{Kind: "Deployment", Name: "dearest-deploy", Namespace: "kube-system", UID: "94682908-e546-42cc-9972-62bcd09bd9de"},
+	}, got.Owners)
+
+	require.Len(t, snapshot.DeleteQueue, 3)
+	assert.Equal(t, "podA", snapshot.DeleteQueue[0].PodName)
+}
+
+func TestPodInformerForbidden(t *testing.T) {
+	c, _ := newTestClient(t)
+	assert.True(t, c.HasSynced())
+	assert.NoError(t, c.SyncError())
+
+	// without permissions to pods, the cache is never synced
+	require.Len(t, c.informers, 1)
+	c.informers[0].forbidden.Store(true)
+	assert.False(t, c.HasSynced())
+	assert.EqualError(t, c.SyncError(), "missing permissions to list and watch pods")
+	assert.Equal(t, map[string]bool{"Pod": false}, c.Snapshot().Informers)
+
+	c, _ = newTestClientWithRulesAndFilters(t, ExtractionRules{}, Filters{Namespaces: []string{"ns1", "ns2"}})
+	require.Len(t, c.informers, 2)
+	c.informers[1].forbidden.Store(true)
+	assert.EqualError(t, c.SyncError(), "missing permissions to list and watch pods in namespace ns2")
+}
@@ -0,0 +1,155 @@
+// Copyright 2020 OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sprocessor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/k8sprocessor/kube"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/k8sprocessor/observability"
+)
+
+const (
+	metadataEventsScopeName = "github.com/open-telemetry/opentelemetry-collector-contrib/processor/k8sprocessor"
+
+	metadataEventTypeAttribute    = "k8s.metadata.event"
+	metadataEventAddedAttribute   = "k8s.metadata.added"
+	metadataEventRemovedAttribute = "k8s.metadata.removed"
+	metadataEventChangedAttribute = "k8s.metadata.changed"
+
+	metadataEventsQueueSize = 1000
+)
+
+// metadataEventsEmitter converts Pod metadata change events into log records
+// and sends them to the next logs consumer.
+type metadataEventsEmitter struct {
+	logger *zap.Logger
+	next   consumer.Logs
+	tags   kube.ExtractionFieldTags
+
+	events chan kube.PodEvent
+	// dropping is set while the queue is full, so that dropped events are logged once
+	dropping atomic.Bool
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+func newMetadataEventsEmitter(logger *zap.Logger, tags kube.ExtractionFieldTags, next consumer.Logs) *metadataEventsEmitter {
+	return &metadataEventsEmitter{
+		logger: logger,
+		next:   next,
+		tags:   tags,
+		events: make(chan kube.PodEvent, metadataEventsQueueSize),
+		stopCh: make(chan struct{}),
+	}
+}
+
+// handle queues the event. It never blocks, so that it doesn't stall the informer.
+// Dropped events are counted in a metric, only the first one of a series is logged.
+func (e *metadataEventsEmitter) handle(event kube.PodEvent) {
+	select {
+	case e.events <- event:
+		e.dropping.Store(false)
+	default:
+		observability.RecordMetadataEventDropped()
+		if !e.dropping.Swap(true) {
+			e.logger.Warn(
+				"Metadata events queue is full, dropping events until it has free space",
+				zap.String("pod", event.Name),
+				zap.String("namespace", event.Namespace),
+				zap.String("type", string(event.Type)),
+			)
+		}
+	}
+}
+
+func (e *metadataEventsEmitter) start() {
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		for {
+			select {
+			case <-e.stopCh:
+				return
+			case event := <-e.events:
+				if err := e.next.ConsumeLogs(context.Background(), e.toLogs(event)); err != nil {
+					e.logger.Error("Failed to send metadata event", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+func (e *metadataEventsEmitter) stop() {
+	e.stopOnce.Do(func() {
+		close(e.stopCh)
+	})
+	e.wg.Wait()
+}
+
+func (e *metadataEventsEmitter) toLogs(event kube.PodEvent) plog.Logs {
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	resourceAttrs := rl.Resource().Attributes()
+	resourceAttrs.PutStr(e.tags.PodName, event.Name)
+	resourceAttrs.PutStr(e.tags.Namespace, event.Namespace)
+	if event.PodUID != "" {
+		resourceAttrs.PutStr(e.tags.PodUID, event.PodUID)
+	}
+
+	sl := rl.ScopeLogs().AppendEmpty()
+	sl.Scope().SetName(metadataEventsScopeName)
+
+	record := sl.LogRecords().AppendEmpty()
+	ts := pcommon.NewTimestampFromTime(event.Timestamp)
+	record.SetTimestamp(ts)
+	record.SetObservedTimestamp(ts)
+	record.SetSeverityNumber(plog.SeverityNumberInfo)
+	record.Body().SetStr(fmt.Sprintf("Pod %s/%s metadata %s", event.Namespace, event.Name, event.Type))
+
+	attrs := record.Attributes()
+	attrs.PutStr(metadataEventTypeAttribute, string(event.Type))
+	if len(event.Added) > 0 {
+		added := attrs.PutEmptyMap(metadataEventAddedAttribute)
+		for key, value := range event.Added {
+			added.PutStr(key, value)
+		}
+	}
+	if len(event.Removed) > 0 {
+		removed := attrs.PutEmptyMap(metadataEventRemovedAttribute)
+		for key, value := range event.Removed {
+			removed.PutStr(key, value)
+		}
+	}
+	if len(event.Changed) > 0 {
+		changed := attrs.PutEmptyMap(metadataEventChangedAttribute)
+		for key, change := range event.Changed {
+			c := changed.PutEmptyMap(key)
+			c.PutStr("old", change.Old)
+			c.PutStr("new", change.New)
+		}
+	}
+	return ld
+}
@@ -0,0 +1,134 @@
+// Copyright 2020 OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sprocessor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/k8sprocessor/kube"
+)
+
+func TestMetadataEventsToLogs(t *testing.T) {
+	e := newMetadataEventsEmitter(zap.NewNop(), kube.NewExtractionFieldTags(), consumertest.NewNop())
+	ts := time.Unix(1700000000, 0)
+
+	ld := e.toLogs(kube.PodEvent{
+		Type:      kube.PodEventUpdated,
+		Timestamp: ts,
+		Name:      "my-pod",
+		Namespace: "my-namespace",
+		PodUID:    "aaa-bbb",
+		Added:     map[string]string{"k8s.pod.label.new": "value"},
+		Removed:   map[string]string{"k8s.pod.label.old": "value"},
+		Changed: map[string]kube.AttributeChange{
+			"k8s.pod.label.version": {Old: "1", New: "2"},
+		},
+	})
+
+	require.Equal(t, 1, ld.LogRecordCount())
+	rl := ld.ResourceLogs().At(0)
+	assert.Equal(t, map[string]any{
+		"k8s.pod.name":       "my-pod",
+		"k8s.namespace.name": "my-namespace",
+		"k8s.pod.uid":        "aaa-bbb",
+	}, rl.Resource().Attributes().AsRaw())
+
+	record := rl.ScopeLogs().At(0).LogRecords().At(0)
+	assert.Equal(t, ts.UnixNano(), record.Timestamp().AsTime().UnixNano())
+	assert.Equal(t, plog.SeverityNumberInfo, record.SeverityNumber())
+	assert.Equal(t, "Pod my-namespace/my-pod metadata updated", record.Body().Str())
+	assert.Equal(t, map[string]any{
+		"k8s.metadata.event":   "updated",
+		"k8s.metadata.added":   map[string]any{"k8s.pod.label.new": "value"},
+		"k8s.metadata.removed": map[string]any{"k8s.pod.label.old": "value"},
+		"k8s.metadata.changed": map[string]any{
+			"k8s.pod.label.version": map[string]any{"old": "1", "new": "2"},
+		},
+	}, record.Attributes().AsRaw())
+}
+
+func TestMetadataEventsEmitted(t *testing.T) {
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	cfg.MetadataEvents.Enabled = true
+	m := newMultiTest(t, cfg, nil)
+
+	assert.Nil(t, m.kpTrace.kc.(*fakeClient).PodEventHandler)
+	assert.Nil(t, m.kpMetrics.kc.(*fakeClient).PodEventHandler)
+	handler := m.kpLogs.kc.(*fakeClient).PodEventHandler
+	require.NotNil(t, handler)
+
+	require.NoError(t, m.lp.Start(context.Background(), componenttest.NewNopHost()))
+	handler(kube.PodEvent{
+		Type:      kube.PodEventAdded,
+		Timestamp: time.Now(),
+		Name:      "my-pod",
+		Namespace: "my-namespace",
+		Added:     map[string]string{"k8s.pod.name": "my-pod"},
+	})
+
+	assert.Eventually(t, func() bool {
+		return m.nextLogs.LogRecordCount() == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, m.lp.Shutdown(context.Background()))
+
+	record := m.nextLogs.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	event, ok := record.Attributes().Get("k8s.metadata.event")
+	require.True(t, ok)
+	assert.Equal(t, "added", event.Str())
+}
+
+func TestMetadataEventsQueueFull(t *testing.T) {
+	core, logs := observer.New(zapcore.WarnLevel)
+	e := newMetadataEventsEmitter(zap.New(core), kube.NewExtractionFieldTags(), consumertest.NewNop())
+
+	event := kube.PodEvent{Type: kube.PodEventAdded, Name: "my-pod", Namespace: "my-namespace"}
+	for i := 0; i < metadataEventsQueueSize+10; i++ {
+		e.handle(event)
+	}
+	assert.Len(t, e.events, metadataEventsQueueSize)
+	assert.Equal(t, 1, logs.Len(), "dropped events should be logged once")
+
+	// dropping is logged again after the queue had free space
+	<-e.events
+	e.handle(event)
+	e.handle(event)
+	assert.Equal(t, 2, logs.Len())
+}
+
+func TestMetadataEventsStopTwice(t *testing.T) {
+	e := newMetadataEventsEmitter(zap.NewNop(), kube.NewExtractionFieldTags(), consumertest.NewNop())
+	e.start()
+	e.stop()
+	assert.NotPanics(t, e.stop)
+}
+
+func TestMetadataEventsDisabledInPassthrough(t *testing.T) {
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	cfg.MetadataEvents.Enabled = true
+	cfg.Passthrough = true
+	m := newMultiTest(t, cfg, nil)
+	assert.Nil(t, m.kpLogs.metadataEvents)
+}
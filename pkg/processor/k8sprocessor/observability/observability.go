@@ -37,6 +37,7 @@ func init() {
 		viewServiceTableSize,
 		viewIPLookupMiss,
 		viewPodTableSize,
+		viewMetadataEventsDropped,
 	)
 }
 
@@ -54,6 +55,8 @@ var (
 
 	mIPLookupMiss = stats.Int64("otelsvc/k8s/ip_lookup_miss", "Number of times pod by IP lookup failed.", "1")
 
+	mMetadataEventsDropped = stats.Int64("otelsvc/k8s/metadata_events_dropped", "Number of pod metadata events dropped because the queue was full", "1")
+
 	resourceKind, _ = tag.NewKey("kind") // nolint:errcheck
 )
 
@@ -129,6 +132,13 @@ var viewPodTableSize = &view.View{
 	Aggregation: view.LastValue(),
 }
 
+var viewMetadataEventsDropped = &view.View{
+	Name:        mMetadataEventsDropped.Name(),
+	Description: mMetadataEventsDropped.Description(),
+	Measure:     mMetadataEventsDropped,
+	Aggregation: view.Sum(),
+}
+
 // RecordPodUpdated increments the metric that records pod update events received.
 func RecordPodUpdated() {
 	stats.Record(context.Background(), mPodsUpdated.M(int64(1)))
@@ -193,3 +203,8 @@ func RecordIPLookupMiss() {
 func RecordPodTableSize(podTableSize int64) {
 	stats.Record(context.Background(), mPodTableSize.M(podTableSize))
 }
+
+// RecordMetadataEventDropped increments the metric that records dropped pod metadata events.
+func RecordMetadataEventDropped() {
+	stats.Record(context.Background(), mMetadataEventsDropped.M(int64(1)))
+}
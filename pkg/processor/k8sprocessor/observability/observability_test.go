@@ -93,6 +93,10 @@ func TestMetrics(t *testing.T) {
 				RecordServiceTableSize(1)
 			},
 		},
+		{
+			"otelsvc/k8s/metadata_events_dropped",
+			RecordMetadataEventDropped,
+		},
 	}
 
 	var (
@@ -19,8 +19,10 @@ import (
 	"os"
 	"regexp"
 	"strings"
+	"time"
 
 	conventions "go.opentelemetry.io/otel/semconv/v1.18.0"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/selection"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/k8sconfig"
@@ -33,6 +35,9 @@ const (
 	filterOPExists       = "exists"
 	filterOPDoesNotExist = "does-not-exist"
 
+	excludeModeSkipEnrichment = "skip_enrichment"
+	excludeModeDrop           = "drop"
+
 	metadataContainerID     = "containerId"
 	metadataContainerName   = "containerName"
 	metadataContainerImage  = "containerImage"
@@ -50,8 +55,17 @@ const (
 	metadataStartTime       = "startTime"
 	metadataStatefulSetName = "statefulSetName"
 
+	metadataServiceType      = "serviceType"
+	metadataServiceClusterIP = "serviceClusterIP"
+	metadataServicePorts     = "servicePorts"
+	metadataIngressHost      = "ingressHost"
+
 	metadataOtelSemconvServiceName = "k8s.service.name"  // no semantic convention for service name as of right now, but this is reasonable
 	metadataOtelPodStartTime       = "k8s.pod.startTime" // no semantic convention for this, but keeping a similar format for consistency
+	metadataOtelServiceType        = "k8s.service.type"
+	metadataOtelServiceClusterIP   = "k8s.service.cluster_ip"
+	metadataOtelServicePorts       = "k8s.service.ports"
+	metadataOtelIngressHost        = "k8s.ingress.host"
 	deprecatedMetadataClusterName  = "clusterName"
 )
 
@@ -141,6 +155,14 @@ func WithExtractMetadata(fields ...string) Option {
 				p.rules.StartTime = true
 			case metadataStatefulSetName, string(conventions.K8SStatefulSetNameKey):
 				p.rules.StatefulSetName = true
+			case metadataServiceType, metadataOtelServiceType:
+				p.rules.ServiceType = true
+			case metadataServiceClusterIP, metadataOtelServiceClusterIP:
+				p.rules.ServiceClusterIP = true
+			case metadataServicePorts, metadataOtelServicePorts:
+				p.rules.ServicePorts = true
+			case metadataIngressHost, metadataOtelIngressHost:
+				p.rules.IngressHost = true
 			case deprecatedMetadataClusterName, string(conventions.K8SClusterNameKey):
 				p.logger.Warn("clusterName metadata field has been deprecated and will be removed soon")
 			default:
@@ -185,6 +207,14 @@ func WithExtractTags(tagsMap map[string]string) Option {
 				tags.StartTime = tag
 			case strings.ToLower(metadataStatefulSetName):
 				tags.StatefulSetName = tag
+			case strings.ToLower(metadataServiceType):
+				tags.ServiceType = tag
+			case strings.ToLower(metadataServiceClusterIP):
+				tags.ServiceClusterIP = tag
+			case strings.ToLower(metadataServicePorts):
+				tags.ServicePorts = tag
+			case strings.ToLower(metadataIngressHost):
+				tags.IngressHost = tag
 			case strings.ToLower(deprecatedMetadataClusterName):
 				p.logger.Warn("clusterName metadata field has been deprecated and will be removed soon")
 			default:
@@ -244,6 +274,50 @@ func WithExtractNamespaceAnnotations(annotations ...FieldExtractConfig) Option {
 	}
 }
 
+// WithExtractServiceLabels allows specifying options to control extraction of labels of services the pod belongs to.
+func WithExtractServiceLabels(labels ...FieldExtractConfig) Option {
+	return func(p *kubernetesprocessor) error {
+		labels, err := extractFieldRules("service_labels", labels...)
+		if err != nil {
+			return err
+		}
+		p.rules.ServiceLabels = labels
+		return nil
+	}
+}
+
+// WithExtractServiceAnnotations allows specifying options to control extraction of annotations of services the pod belongs to.
+func WithExtractServiceAnnotations(annotations ...FieldExtractConfig) Option {
+	return func(p *kubernetesprocessor) error {
+		annotations, err := extractFieldRules("service_annotations", annotations...)
+		if err != nil {
+			return err
+		}
+		p.rules.ServiceAnnotations = annotations
+		return nil
+	}
+}
+
+// WithExtractTemplates allows specifying templates to compute attributes from other extracted metadata.
+func WithExtractTemplates(templates ...TemplateExtractConfig) Option {
+	return func(p *kubernetesprocessor) error {
+		rules := make([]kube.TemplateExtractionRule, 0, len(templates))
+		for _, t := range templates {
+			if t.TagName == "" {
+				return fmt.Errorf("tag_name must be set for template %q", t.Template)
+			}
+			if t.Template == "" {
+				return fmt.Errorf("template must be set for tag %q", t.TagName)
+			}
+			rules = append(rules, kube.TemplateExtractionRule{
+				Name: t.TagName, Template: t.Template,
+			})
+		}
+		p.rules.Templates = rules
+		return nil
+	}
+}
+
 func extractFieldRules(fieldType string, fields ...FieldExtractConfig) ([]kube.FieldExtractionRule, error) {
 	rules := []kube.FieldExtractionRule{}
 	for _, a := range fields {
@@ -296,27 +370,22 @@ func WithFilterNamespace(ns string) Option {
 	}
 }
 
+// WithFilterNamespaces allows specifying options to control filtering pods by a list of namespaces.
+func WithFilterNamespaces(namespaces ...string) Option {
+	return func(p *kubernetesprocessor) error {
+		p.filters.Namespaces = namespaces
+		return nil
+	}
+}
+
 // WithFilterLabels allows specifying options to control filtering pods by pod labels.
 func WithFilterLabels(filters ...FieldFilterConfig) Option {
 	return func(p *kubernetesprocessor) error {
 		labels := []kube.FieldFilter{}
 		for _, f := range filters {
-			if f.Op == "" {
-				f.Op = filterOPEquals
-			}
-
-			var op selection.Operator
-			switch f.Op {
-			case filterOPEquals:
-				op = selection.Equals
-			case filterOPNotEquals:
-				op = selection.NotEquals
-			case filterOPExists:
-				op = selection.Exists
-			case filterOPDoesNotExist:
-				op = selection.DoesNotExist
-			default:
-				return fmt.Errorf("'%s' is not a valid label filter operation for key=%s, value=%s", f.Op, f.Key, f.Value)
+			op, err := labelFilterOperator(f)
+			if err != nil {
+				return err
 			}
 			labels = append(labels, kube.FieldFilter{
 				Key:   f.Key,
@@ -329,6 +398,22 @@ func WithFilterLabels(filters ...FieldFilterConfig) Option {
 	}
 }
 
+// labelFilterOperator returns the selection operator of the label filter, defaulting to equals
+func labelFilterOperator(f FieldFilterConfig) (selection.Operator, error) {
+	switch f.Op {
+	case filterOPEquals, "":
+		return selection.Equals, nil
+	case filterOPNotEquals:
+		return selection.NotEquals, nil
+	case filterOPExists:
+		return selection.Exists, nil
+	case filterOPDoesNotExist:
+		return selection.DoesNotExist, nil
+	default:
+		return "", fmt.Errorf("'%s' is not a valid label filter operation for key=%s, value=%s", f.Op, f.Key, f.Value)
+	}
+}
+
 // WithFilterFields allows specifying options to control filtering pods by pod fields.
 func WithFilterFields(filters ...FieldFilterConfig) Option {
 	return func(p *kubernetesprocessor) error {
@@ -396,12 +481,91 @@ func WithExcludes(excludeConfig ExcludeConfig) Option {
 		names := excludeConfig.Pods
 
 		for _, name := range names {
-			excludes.Pods = append(excludes.Pods, kube.ExcludePods{
-				Name: regexp.MustCompile(name.Name)},
-			)
+			r, err := regexp.Compile(name.Name)
+			if err != nil {
+				return fmt.Errorf("invalid pod name exclusion %q: %w", name.Name, err)
+			}
+			excludes.Pods = append(excludes.Pods, kube.ExcludePods{Name: r})
+		}
+
+		for _, namespace := range excludeConfig.Namespaces {
+			r, err := regexp.Compile(namespace.Name)
+			if err != nil {
+				return fmt.Errorf("invalid namespace exclusion %q: %w", namespace.Name, err)
+			}
+			excludes.Namespaces = append(excludes.Namespaces, kube.ExcludeNamespaces{Name: r})
+		}
+
+		for _, f := range excludeConfig.Labels {
+			op, err := labelFilterOperator(f)
+			if err != nil {
+				return err
+			}
+			var values []string
+			if op == selection.Equals || op == selection.NotEquals {
+				values = []string{f.Value}
+			}
+			requirement, err := labels.NewRequirement(f.Key, op, values)
+			if err != nil {
+				return fmt.Errorf("invalid label exclusion for key=%s: %w", f.Key, err)
+			}
+			excludes.Labels = append(excludes.Labels, labels.NewSelector().Add(*requirement))
+		}
+
+		switch excludeConfig.Mode {
+		case excludeModeSkipEnrichment, "":
+			p.dropExcluded = false
+		case excludeModeDrop:
+			p.dropExcluded = true
+		default:
+			return fmt.Errorf("'%s' is not a valid exclusion mode", excludeConfig.Mode)
 		}
 
 		p.podIgnore = excludes
 		return nil
 	}
 }
+
+// WithDestination allows specifying attributes used to resolve destination pods and services
+func WithDestination(destination DestinationConfig) Option {
+	return func(p *kubernetesprocessor) error {
+		p.destinationAttributes = destination.Attributes
+		p.destinationPrefix = destination.Prefix
+		p.rules.ServiceIPLookup = len(destination.Attributes) > 0
+		return nil
+	}
+}
+
+// WithMetadataEvents allows emitting Pod metadata change events as logs
+func WithMetadataEvents(cfg MetadataEventsConfig) Option {
+	return func(p *kubernetesprocessor) error {
+		p.metadataEventsEnabled = cfg.Enabled
+		return nil
+	}
+}
+
+// WithDebugEndpoint allows exposing the processor cache over HTTP on the given endpoint
+func WithDebugEndpoint(endpoint string) Option {
+	return func(p *kubernetesprocessor) error {
+		p.debugEndpoint = endpoint
+		return nil
+	}
+}
+
+// WithWaitForMetadata makes the processor wait on start until the initial sync of caches completes
+func WithWaitForMetadata(timeout time.Duration) Option {
+	return func(p *kubernetesprocessor) error {
+		p.waitForMetadata = true
+		p.waitForMetadataTimeout = timeout
+		return nil
+	}
+}
+
+// WithStartupBuffer allows buffering data received before the initial sync of caches completes
+func WithStartupBuffer(maxItems int, timeout time.Duration) Option {
+	return func(p *kubernetesprocessor) error {
+		p.startupBuffer = newStartupBuffer(p.logger, maxItems)
+		p.startupBufferTimeout = timeout
+		return nil
+	}
+}
@@ -51,6 +51,12 @@ func TestWithFilterNamespace(t *testing.T) {
 	assert.Equal(t, p.filters.Namespace, "testns")
 }
 
+func TestWithFilterNamespaces(t *testing.T) {
+	p := &kubernetesprocessor{}
+	assert.NoError(t, WithFilterNamespaces("ns1", "ns2")(p))
+	assert.Equal(t, []string{"ns1", "ns2"}, p.filters.Namespaces)
+}
+
 func TestWithFilterNode(t *testing.T) {
 	p := &kubernetesprocessor{}
 	assert.NoError(t, WithFilterNode("testnode", "")(p))
@@ -324,6 +330,73 @@ func TestWithExtractNamespaceLabels(t *testing.T) {
 	}
 }
 
+func TestWithExtractTemplates(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []TemplateExtractConfig
+		want      []kube.TemplateExtractionRule
+		wantError string
+	}{
+		{
+			"empty",
+			[]TemplateExtractConfig{},
+			[]kube.TemplateExtractionRule{},
+			"",
+		},
+		{
+			"missing tag name",
+			[]TemplateExtractConfig{
+				{
+					Template: "%{k8s.pod.name}",
+				},
+			},
+			nil,
+			`tag_name must be set for template "%{k8s.pod.name}"`,
+		},
+		{
+			"missing template",
+			[]TemplateExtractConfig{
+				{
+					TagName: "tag1",
+				},
+			},
+			nil,
+			`template must be set for tag "tag1"`,
+		},
+		{
+			"basic",
+			[]TemplateExtractConfig{
+				{
+					TagName:  "team",
+					Template: "%{k8s.namespace.label.team}/%{k8s.deployment.name}",
+				},
+			},
+			[]kube.TemplateExtractionRule{
+				{
+					Name:     "team",
+					Template: "%{k8s.namespace.label.team}/%{k8s.deployment.name}",
+				},
+			},
+			"",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &kubernetesprocessor{}
+			option := WithExtractTemplates(tt.args...)
+			err := option(p)
+			if tt.wantError != "" {
+				assert.Error(t, err)
+				assert.Equal(t, err.Error(), tt.wantError)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, p.rules.Templates)
+		})
+	}
+}
+
 func TestWithExtractMetadata(t *testing.T) {
 	p := &kubernetesprocessor{}
 	assert.NoError(t, WithExtractMetadata()(p))
@@ -348,6 +421,23 @@ func TestWithExtractMetadata(t *testing.T) {
 	assert.False(t, p.rules.NodeName)
 }
 
+func TestWithExtractMetadataService(t *testing.T) {
+	p := &kubernetesprocessor{}
+	assert.NoError(t, WithExtractMetadata()(p))
+	assert.False(t, p.rules.ServiceType)
+	assert.False(t, p.rules.ServiceClusterIP)
+	assert.False(t, p.rules.ServicePorts)
+	assert.False(t, p.rules.IngressHost)
+
+	p = &kubernetesprocessor{}
+	assert.NoError(t, WithExtractMetadata("serviceType", "k8s.service.cluster_ip", "servicePorts", "k8s.ingress.host")(p))
+	assert.True(t, p.rules.ServiceType)
+	assert.True(t, p.rules.ServiceClusterIP)
+	assert.True(t, p.rules.ServicePorts)
+	assert.True(t, p.rules.IngressHost)
+	assert.False(t, p.rules.ServiceName)
+}
+
 func TestWithExtractMetadataSemanticConventions(t *testing.T) {
 	p := &kubernetesprocessor{}
 	fields := []string{
@@ -808,6 +898,19 @@ func TestWithExcludes(t *testing.T) {
 				},
 			},
 		},
+		{
+			"namespaces",
+			ExcludeConfig{
+				Namespaces: []ExcludeNamespaceConfig{
+					{Name: "^kube-system$"},
+				},
+			},
+			kube.Excludes{
+				Namespaces: []kube.ExcludeNamespaces{
+					{Name: regexp.MustCompile(`^kube-system$`)},
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -815,10 +918,48 @@ func TestWithExcludes(t *testing.T) {
 			option := WithExcludes(tt.args)
 			require.NoError(t, option(p))
 			assert.Equal(t, tt.want, p.podIgnore)
+			assert.False(t, p.dropExcluded)
 		})
 	}
 }
 
+func TestWithExcludesLabels(t *testing.T) {
+	p := &kubernetesprocessor{}
+	err := WithExcludes(ExcludeConfig{
+		Labels: []FieldFilterConfig{
+			{Key: "app", Value: "jaeger"},
+			{Key: "team", Value: "infra", Op: "not-equals"},
+			{Key: "sumologic.com/exclude", Op: "exists"},
+		},
+	})(p)
+	require.NoError(t, err)
+	require.Len(t, p.podIgnore.Labels, 3)
+	assert.Equal(t, "app=jaeger", p.podIgnore.Labels[0].String())
+	assert.Equal(t, "team!=infra", p.podIgnore.Labels[1].String())
+	assert.Equal(t, "sumologic.com/exclude", p.podIgnore.Labels[2].String())
+}
+
+func TestWithExcludesMode(t *testing.T) {
+	p := &kubernetesprocessor{}
+	require.NoError(t, WithExcludes(ExcludeConfig{Mode: "drop"})(p))
+	assert.True(t, p.dropExcluded)
+
+	p = &kubernetesprocessor{}
+	require.NoError(t, WithExcludes(ExcludeConfig{Mode: "skip_enrichment"})(p))
+	assert.False(t, p.dropExcluded)
+
+	p = &kubernetesprocessor{}
+	err := WithExcludes(ExcludeConfig{Mode: "unknown"})(p)
+	assert.EqualError(t, err, "'unknown' is not a valid exclusion mode")
+}
+
+func TestWithExcludesErrors(t *testing.T) {
+	p := &kubernetesprocessor{}
+	assert.Error(t, WithExcludes(ExcludeConfig{Pods: []ExcludePodConfig{{Name: "("}}})(p))
+	assert.Error(t, WithExcludes(ExcludeConfig{Namespaces: []ExcludeNamespaceConfig{{Name: "("}}})(p))
+	assert.Error(t, WithExcludes(ExcludeConfig{Labels: []FieldFilterConfig{{Key: "app", Op: "unknown"}}})(p))
+}
+
 func TestExtractTags(t *testing.T) {
 	p := &kubernetesprocessor{}
 	tags := map[string]string{
@@ -832,6 +973,7 @@ func TestExtractTags(t *testing.T) {
 	assert.Equal(t, "the id", p.rules.Tags.PodUID)
 	assert.Equal(t, "host", p.rules.Tags.HostName)
 	assert.Equal(t, "blep", p.rules.Tags.ServiceName)
+	assert.Equal(t, "k8s.service.type", p.rules.Tags.ServiceType)
 
 	p = &kubernetesprocessor{}
 	err := WithExtractTags(map[string]string{"randomfield": "randomvalue"})(p)
@@ -16,13 +16,16 @@ package k8sprocessor
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/plog"
 	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/processor/processorhelper"
 	"go.uber.org/zap"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/k8sconfig"
@@ -43,8 +46,28 @@ type kubernetesprocessor struct {
 	filters         kube.Filters
 	podAssociations []kube.Association
 	podIgnore       kube.Excludes
+	dropExcluded    bool
 	delimiter       string
 	limit           int
+
+	destinationAttributes []string
+	destinationPrefix     string
+
+	metadataEventsEnabled bool
+	metadataEvents        *metadataEventsEmitter
+
+	debugEndpoint string
+	debugName     string
+
+	waitForMetadata        bool
+	waitForMetadataTimeout time.Duration
+	startupBuffer          *startupBuffer
+	startupBufferTimeout   time.Duration
+	stopCh                 chan struct{}
+
+	nextTraces  consumer.Traces
+	nextMetrics consumer.Metrics
+	nextLogs    consumer.Logs
 }
 
 func (kp *kubernetesprocessor) initKubeClient(logger *zap.Logger, kubeClient kube.ClientProvider) error {
@@ -75,52 +98,167 @@ func (kp *kubernetesprocessor) initKubeClient(logger *zap.Logger, kubeClient kub
 	return nil
 }
 
+// enableMetadataEvents makes the processor send Pod metadata change events to the given consumer.
+func (kp *kubernetesprocessor) enableMetadataEvents(next consumer.Logs) {
+	if !kp.metadataEventsEnabled || kp.passthroughMode {
+		return
+	}
+	kp.metadataEvents = newMetadataEventsEmitter(kp.logger, kp.rules.Tags, next)
+	kp.kc.SetPodEventHandler(kp.metadataEvents.handle)
+}
+
 func (kp *kubernetesprocessor) Start(_ context.Context, _ component.Host) error {
-	if !kp.passthroughMode {
-		go kp.kc.Start()
+	if kp.metadataEvents != nil {
+		kp.metadataEvents.start()
+	}
+	if kp.passthroughMode {
+		return nil
+	}
+
+	kp.stopCh = make(chan struct{})
+	go kp.kc.Start()
+	if kp.debugEnabled() {
+		if err := registerDebugEndpoint(kp.debugEndpoint, kp); err != nil {
+			return err
+		}
+	}
+
+	if kp.waitForMetadata {
+		synced, err := waitForSync(kp.kc, kp.waitForMetadataTimeout, kp.stopCh)
+		if err != nil {
+			return fmt.Errorf("kubernetes metadata not available: %w", err)
+		}
+		if !synced {
+			return fmt.Errorf("kubernetes metadata not synced within %s", kp.waitForMetadataTimeout)
+		}
+		kp.logger.Info("Kubernetes metadata synced")
+	}
+
+	if kp.startupBuffer != nil {
+		go func() {
+			synced, err := waitForSync(kp.kc, kp.startupBufferTimeout, kp.stopCh)
+			if err != nil {
+				kp.logger.Warn("Kubernetes metadata not available, releasing startup buffer", zap.Error(err))
+			} else if !synced {
+				kp.logger.Warn("Kubernetes metadata not synced, releasing startup buffer", zap.Duration("timeout", kp.startupBufferTimeout))
+			}
+			kp.startupBuffer.release()
+		}()
 	}
 	return nil
 }
 
 func (kp *kubernetesprocessor) Shutdown(context.Context) error {
+	var err error
+	if kp.debugEnabled() {
+		err = unregisterDebugEndpoint(kp.debugEndpoint, kp)
+	}
 	if !kp.passthroughMode {
+		if kp.stopCh != nil {
+			close(kp.stopCh)
+		}
+		// send the data which has been buffered so far, so that it's not lost
+		if kp.startupBuffer != nil {
+			kp.startupBuffer.release()
+		}
 		kp.kc.Stop()
 	}
-	return nil
+	if kp.metadataEvents != nil {
+		kp.metadataEvents.stop()
+	}
+	return err
+}
+
+func (kp *kubernetesprocessor) debugEnabled() bool {
+	return !kp.passthroughMode && kp.debugEndpoint != ""
 }
 
 // ProcessTraces process traces and add k8s metadata using resource IP or incoming IP as pod origin.
 func (kp *kubernetesprocessor) ProcessTraces(ctx context.Context, td ptrace.Traces) (ptrace.Traces, error) {
+	if !kp.passthroughMode && kp.startupBuffer.add(ctx, td.SpanCount(), func(ctx context.Context) error {
+		return kp.nextTraces.ConsumeTraces(ctx, kp.processTraces(ctx, td))
+	}) {
+		return td, processorhelper.ErrSkipProcessingData
+	}
+	return kp.processTraces(ctx, td), nil
+}
+
+func (kp *kubernetesprocessor) processTraces(ctx context.Context, td ptrace.Traces) ptrace.Traces {
 	rss := td.ResourceSpans()
-	for i := 0; i < rss.Len(); i++ {
-		kp.processResource(ctx, rss.At(i).Resource())
+	rss.RemoveIf(func(rs ptrace.ResourceSpans) bool {
+		return kp.processResource(ctx, rs.Resource())
+	})
+
+	if kp.destinationEnabled() {
+		destinations := map[string]map[string]string{}
+		for i := 0; i < rss.Len(); i++ {
+			sss := rss.At(i).ScopeSpans()
+			for j := 0; j < sss.Len(); j++ {
+				spans := sss.At(j).Spans()
+				for k := 0; k < spans.Len(); k++ {
+					kp.processDestination(spans.At(k).Attributes(), destinations)
+				}
+			}
+		}
 	}
 
-	return td, nil
+	return td
 }
 
 // ProcessMetrics process metrics and add k8s metadata using resource IP, hostname or incoming IP as pod origin.
 func (kp *kubernetesprocessor) ProcessMetrics(ctx context.Context, md pmetric.Metrics) (pmetric.Metrics, error) {
-	rm := md.ResourceMetrics()
-	for i := 0; i < rm.Len(); i++ {
-		kp.processResource(ctx, rm.At(i).Resource())
+	if !kp.passthroughMode && kp.startupBuffer.add(ctx, md.DataPointCount(), func(ctx context.Context) error {
+		return kp.nextMetrics.ConsumeMetrics(ctx, kp.processMetrics(ctx, md))
+	}) {
+		return md, processorhelper.ErrSkipProcessingData
 	}
+	return kp.processMetrics(ctx, md), nil
+}
+
+func (kp *kubernetesprocessor) processMetrics(ctx context.Context, md pmetric.Metrics) pmetric.Metrics {
+	rm := md.ResourceMetrics()
+	rm.RemoveIf(func(rm pmetric.ResourceMetrics) bool {
+		return kp.processResource(ctx, rm.Resource())
+	})
 
-	return md, nil
+	return md
 }
 
 // ProcessLogs process logs and add k8s metadata using resource IP, hostname or incoming IP as pod origin.
 func (kp *kubernetesprocessor) ProcessLogs(ctx context.Context, ld plog.Logs) (plog.Logs, error) {
+	if !kp.passthroughMode && kp.startupBuffer.add(ctx, ld.LogRecordCount(), func(ctx context.Context) error {
+		return kp.nextLogs.ConsumeLogs(ctx, kp.processLogs(ctx, ld))
+	}) {
+		return ld, processorhelper.ErrSkipProcessingData
+	}
+	return kp.processLogs(ctx, ld), nil
+}
+
+func (kp *kubernetesprocessor) processLogs(ctx context.Context, ld plog.Logs) plog.Logs {
 	rl := ld.ResourceLogs()
-	for i := 0; i < rl.Len(); i++ {
-		kp.processResource(ctx, rl.At(i).Resource())
+	rl.RemoveIf(func(rl plog.ResourceLogs) bool {
+		return kp.processResource(ctx, rl.Resource())
+	})
+
+	if kp.destinationEnabled() {
+		destinations := map[string]map[string]string{}
+		for i := 0; i < rl.Len(); i++ {
+			sls := rl.At(i).ScopeLogs()
+			for j := 0; j < sls.Len(); j++ {
+				logs := sls.At(j).LogRecords()
+				for k := 0; k < logs.Len(); k++ {
+					kp.processDestination(logs.At(k).Attributes(), destinations)
+				}
+			}
+		}
 	}
 
-	return ld, nil
+	return ld
 }
 
-// processResource adds Pod metadata tags to resource based on pod association configuration
-func (kp *kubernetesprocessor) processResource(ctx context.Context, resource pcommon.Resource) {
+// processResource adds Pod metadata tags to resource based on pod association configuration.
+// It returns true if the resource belongs to an excluded pod and should be dropped.
+func (kp *kubernetesprocessor) processResource(ctx context.Context, resource pcommon.Resource) bool {
 	podIdentifierKey, podIdentifierValue, err := extractPodID(ctx, resource.Attributes(), kp.podAssociations)
 	if err != nil {
 		kp.logger.Debug(
@@ -128,7 +266,7 @@ func (kp *kubernetesprocessor) processResource(ctx context.Context, resource pco
 			zap.Error(err),
 			zap.Any("resource_attributes", resource.Attributes()),
 		)
-		return
+		return false
 	}
 
 	if podIdentifierKey != "" {
@@ -136,12 +274,57 @@ func (kp *kubernetesprocessor) processResource(ctx context.Context, resource pco
 	}
 
 	if kp.passthroughMode {
-		return
+		return false
+	}
+	if kp.dropExcluded && kp.kc.IsPodExcluded(podIdentifierValue) {
+		return true
 	}
 	attrsToAdd := kp.getAttributesForPod(podIdentifierValue)
 	for key, val := range attrsToAdd {
 		resource.Attributes().PutStr(key, val)
 	}
+	return false
+}
+
+func (kp *kubernetesprocessor) destinationEnabled() bool {
+	return !kp.passthroughMode && len(kp.destinationAttributes) > 0
+}
+
+// processDestination adds metadata of the destination pod or service to span or log record attributes,
+// based on the first configured destination attribute which can be resolved. Resolved destinations
+// are stored in the provided map, so that they're looked up only once per batch.
+func (kp *kubernetesprocessor) processDestination(attrs pcommon.Map, destinations map[string]map[string]string) {
+	for _, name := range kp.destinationAttributes {
+		ip := stringAttributeFromMap(attrs, name)
+		if ip == "" {
+			continue
+		}
+
+		attrsToAdd, ok := destinations[ip]
+		if !ok {
+			attrsToAdd = kp.getAttributesForDestination(ip)
+			destinations[ip] = attrsToAdd
+		}
+		if attrsToAdd == nil {
+			continue
+		}
+
+		for key, val := range attrsToAdd {
+			attrs.PutStr(kp.destinationPrefix+key, val)
+		}
+		return
+	}
+}
+
+func (kp *kubernetesprocessor) getAttributesForDestination(ip string) map[string]string {
+	if attributes, ok := kp.kc.GetPodAttributes(kube.PodIdentifier(ip)); ok {
+		return attributes
+	}
+	if attributes, ok := kp.kc.GetServiceAttributes(ip); ok {
+		return attributes
+	}
+	kp.logger.Debug("No pod or service with given destination IP found", zap.String("ip", ip))
+	return nil
 }
 
 func (kp *kubernetesprocessor) getAttributesForPod(identifier kube.PodIdentifier) map[string]string {
@@ -729,6 +729,143 @@ func TestProcessorAddLabels(t *testing.T) {
 	}
 }
 
+func TestProcessorDestination(t *testing.T) {
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	cfg.Destination.Attributes = []string{"net.peer.ip", "destination.ip"}
+	m := newMultiTest(t, cfg, nil)
+
+	m.kubernetesProcessorOperation(func(kp *kubernetesprocessor) {
+		kp.kc.(*fakeClient).Pods["1.1.1.1"] = &kube.Pod{Attributes: map[string]string{"k8s.pod.name": "source"}}
+		kp.kc.(*fakeClient).Pods["2.2.2.2"] = &kube.Pod{Attributes: map[string]string{"k8s.pod.name": "destination"}}
+		kp.kc.(*fakeClient).Services["10.0.0.1"] = map[string]string{"k8s.service.name": "my-service"}
+	})
+
+	tests := []struct {
+		name     string
+		attrs    map[string]string
+		expected map[string]string
+	}{
+		{
+			name:  "pod",
+			attrs: map[string]string{"net.peer.ip": "2.2.2.2"},
+			expected: map[string]string{
+				"net.peer.ip":              "2.2.2.2",
+				"destination.k8s.pod.name": "destination",
+			},
+		},
+		{
+			name:  "service",
+			attrs: map[string]string{"destination.ip": "10.0.0.1"},
+			expected: map[string]string{
+				"destination.ip":               "10.0.0.1",
+				"destination.k8s.service.name": "my-service",
+			},
+		},
+		{
+			name:  "first resolved attribute is used",
+			attrs: map[string]string{"net.peer.ip": "3.3.3.3", "destination.ip": "2.2.2.2"},
+			expected: map[string]string{
+				"net.peer.ip":              "3.3.3.3",
+				"destination.ip":           "2.2.2.2",
+				"destination.k8s.pod.name": "destination",
+			},
+		},
+		{
+			name:     "unknown",
+			attrs:    map[string]string{"net.peer.ip": "3.3.3.3"},
+			expected: map[string]string{"net.peer.ip": "3.3.3.3"},
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			traces := generateTraces(withPassthroughIP("1.1.1.1"))
+			span := traces.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0)
+			logs := generateLogs(withPassthroughIP("1.1.1.1"))
+			record := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+			for k, v := range tt.attrs {
+				span.Attributes().PutStr(k, v)
+				record.Attributes().PutStr(k, v)
+			}
+
+			m.testConsume(
+				context.Background(),
+				traces,
+				generateMetrics(withPassthroughIP("1.1.1.1")),
+				logs,
+				func(err error) {
+					assert.NoError(t, err)
+				})
+
+			m.assertBatchesLen(i + 1)
+			m.assertResource(i, func(res pcommon.Resource) {
+				assertResourceHasStringAttribute(t, res, "k8s.pod.name", "source")
+			})
+
+			gotSpan := m.nextTrace.AllTraces()[i].ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0)
+			gotRecord := m.nextLogs.AllLogs()[i].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+			for _, attrs := range []pcommon.Map{gotSpan.Attributes(), gotRecord.Attributes()} {
+				assert.Equal(t, len(tt.expected), attrs.Len())
+				for k, v := range tt.expected {
+					got, ok := attrs.Get(k)
+					if assert.True(t, ok, "Attribute '%s' not found.", k) {
+						assert.Equal(t, v, got.Str())
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestProcessorDropExcluded(t *testing.T) {
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	cfg.Exclude.Mode = "drop"
+	m := newMultiTest(t, cfg, nil)
+
+	m.kubernetesProcessorOperation(func(kp *kubernetesprocessor) {
+		kp.kc.(*fakeClient).Pods["1.1.1.1"] = &kube.Pod{Attributes: map[string]string{"k8s.pod.name": "kept"}}
+		kp.kc.(*fakeClient).Pods["2.2.2.2"] = &kube.Pod{Ignore: true, Excluded: true}
+	})
+
+	traces := generateTraces(withPassthroughIP("1.1.1.1"))
+	metrics := generateMetrics(withPassthroughIP("1.1.1.1"))
+	logs := generateLogs(withPassthroughIP("1.1.1.1"))
+	generateTraces(withPassthroughIP("2.2.2.2")).ResourceSpans().MoveAndAppendTo(traces.ResourceSpans())
+	generateMetrics(withPassthroughIP("2.2.2.2")).ResourceMetrics().MoveAndAppendTo(metrics.ResourceMetrics())
+	generateLogs(withPassthroughIP("2.2.2.2")).ResourceLogs().MoveAndAppendTo(logs.ResourceLogs())
+
+	m.testConsume(context.Background(), traces, metrics, logs, func(err error) {
+		assert.NoError(t, err)
+	})
+
+	m.assertBatchesLen(1)
+	m.assertResourceObjectLen(0)
+	m.assertResource(0, func(res pcommon.Resource) {
+		assertResourceHasStringAttribute(t, res, "k8s.pod.name", "kept")
+	})
+}
+
+func TestProcessorSkipEnrichmentExcluded(t *testing.T) {
+	m := newMultiTest(t, NewFactory().CreateDefaultConfig(), nil)
+
+	m.kubernetesProcessorOperation(func(kp *kubernetesprocessor) {
+		kp.kc.(*fakeClient).Pods["2.2.2.2"] = &kube.Pod{Ignore: true, Excluded: true}
+	})
+
+	m.testConsume(
+		context.Background(),
+		generateTraces(withPassthroughIP("2.2.2.2")),
+		generateMetrics(withPassthroughIP("2.2.2.2")),
+		generateLogs(withPassthroughIP("2.2.2.2")),
+		func(err error) {
+			assert.NoError(t, err)
+		})
+
+	m.assertBatchesLen(1)
+	m.assertResourceObjectLen(0)
+	m.assertResourceAttributesLen(0, 1)
+}
+
 func TestProcessorPicksUpPassthoughPodIp(t *testing.T) {
 	m := newMultiTest(
 		t,
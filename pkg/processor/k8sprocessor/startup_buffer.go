@@ -0,0 +1,119 @@
+// Copyright 2020 OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sprocessor
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/k8sprocessor/kube"
+)
+
+// syncCheckInterval is how often the sync status of caches is checked while waiting for it
+const syncCheckInterval = 100 * time.Millisecond
+
+// startupBuffer holds data received before the initial sync of caches completes.
+type startupBuffer struct {
+	logger   *zap.Logger
+	maxItems int
+
+	mu       sync.Mutex
+	items    int
+	released bool
+	pending  []bufferedData
+}
+
+type bufferedData struct {
+	ctx  context.Context
+	send func(ctx context.Context) error
+}
+
+func newStartupBuffer(logger *zap.Logger, maxItems int) *startupBuffer {
+	return &startupBuffer{
+		logger:   logger,
+		maxItems: maxItems,
+	}
+}
+
+// add buffers the data until the buffer is released, at which point send is called.
+// It returns false if the data should be processed immediately instead, because
+// the buffer is nil, has already been released or is full.
+func (b *startupBuffer) add(ctx context.Context, items int, send func(ctx context.Context) error) bool {
+	if b == nil {
+		return false
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.released || b.items+items > b.maxItems {
+		return false
+	}
+	b.items += items
+	// the data is sent after the caller returns, so its cancellation must not be propagated
+	b.pending = append(b.pending, bufferedData{ctx: context.WithoutCancel(ctx), send: send})
+	return true
+}
+
+// release sends all the buffered data and makes the buffer pass the data through from now on.
+// The lock is held while sending, so that the data received in the meantime is sent after the buffered data.
+func (b *startupBuffer) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.released {
+		return
+	}
+	b.released = true
+
+	for _, data := range b.pending {
+		if err := data.send(data.ctx); err != nil {
+			b.logger.Error("Failed to send buffered data", zap.Error(err))
+		}
+	}
+	b.logger.Debug("Released startup buffer", zap.Int("batches", len(b.pending)), zap.Int("items", b.items))
+	b.pending = nil
+	b.items = 0
+}
+
+// waitForSync blocks until the cache of the client is synced, the timeout passes or done is closed.
+// It returns true if the cache is synced, or an error if the cache can't be synced at all.
+func waitForSync(kc kube.Client, timeout time.Duration, done <-chan struct{}) (bool, error) {
+	if kc.HasSynced() {
+		return true, nil
+	}
+
+	ticker := time.NewTicker(syncCheckInterval)
+	defer ticker.Stop()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if kc.HasSynced() {
+				return true, nil
+			}
+			if err := kc.SyncError(); err != nil {
+				return false, err
+			}
+		case <-timer.C:
+			return kc.HasSynced(), kc.SyncError()
+		case <-done:
+			return false, nil
+		}
+	}
+}
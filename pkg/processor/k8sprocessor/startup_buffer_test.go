@@ -0,0 +1,173 @@
+// Copyright 2020 OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sprocessor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/k8sprocessor/kube"
+)
+
+func TestStartupBuffer(t *testing.T) {
+	b := newStartupBuffer(zap.NewNop(), 10)
+	var sent []int
+	send := func(i int) func(context.Context) error {
+		return func(context.Context) error {
+			sent = append(sent, i)
+			return nil
+		}
+	}
+
+	assert.True(t, b.add(context.Background(), 5, send(1)))
+	assert.True(t, b.add(context.Background(), 5, send(2)))
+	// buffer is full
+	assert.False(t, b.add(context.Background(), 1, send(3)))
+	assert.Empty(t, sent)
+
+	b.release()
+	assert.Equal(t, []int{1, 2}, sent)
+
+	// buffer is released
+	assert.False(t, b.add(context.Background(), 1, send(4)))
+	b.release()
+	assert.Equal(t, []int{1, 2}, sent)
+
+	var nilBuffer *startupBuffer
+	assert.False(t, nilBuffer.add(context.Background(), 1, send(5)))
+}
+
+func TestStartupBufferContextNotCancelled(t *testing.T) {
+	b := newStartupBuffer(zap.NewNop(), 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	var sendErr error
+	require.True(t, b.add(ctx, 1, func(ctx context.Context) error {
+		sendErr = ctx.Err()
+		return nil
+	}))
+	cancel()
+	b.release()
+	assert.NoError(t, sendErr)
+}
+
+func TestWaitForSync(t *testing.T) {
+	kc := &fakeClient{}
+	kc.Synced.Store(true)
+	synced, err := waitForSync(kc, time.Second, nil)
+	assert.NoError(t, err)
+	assert.True(t, synced)
+
+	kc = &fakeClient{}
+	synced, err = waitForSync(kc, 2*syncCheckInterval, nil)
+	assert.NoError(t, err)
+	assert.False(t, synced)
+
+	done := make(chan struct{})
+	close(done)
+	synced, err = waitForSync(kc, time.Minute, done)
+	assert.NoError(t, err)
+	assert.False(t, synced)
+
+	// waiting stops as soon as the client can't be synced
+	kc.SyncErr = errors.New("missing permissions to list and watch pods")
+	synced, err = waitForSync(kc, time.Minute, nil)
+	assert.EqualError(t, err, "missing permissions to list and watch pods")
+	assert.False(t, synced)
+}
+
+func TestProcessorWaitForMetadata(t *testing.T) {
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	cfg.WaitForMetadata = true
+	cfg.WaitForMetadataTimeout = 2 * syncCheckInterval
+	m := newMultiTest(t, cfg, nil)
+
+	err := m.tp.Start(context.Background(), componenttest.NewNopHost())
+	assert.EqualError(t, err, "kubernetes metadata not synced within 200ms")
+	require.NoError(t, m.tp.Shutdown(context.Background()))
+
+	m.kpMetrics.kc.(*fakeClient).Synced.Store(true)
+	require.NoError(t, m.mp.Start(context.Background(), componenttest.NewNopHost()))
+	require.NoError(t, m.mp.Shutdown(context.Background()))
+
+	m.kpLogs.kc.(*fakeClient).SyncErr = errors.New("missing permissions to list and watch pods")
+	err = m.lp.Start(context.Background(), componenttest.NewNopHost())
+	assert.EqualError(t, err, "kubernetes metadata not available: missing permissions to list and watch pods")
+	require.NoError(t, m.lp.Shutdown(context.Background()))
+}
+
+func TestProcessorStartupBuffer(t *testing.T) {
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	cfg.StartupBuffer.Enabled = true
+	m := newMultiTest(t, cfg, nil)
+
+	ctx := context.Background()
+	host := componenttest.NewNopHost()
+	m.kubernetesProcessorOperation(func(kp *kubernetesprocessor) {
+		kp.kc.(*fakeClient).Pods["1.1.1.1"] = &kube.Pod{Attributes: map[string]string{"k8s.pod.name": "my-pod"}}
+	})
+	require.NoError(t, m.tp.Start(ctx, host))
+	require.NoError(t, m.mp.Start(ctx, host))
+	require.NoError(t, m.lp.Start(ctx, host))
+
+	m.testConsume(
+		ctx,
+		generateTraces(withPassthroughIP("1.1.1.1")),
+		generateMetrics(withPassthroughIP("1.1.1.1")),
+		generateLogs(withPassthroughIP("1.1.1.1")),
+		func(err error) {
+			assert.NoError(t, err)
+		})
+	// the data is buffered until the caches are synced
+	m.assertBatchesLen(0)
+
+	m.kubernetesProcessorOperation(func(kp *kubernetesprocessor) {
+		kp.kc.(*fakeClient).Synced.Store(true)
+	})
+
+	assert.Eventually(t, func() bool {
+		return len(m.nextTrace.AllTraces()) == 1 &&
+			len(m.nextMetrics.AllMetrics()) == 1 &&
+			len(m.nextLogs.AllLogs()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	m.assertResource(0, func(res pcommon.Resource) {
+		assertResourceHasStringAttribute(t, res, "k8s.pod.name", "my-pod")
+	})
+
+	require.NoError(t, m.tp.Shutdown(ctx))
+	require.NoError(t, m.mp.Shutdown(ctx))
+	require.NoError(t, m.lp.Shutdown(ctx))
+}
+
+func TestProcessorStartupBufferReleasedOnShutdown(t *testing.T) {
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	cfg.StartupBuffer.Enabled = true
+	m := newMultiTest(t, cfg, nil)
+
+	ctx := context.Background()
+	require.NoError(t, m.lp.Start(ctx, componenttest.NewNopHost()))
+	require.NoError(t, m.lp.ConsumeLogs(ctx, generateLogs(withPassthroughIP("1.1.1.1"))))
+	assert.Empty(t, m.nextLogs.AllLogs())
+
+	require.NoError(t, m.lp.Shutdown(ctx))
+	assert.Len(t, m.nextLogs.AllLogs(), 1)
+}
@@ -34,22 +34,30 @@ func (a *Action) UnmarshalText(text []byte) error {
 }
 
 // ContextID specifies where to apply the lookup.
-// Matches the semantic used by geoipprocessor.
+// Matches the semantic used by geoipprocessor, with signal specific contexts
+// named after the corresponding OTTL contexts.
 type ContextID string
 
 const (
+	// ContextRecord applies the lookup to log records, spans and metric data points.
 	ContextRecord   ContextID = "record"
 	ContextResource ContextID = "resource"
+	// ContextSpan applies the lookup to spans only.
+	ContextSpan ContextID = "span"
+	// ContextSpanEvent applies the lookup to span events.
+	ContextSpanEvent ContextID = "spanevent"
+	// ContextDataPoint applies the lookup to metric data points only.
+	ContextDataPoint ContextID = "datapoint"
 )
 
 func (c *ContextID) UnmarshalText(text []byte) error {
 	str := ContextID(strings.ToLower(string(text)))
 	switch str {
-	case ContextRecord, ContextResource:
+	case ContextRecord, ContextResource, ContextSpan, ContextSpanEvent, ContextDataPoint:
 		*c = str
 		return nil
 	default:
-		return fmt.Errorf("invalid context %q, must be one of: record, resource", str)
+		return fmt.Errorf("invalid context %q, must be one of: record, resource, span, spanevent, datapoint", str)
 	}
 }
 
@@ -80,7 +88,7 @@ type SourceConfig struct {
 // AttributeConfig defines a single attribute enrichment rule.
 type AttributeConfig struct {
 	// Key is the name of the attribute to set with the lookup result.
-	// Required.
+	// Required, unless Columns are set.
 	Key string `mapstructure:"key"`
 
 	// FromAttribute is the name of the attribute containing the lookup key.
@@ -98,12 +106,35 @@ type AttributeConfig struct {
 	// Default: "upsert"
 	Action Action `mapstructure:"action"`
 
+	// Columns maps fields of a structured lookup result, e.g. a CSV row, to attributes,
+	// so that a single lookup sets multiple attributes. Mutually exclusive with Key and Default.
+	// Optional.
+	Columns []ColumnConfig `mapstructure:"columns"`
+
 	// Context specifies where to read the source attribute and write the result.
-	// Valid values: "record" (log record attributes), "resource" (resource attributes).
+	// Valid values: "record" (log record, span or metric data point attributes), "resource" (resource attributes),
+	// "span" (span attributes), "spanevent" (span event attributes), "datapoint" (metric data point attributes).
+	// Rules with a context not applicable to a signal are ignored for that signal.
 	// Default: "record"
 	Context ContextID `mapstructure:"context"`
 }
 
+// ColumnConfig maps a single field of a structured lookup result to an attribute.
+type ColumnConfig struct {
+	// Column is the name of the lookup result field.
+	// Required.
+	Column string `mapstructure:"column"`
+
+	// Key is the name of the attribute to set with the field value.
+	// Required.
+	Key string `mapstructure:"key"`
+
+	// Default is the value to use when the lookup returns no result or the result has no such field.
+	// If not set, no attribute is added in that case.
+	// Optional.
+	Default string `mapstructure:"default"`
+}
+
 var _ component.Config = (*Config)(nil)
 
 func (cfg *Config) Validate() error {
@@ -112,12 +143,24 @@ func (cfg *Config) Validate() error {
 	}
 
 	for i, attr := range cfg.Attributes {
-		if attr.Key == "" {
+		if len(attr.Columns) > 0 {
+			if attr.Key != "" || attr.Default != "" {
+				return fmt.Errorf("attributes[%d]: key and default cannot be used with columns", i)
+			}
+		} else if attr.Key == "" {
 			return fmt.Errorf("attributes[%d]: key is required", i)
 		}
 		if attr.FromAttribute == "" {
 			return fmt.Errorf("attributes[%d]: from_attribute is required", i)
 		}
+		for j, column := range attr.Columns {
+			if column.Column == "" {
+				return fmt.Errorf("attributes[%d].columns[%d]: column is required", i, j)
+			}
+			if column.Key == "" {
+				return fmt.Errorf("attributes[%d].columns[%d]: key is required", i, j)
+			}
+		}
 	}
 
 	return nil
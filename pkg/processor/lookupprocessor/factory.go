@@ -15,6 +15,7 @@ import (
 	"go.opentelemetry.io/collector/processor/processorhelper"
 
 	"github.com/SumoLogic/sumologic-otel-collector/pkg/processor/lookupprocessor/internal/metadata"
+	csvsource "github.com/SumoLogic/sumologic-otel-collector/pkg/processor/lookupprocessor/internal/source/csv"
 	"github.com/SumoLogic/sumologic-otel-collector/pkg/processor/lookupprocessor/internal/source/dns"
 	"github.com/SumoLogic/sumologic-otel-collector/pkg/processor/lookupprocessor/internal/source/noop"
 	yamlsource "github.com/SumoLogic/sumologic-otel-collector/pkg/processor/lookupprocessor/internal/source/yaml"
@@ -58,6 +59,7 @@ func defaultSources() map[string]lookupsource.SourceFactory {
 		"noop": noop.NewFactory(),
 		"yaml": yamlsource.NewFactory(),
 		"dns":  dns.NewFactory(),
+		"csv":  csvsource.NewFactory(),
 	}
 }
 
@@ -89,6 +91,8 @@ func NewFactoryWithOptions(options ...FactoryOption) processor.Factory {
 		metadata.Type,
 		f.createDefaultConfig,
 		processor.WithLogs(f.createLogsProcessor, metadata.LogsStability),
+		processor.WithTraces(f.createTracesProcessor, metadata.TracesStability),
+		processor.WithMetrics(f.createMetricsProcessor, metadata.MetricsStability),
 	)
 }
 
@@ -133,6 +137,60 @@ func (f *lookupProcessorFactory) createLogsProcessor(
 	)
 }
 
+func (f *lookupProcessorFactory) createTracesProcessor(
+	ctx context.Context,
+	set processor.Settings,
+	cfg component.Config,
+	next consumer.Traces,
+) (processor.Traces, error) {
+	processorCfg := cfg.(*Config)
+
+	source, err := f.createSource(ctx, set, processorCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	proc := newLookupProcessor(source, processorCfg, set.Logger)
+
+	return processorhelper.NewTraces(
+		ctx,
+		set,
+		cfg,
+		next,
+		proc.processTraces,
+		processorhelper.WithCapabilities(processorCapabilities),
+		processorhelper.WithStart(proc.Start),
+		processorhelper.WithShutdown(proc.Shutdown),
+	)
+}
+
+func (f *lookupProcessorFactory) createMetricsProcessor(
+	ctx context.Context,
+	set processor.Settings,
+	cfg component.Config,
+	next consumer.Metrics,
+) (processor.Metrics, error) {
+	processorCfg := cfg.(*Config)
+
+	source, err := f.createSource(ctx, set, processorCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	proc := newLookupProcessor(source, processorCfg, set.Logger)
+
+	return processorhelper.NewMetrics(
+		ctx,
+		set,
+		cfg,
+		next,
+		proc.processMetrics,
+		processorhelper.WithCapabilities(processorCapabilities),
+		processorhelper.WithStart(proc.Start),
+		processorhelper.WithShutdown(proc.Shutdown),
+	)
+}
+
 func (f *lookupProcessorFactory) createSource(
 	ctx context.Context,
 	set processor.Settings,
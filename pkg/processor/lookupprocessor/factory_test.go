@@ -94,26 +94,36 @@ func TestFactoryCreatesLogsProcessor(t *testing.T) {
 	require.NoError(t, proc.Shutdown(t.Context()))
 }
 
-func TestFactoryRejectsTracesProcessor(t *testing.T) {
+func TestFactoryCreatesTracesProcessor(t *testing.T) {
 	factory := NewFactory()
 
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.Attributes = []AttributeConfig{testAttributeConfig()}
 	settings := processortest.NewNopSettings(metadata.Type)
 
-	_, err := factory.CreateTraces(t.Context(), settings, cfg, consumertest.NewNop())
-	require.Error(t, err)
+	proc, err := factory.CreateTraces(t.Context(), settings, cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	require.NotNil(t, proc)
+
+	host := &testHost{}
+	require.NoError(t, proc.Start(t.Context(), host))
+	require.NoError(t, proc.Shutdown(t.Context()))
 }
 
-func TestFactoryRejectsMetricsProcessor(t *testing.T) {
+func TestFactoryCreatesMetricsProcessor(t *testing.T) {
 	factory := NewFactory()
 
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.Attributes = []AttributeConfig{testAttributeConfig()}
 	settings := processortest.NewNopSettings(metadata.Type)
 
-	_, err := factory.CreateMetrics(t.Context(), settings, cfg, consumertest.NewNop())
-	require.Error(t, err)
+	proc, err := factory.CreateMetrics(t.Context(), settings, cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	require.NotNil(t, proc)
+
+	host := &testHost{}
+	require.NoError(t, proc.Start(t.Context(), host))
+	require.NoError(t, proc.Shutdown(t.Context()))
 }
 
 func TestFactoryUnknownSourceType(t *testing.T) {
@@ -191,6 +201,37 @@ func TestConfigValidation(t *testing.T) {
 			},
 			wantErr: "from_attribute is required",
 		},
+		{
+			name: "key with columns",
+			cfg: &Config{
+				Attributes: []AttributeConfig{{
+					Key:           "test",
+					FromAttribute: "test",
+					Columns:       []ColumnConfig{{Column: "owner", Key: "host.owner"}},
+				}},
+			},
+			wantErr: "key and default cannot be used with columns",
+		},
+		{
+			name: "missing column name",
+			cfg: &Config{
+				Attributes: []AttributeConfig{{
+					FromAttribute: "test",
+					Columns:       []ColumnConfig{{Key: "host.owner"}},
+				}},
+			},
+			wantErr: "attributes[0].columns[0]: column is required",
+		},
+		{
+			name: "missing column key",
+			cfg: &Config{
+				Attributes: []AttributeConfig{{
+					FromAttribute: "test",
+					Columns:       []ColumnConfig{{Column: "owner"}},
+				}},
+			},
+			wantErr: "attributes[0].columns[0]: key is required",
+		},
 		{
 			name: "valid config",
 			cfg: &Config{
@@ -201,6 +242,20 @@ func TestConfigValidation(t *testing.T) {
 			},
 			wantErr: "",
 		},
+		{
+			name: "valid config with columns",
+			cfg: &Config{
+				Source: SourceConfig{Type: "csv"},
+				Attributes: []AttributeConfig{{
+					FromAttribute: "host.name",
+					Columns: []ColumnConfig{
+						{Column: "owner", Key: "host.owner", Default: "unknown"},
+						{Column: "environment", Key: "deployment.environment"},
+					},
+				}},
+			},
+			wantErr: "",
+		},
 		{
 			name: "valid config with all options",
 			cfg: &Config{
@@ -275,6 +330,10 @@ func TestContextIDUnmarshalText(t *testing.T) {
 		{"Record", ContextRecord, false},
 		{"resource", ContextResource, false},
 		{"RESOURCE", ContextResource, false},
+		{"span", ContextSpan, false},
+		{"spanevent", ContextSpanEvent, false},
+		{"SpanEvent", ContextSpanEvent, false},
+		{"datapoint", ContextDataPoint, false},
 		{"invalid", "", true},
 		{"", "", true},
 	}
@@ -0,0 +1,219 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package csv provides a CSV file-based lookup source.
+package csv
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"go.opentelemetry.io/collector/component"
+
+	"github.com/SumoLogic/sumologic-otel-collector/pkg/processor/lookupprocessor/lookupsource"
+)
+
+const (
+	sourceType = "csv"
+
+	defaultDelimiter = ","
+
+	// utf8BOM is prepended to CSV files by some spreadsheet tools.
+	utf8BOM = "\ufeff"
+)
+
+// Config is the configuration for the CSV lookup source.
+type Config struct {
+	// Path is the path to the CSV file. The first row of the file is a header
+	// with column names.
+	// Required.
+	Path string `mapstructure:"path"`
+
+	// KeyColumn is the name of the column containing lookup keys.
+	// Required.
+	KeyColumn string `mapstructure:"key_column"`
+
+	// Delimiter is the field delimiter, a single character.
+	// Default: ","
+	Delimiter string `mapstructure:"delimiter"`
+
+	// LazyQuotes allows quotes in unquoted fields and non-doubled quotes in quoted fields.
+	// Default: false
+	LazyQuotes bool `mapstructure:"lazy_quotes"`
+}
+
+// Validate implements lookupsource.SourceConfig.
+func (c *Config) Validate() error {
+	if c.Path == "" {
+		return errors.New("path is required")
+	}
+	if c.KeyColumn == "" {
+		return errors.New("key_column is required")
+	}
+	if _, err := c.delimiter(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *Config) delimiter() (rune, error) {
+	if utf8.RuneCountInString(c.Delimiter) != 1 {
+		return 0, fmt.Errorf("delimiter must be a single character, got %q", c.Delimiter)
+	}
+
+	r, _ := utf8.DecodeRuneInString(c.Delimiter)
+	if r == '"' || r == '\r' || r == '\n' || r == utf8.RuneError {
+		return 0, fmt.Errorf("invalid delimiter %q", c.Delimiter)
+	}
+	return r, nil
+}
+
+// NewFactory creates a factory for the CSV source.
+func NewFactory() lookupsource.SourceFactory {
+	return lookupsource.NewSourceFactory(
+		sourceType,
+		createDefaultConfig,
+		createSource,
+	)
+}
+
+func createDefaultConfig() lookupsource.SourceConfig {
+	return &Config{
+		Delimiter: defaultDelimiter,
+	}
+}
+
+func createSource(
+	_ context.Context,
+	_ lookupsource.CreateSettings,
+	cfg lookupsource.SourceConfig,
+) (lookupsource.Source, error) {
+	csvCfg := cfg.(*Config)
+	delimiter, err := csvCfg.delimiter()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &csvSource{
+		path:       csvCfg.Path,
+		keyColumn:  csvCfg.KeyColumn,
+		delimiter:  delimiter,
+		lazyQuotes: csvCfg.LazyQuotes,
+		rows:       make(map[string]map[string]any),
+	}
+
+	return lookupsource.NewSource(
+		s.lookup,
+		func() string { return sourceType },
+		s.start,
+		nil, // no shutdown needed
+	), nil
+}
+
+// csvSource holds rows of the loaded CSV file by key.
+type csvSource struct {
+	path       string
+	keyColumn  string
+	delimiter  rune
+	lazyQuotes bool
+
+	mu   sync.RWMutex
+	rows map[string]map[string]any
+}
+
+// start loads the CSV file.
+func (s *csvSource) start(_ context.Context, _ component.Host) error {
+	f, err := os.Open(s.path)
+	if err != nil {
+		return fmt.Errorf("failed to read CSV file %q: %w", s.path, err)
+	}
+	defer f.Close()
+
+	rows, err := s.parse(f)
+	if err != nil {
+		return fmt.Errorf("failed to parse CSV file %q: %w", s.path, err)
+	}
+
+	s.mu.Lock()
+	s.rows = rows
+	s.mu.Unlock()
+
+	return nil
+}
+
+// parse reads rows of the CSV content by key. Each row maps column names to values,
+// empty values are left out. When multiple rows have the same key, the last one wins.
+func (s *csvSource) parse(r io.Reader) (map[string]map[string]any, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = s.delimiter
+	reader.LazyQuotes = s.lazyQuotes
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("missing header row")
+	}
+	if err != nil {
+		return nil, err
+	}
+	header[0] = strings.TrimPrefix(header[0], utf8BOM)
+
+	keyIndex := -1
+	seen := make(map[string]struct{}, len(header))
+	for i, column := range header {
+		if _, duplicate := seen[column]; duplicate {
+			return nil, fmt.Errorf("duplicate column %q", column)
+		}
+		seen[column] = struct{}{}
+		if column == s.keyColumn {
+			keyIndex = i
+		}
+	}
+	if keyIndex < 0 {
+		return nil, fmt.Errorf("key column %q not found in header", s.keyColumn)
+	}
+
+	rows := make(map[string]map[string]any)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		key := record[keyIndex]
+		if key == "" {
+			continue
+		}
+
+		row := make(map[string]any, len(record))
+		for i, value := range record {
+			if value != "" {
+				row[header[i]] = value
+			}
+		}
+		rows[key] = row
+	}
+
+	return rows, nil
+}
+
+// lookup retrieves the row with the given key. The returned row must not be modified.
+func (s *csvSource) lookup(_ context.Context, key string) (any, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	row, found := s.rows[key]
+	if !found {
+		return nil, false, nil
+	}
+	return row, true, nil
+}
@@ -0,0 +1,90 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package csv
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+
+	"github.com/SumoLogic/sumologic-otel-collector/pkg/processor/lookupprocessor/lookupsource"
+)
+
+func BenchmarkCSVSourceLookup(b *testing.B) {
+	sizes := []int{10, 100, 1000, 10000}
+
+	for _, size := range sizes {
+		b.Run(fmt.Sprintf("rows_%d", size), func(b *testing.B) {
+			source := createBenchmarkSource(b, size)
+			keys := generateKeys(size, 100)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for b.Loop() {
+				for _, key := range keys {
+					_, _, _ = source.Lookup(b.Context(), key)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkCSVSourceLoad(b *testing.B) {
+	path := writeBenchmarkFile(b, 10000)
+	cfg := &Config{Path: path, KeyColumn: "id", Delimiter: ","}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for b.Loop() {
+		source, err := NewFactory().CreateSource(b.Context(), lookupsource.CreateSettings{}, cfg)
+		require.NoError(b, err)
+		require.NoError(b, source.Start(b.Context(), componenttest.NewNopHost()))
+	}
+}
+
+func createBenchmarkSource(b *testing.B, numRows int) lookupsource.Source {
+	b.Helper()
+
+	cfg := &Config{Path: writeBenchmarkFile(b, numRows), KeyColumn: "id", Delimiter: ","}
+	source, err := NewFactory().CreateSource(b.Context(), lookupsource.CreateSettings{}, cfg)
+	require.NoError(b, err)
+
+	err = source.Start(b.Context(), componenttest.NewNopHost())
+	require.NoError(b, err)
+
+	b.Cleanup(func() {
+		_ = source.Shutdown(b.Context())
+	})
+
+	return source
+}
+
+func writeBenchmarkFile(b *testing.B, numRows int) string {
+	b.Helper()
+
+	var content strings.Builder
+	content.WriteString("id,owner,environment,location\n")
+	for i := range numRows {
+		fmt.Fprintf(&content, "key%d,owner%d,production,location%d\n", i, i, i)
+	}
+
+	path := filepath.Join(b.TempDir(), "inventory.csv")
+	require.NoError(b, os.WriteFile(path, []byte(content.String()), 0o600))
+	return path
+}
+
+func generateKeys(numRows, numKeys int) []string {
+	keys := make([]string, numKeys)
+	for i := range numKeys {
+		keys[i] = fmt.Sprintf("key%d", i%numRows)
+	}
+	return keys
+}
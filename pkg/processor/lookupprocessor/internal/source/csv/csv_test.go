@@ -0,0 +1,240 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package csv
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+
+	"github.com/SumoLogic/sumologic-otel-collector/pkg/processor/lookupprocessor/lookupsource"
+)
+
+func TestNewFactory(t *testing.T) {
+	factory := NewFactory()
+	require.NotNil(t, factory)
+	assert.Equal(t, "csv", factory.Type())
+	assert.Equal(t, &Config{Delimiter: ","}, factory.CreateDefaultConfig())
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *Config
+		wantErr string
+	}{
+		{
+			name:    "empty path",
+			config:  &Config{KeyColumn: "id", Delimiter: ","},
+			wantErr: "path is required",
+		},
+		{
+			name:    "empty key column",
+			config:  &Config{Path: "/path/to/file.csv", Delimiter: ","},
+			wantErr: "key_column is required",
+		},
+		{
+			name:    "empty delimiter",
+			config:  &Config{Path: "/path/to/file.csv", KeyColumn: "id"},
+			wantErr: "delimiter must be a single character",
+		},
+		{
+			name:    "multi character delimiter",
+			config:  &Config{Path: "/path/to/file.csv", KeyColumn: "id", Delimiter: "::"},
+			wantErr: "delimiter must be a single character",
+		},
+		{
+			name:    "quote delimiter",
+			config:  &Config{Path: "/path/to/file.csv", KeyColumn: "id", Delimiter: `"`},
+			wantErr: "invalid delimiter",
+		},
+		{
+			name:   "valid config",
+			config: &Config{Path: "/path/to/file.csv", KeyColumn: "id", Delimiter: "\t"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCSVSourceLookup(t *testing.T) {
+	// exported with a byte order mark
+	content := "\ufeffhostname,owner,environment,location\n" +
+		"db-01,team-data,production,\"Frankfurt, DE\"\n" +
+		"web-01,team-web,staging,\n" +
+		",team-none,production,Warsaw\n" +
+		"web-02,\"team \"\"web\"\"\",production,Dublin\n"
+
+	source := startSource(t, content, &Config{KeyColumn: "hostname", Delimiter: ","})
+
+	tests := []struct {
+		key      string
+		expected any
+		found    bool
+	}{
+		{
+			key: "db-01",
+			expected: map[string]any{
+				"hostname":    "db-01",
+				"owner":       "team-data",
+				"environment": "production",
+				"location":    "Frankfurt, DE",
+			},
+			found: true,
+		},
+		{
+			// empty values are left out
+			key: "web-01",
+			expected: map[string]any{
+				"hostname":    "web-01",
+				"owner":       "team-web",
+				"environment": "staging",
+			},
+			found: true,
+		},
+		{
+			key: "web-02",
+			expected: map[string]any{
+				"hostname":    "web-02",
+				"owner":       `team "web"`,
+				"environment": "production",
+				"location":    "Dublin",
+			},
+			found: true,
+		},
+		{key: "", found: false},
+		{key: "nonexistent", found: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			val, found, err := source.Lookup(t.Context(), tt.key)
+			require.NoError(t, err)
+			assert.Equal(t, tt.found, found)
+			if tt.found {
+				assert.Equal(t, tt.expected, val)
+			}
+		})
+	}
+
+	require.NoError(t, source.Shutdown(t.Context()))
+}
+
+func TestCSVSourceDelimiterAndLazyQuotes(t *testing.T) {
+	content := "owner;id\n" +
+		"team \"data\";db-01\n" +
+		"team-web;web-01\n" +
+		// later rows win
+		"team-ops;web-01\n"
+
+	source := startSource(t, content, &Config{KeyColumn: "id", Delimiter: ";", LazyQuotes: true})
+
+	val, found, err := source.Lookup(t.Context(), "db-01")
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, map[string]any{"id": "db-01", "owner": `team "data"`}, val)
+
+	val, found, err = source.Lookup(t.Context(), "web-01")
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, map[string]any{"id": "web-01", "owner": "team-ops"}, val)
+}
+
+func TestCSVSourceInvalidFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "empty file",
+			content: "",
+			wantErr: "missing header row",
+		},
+		{
+			name:    "missing key column",
+			content: "name,owner\ndb-01,team-data\n",
+			wantErr: `key column "id" not found in header`,
+		},
+		{
+			name:    "duplicate column",
+			content: "id,owner,owner\ndb-01,team-data,team-ops\n",
+			wantErr: `duplicate column "owner"`,
+		},
+		{
+			name:    "wrong number of fields",
+			content: "id,owner\ndb-01,team-data,extra\n",
+			wantErr: "wrong number of fields",
+		},
+		{
+			name:    "bare quote",
+			content: "id,owner\ndb-01,team \"data\"\n",
+			wantErr: "bare \" in non-quoted-field",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, err := NewFactory().CreateSource(t.Context(), lookupsource.CreateSettings{}, &Config{
+				Path:      writeFile(t, tt.content),
+				KeyColumn: "id",
+				Delimiter: ",",
+			})
+			require.NoError(t, err)
+
+			err = source.Start(t.Context(), componenttest.NewNopHost())
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "failed to parse CSV file")
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestCSVSourceFileNotFound(t *testing.T) {
+	factory := NewFactory()
+	cfg := &Config{Path: "/nonexistent/path/to/file.csv", KeyColumn: "id", Delimiter: ","}
+
+	source, err := factory.CreateSource(t.Context(), lookupsource.CreateSettings{}, cfg)
+	require.NoError(t, err)
+
+	err = source.Start(t.Context(), componenttest.NewNopHost())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read CSV file")
+}
+
+func startSource(t *testing.T, content string, cfg *Config) lookupsource.Source {
+	t.Helper()
+
+	cfg.Path = writeFile(t, content)
+	settings := lookupsource.CreateSettings{
+		TelemetrySettings: componenttest.NewNopTelemetrySettings(),
+	}
+
+	source, err := NewFactory().CreateSource(t.Context(), settings, cfg)
+	require.NoError(t, err)
+	require.NoError(t, source.Start(t.Context(), componenttest.NewNopHost()))
+	return source
+}
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "inventory.csv")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
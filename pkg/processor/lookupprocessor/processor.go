@@ -6,11 +6,14 @@ package lookupprocessor
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strconv"
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
 	"go.uber.org/zap"
 
 	"github.com/SumoLogic/sumologic-otel-collector/pkg/processor/lookupprocessor/lookupsource"
@@ -42,34 +45,108 @@ func (p *lookupProcessor) processLogs(ctx context.Context, ld plog.Logs) (plog.L
 	resourceLogs := ld.ResourceLogs()
 	for i := 0; i < resourceLogs.Len(); i++ {
 		rl := resourceLogs.At(i)
-		resourceAttrs := rl.Resource().Attributes()
 
 		// Process resource-level attributes
-		for _, attrCfg := range p.attributes {
-			if attrCfg.GetContext() == ContextResource {
-				p.processAttribute(ctx, resourceAttrs, attrCfg)
-			}
-		}
+		p.processAttributes(ctx, rl.Resource().Attributes(), ContextResource)
 
 		// Process log records
 		scopeLogs := rl.ScopeLogs()
 		for j := 0; j < scopeLogs.Len(); j++ {
-			sl := scopeLogs.At(j)
-			logRecords := sl.LogRecords()
+			logRecords := scopeLogs.At(j).LogRecords()
 			for k := 0; k < logRecords.Len(); k++ {
-				lr := logRecords.At(k)
-				logAttrs := lr.Attributes()
+				p.processAttributes(ctx, logRecords.At(k).Attributes(), ContextRecord)
+			}
+		}
+	}
+
+	return ld, nil
+}
+
+func (p *lookupProcessor) processTraces(ctx context.Context, td ptrace.Traces) (ptrace.Traces, error) {
+	resourceSpans := td.ResourceSpans()
+	for i := 0; i < resourceSpans.Len(); i++ {
+		rs := resourceSpans.At(i)
+
+		// Process resource-level attributes
+		p.processAttributes(ctx, rs.Resource().Attributes(), ContextResource)
 
-				for _, attrCfg := range p.attributes {
-					if attrCfg.GetContext() == ContextRecord {
-						p.processAttribute(ctx, logAttrs, attrCfg)
-					}
+		// Process spans and their events
+		scopeSpans := rs.ScopeSpans()
+		for j := 0; j < scopeSpans.Len(); j++ {
+			spans := scopeSpans.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				span := spans.At(k)
+				p.processAttributes(ctx, span.Attributes(), ContextRecord, ContextSpan)
+
+				events := span.Events()
+				for l := 0; l < events.Len(); l++ {
+					p.processAttributes(ctx, events.At(l).Attributes(), ContextSpanEvent)
 				}
 			}
 		}
 	}
 
-	return ld, nil
+	return td, nil
+}
+
+func (p *lookupProcessor) processMetrics(ctx context.Context, md pmetric.Metrics) (pmetric.Metrics, error) {
+	resourceMetrics := md.ResourceMetrics()
+	for i := 0; i < resourceMetrics.Len(); i++ {
+		rm := resourceMetrics.At(i)
+
+		// Process resource-level attributes
+		p.processAttributes(ctx, rm.Resource().Attributes(), ContextResource)
+
+		// Process metric data points
+		scopeMetrics := rm.ScopeMetrics()
+		for j := 0; j < scopeMetrics.Len(); j++ {
+			metrics := scopeMetrics.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				p.processDataPoints(ctx, metrics.At(k))
+			}
+		}
+	}
+
+	return md, nil
+}
+
+func (p *lookupProcessor) processDataPoints(ctx context.Context, metric pmetric.Metric) {
+	switch metric.Type() {
+	case pmetric.MetricTypeGauge:
+		dataPoints := metric.Gauge().DataPoints()
+		for i := 0; i < dataPoints.Len(); i++ {
+			p.processAttributes(ctx, dataPoints.At(i).Attributes(), ContextRecord, ContextDataPoint)
+		}
+	case pmetric.MetricTypeSum:
+		dataPoints := metric.Sum().DataPoints()
+		for i := 0; i < dataPoints.Len(); i++ {
+			p.processAttributes(ctx, dataPoints.At(i).Attributes(), ContextRecord, ContextDataPoint)
+		}
+	case pmetric.MetricTypeHistogram:
+		dataPoints := metric.Histogram().DataPoints()
+		for i := 0; i < dataPoints.Len(); i++ {
+			p.processAttributes(ctx, dataPoints.At(i).Attributes(), ContextRecord, ContextDataPoint)
+		}
+	case pmetric.MetricTypeExponentialHistogram:
+		dataPoints := metric.ExponentialHistogram().DataPoints()
+		for i := 0; i < dataPoints.Len(); i++ {
+			p.processAttributes(ctx, dataPoints.At(i).Attributes(), ContextRecord, ContextDataPoint)
+		}
+	case pmetric.MetricTypeSummary:
+		dataPoints := metric.Summary().DataPoints()
+		for i := 0; i < dataPoints.Len(); i++ {
+			p.processAttributes(ctx, dataPoints.At(i).Attributes(), ContextRecord, ContextDataPoint)
+		}
+	}
+}
+
+// processAttributes applies the attribute rules configured for any of the given contexts.
+func (p *lookupProcessor) processAttributes(ctx context.Context, attrs pcommon.Map, contexts ...ContextID) {
+	for _, attrCfg := range p.attributes {
+		if slices.Contains(contexts, attrCfg.GetContext()) {
+			p.processAttribute(ctx, attrs, attrCfg)
+		}
+	}
 }
 
 func (p *lookupProcessor) processAttribute(ctx context.Context, attrs pcommon.Map, cfg AttributeConfig) {
@@ -95,15 +172,12 @@ func (p *lookupProcessor) processAttribute(ctx context.Context, attrs pcommon.Ma
 
 	action := cfg.GetAction()
 
-	var shouldSet bool
-	if action == ActionUpsert {
-		shouldSet = true
-	} else {
-		_, targetExists := attrs.Get(cfg.Key)
-		shouldSet = (action == ActionInsert && !targetExists) || (action == ActionUpdate && targetExists)
+	if len(cfg.Columns) > 0 {
+		p.processColumns(attrs, cfg.Columns, action, result, found)
+		return
 	}
 
-	if !shouldSet {
+	if !shouldSet(attrs, cfg.Key, action) {
 		return
 	}
 
@@ -118,6 +192,41 @@ func (p *lookupProcessor) processAttribute(ctx context.Context, attrs pcommon.Ma
 	putAny(attrs, cfg.Key, result)
 }
 
+// processColumns sets an attribute for each of the columns from fields of a structured lookup result.
+func (p *lookupProcessor) processColumns(attrs pcommon.Map, columns []ColumnConfig, action Action, result any, found bool) {
+	fields, ok := result.(map[string]any)
+	if found && !ok {
+		p.logger.Debug("lookup result has no columns",
+			zap.String("type", fmt.Sprintf("%T", result)),
+		)
+	}
+
+	for _, column := range columns {
+		if !shouldSet(attrs, column.Key, action) {
+			continue
+		}
+
+		value, exists := fields[column.Column]
+		if !exists {
+			if column.Default != "" {
+				attrs.PutStr(column.Key, column.Default)
+			}
+			continue
+		}
+
+		putAny(attrs, column.Key, value)
+	}
+}
+
+func shouldSet(attrs pcommon.Map, key string, action Action) bool {
+	if action == ActionUpsert {
+		return true
+	}
+
+	_, targetExists := attrs.Get(key)
+	return (action == ActionInsert && !targetExists) || (action == ActionUpdate && targetExists)
+}
+
 func putAny(attrs pcommon.Map, key string, v any) {
 	switch val := v.(type) {
 	case string:
@@ -130,6 +239,10 @@ func putAny(attrs pcommon.Map, key string, v any) {
 		attrs.PutDouble(key, val)
 	case bool:
 		attrs.PutBool(key, val)
+	case map[string]any:
+		if err := attrs.PutEmptyMap(key).FromRaw(val); err != nil {
+			attrs.PutStr(key, fmt.Sprintf("%v", v))
+		}
 	default:
 		attrs.PutStr(key, fmt.Sprintf("%v", v))
 	}
@@ -10,7 +10,10 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
 	"go.opentelemetry.io/collector/processor/processortest"
 
 	"github.com/SumoLogic/sumologic-otel-collector/pkg/processor/lookupprocessor/internal/metadata"
@@ -258,6 +261,230 @@ func TestProcessorNoSourceAttribute(t *testing.T) {
 	assert.False(t, exists, "result should not be set when source attribute is missing")
 }
 
+func TestProcessorTraces(t *testing.T) {
+	mappings := map[string]any{
+		"user001":      "Alice Johnson",
+		"svc-frontend": "Frontend Web App",
+		"cache-miss":   "Cache Miss",
+	}
+
+	factory := NewFactoryWithOptions(WithSources(mockMapSourceFactory(mappings)))
+	cfg := &Config{
+		Source: SourceConfig{Type: "mockmap"},
+		Attributes: []AttributeConfig{
+			{Key: "user.name", FromAttribute: "user.id", Context: ContextRecord},
+			{Key: "service.display_name", FromAttribute: "service.name", Context: ContextResource},
+			{Key: "event.display_name", FromAttribute: "event.id", Context: ContextSpanEvent},
+			// not applicable to traces
+			{Key: "metric.user.name", FromAttribute: "user.id", Context: ContextDataPoint},
+		},
+	}
+
+	sink := &consumertest.TracesSink{}
+	settings := processortest.NewNopSettings(metadata.Type)
+
+	proc, err := factory.CreateTraces(t.Context(), settings, cfg, sink)
+	require.NoError(t, err)
+
+	host := &testHost{}
+	require.NoError(t, proc.Start(t.Context(), host))
+	defer func() { _ = proc.Shutdown(t.Context()) }()
+
+	traces := ptrace.NewTraces()
+	rs := traces.ResourceSpans().AppendEmpty()
+	rs.Resource().Attributes().PutStr("service.name", "svc-frontend")
+	span := rs.ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+	span.Attributes().PutStr("user.id", "user001")
+	event := span.Events().AppendEmpty()
+	event.Attributes().PutStr("event.id", "cache-miss")
+	event.Attributes().PutStr("user.id", "user001")
+
+	require.NoError(t, proc.ConsumeTraces(t.Context(), traces))
+	require.Len(t, sink.AllTraces(), 1)
+	processed := sink.AllTraces()[0].ResourceSpans().At(0)
+
+	serviceName, exists := processed.Resource().Attributes().Get("service.display_name")
+	require.True(t, exists)
+	assert.Equal(t, "Frontend Web App", serviceName.Str())
+
+	processedSpan := processed.ScopeSpans().At(0).Spans().At(0)
+	userName, exists := processedSpan.Attributes().Get("user.name")
+	require.True(t, exists)
+	assert.Equal(t, "Alice Johnson", userName.Str())
+	_, exists = processedSpan.Attributes().Get("metric.user.name")
+	assert.False(t, exists, "datapoint context should not apply to spans")
+
+	processedEvent := processedSpan.Events().At(0)
+	eventName, exists := processedEvent.Attributes().Get("event.display_name")
+	require.True(t, exists)
+	assert.Equal(t, "Cache Miss", eventName.Str())
+	_, exists = processedEvent.Attributes().Get("user.name")
+	assert.False(t, exists, "record context should not apply to span events")
+}
+
+func TestProcessorMetrics(t *testing.T) {
+	mappings := map[string]any{
+		"host-1":       "Database Server",
+		"svc-frontend": "Frontend Web App",
+	}
+
+	factory := NewFactoryWithOptions(WithSources(mockMapSourceFactory(mappings)))
+	cfg := &Config{
+		Source: SourceConfig{Type: "mockmap"},
+		Attributes: []AttributeConfig{
+			{Key: "host.display_name", FromAttribute: "host.id", Context: ContextDataPoint},
+			{Key: "service.display_name", FromAttribute: "service.name", Context: ContextResource},
+			// not applicable to metrics
+			{Key: "span.host.display_name", FromAttribute: "host.id", Context: ContextSpan},
+		},
+	}
+
+	sink := &consumertest.MetricsSink{}
+	settings := processortest.NewNopSettings(metadata.Type)
+
+	proc, err := factory.CreateMetrics(t.Context(), settings, cfg, sink)
+	require.NoError(t, err)
+
+	host := &testHost{}
+	require.NoError(t, proc.Start(t.Context(), host))
+	defer func() { _ = proc.Shutdown(t.Context()) }()
+
+	metrics := pmetric.NewMetrics()
+	rm := metrics.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("service.name", "svc-frontend")
+	ms := rm.ScopeMetrics().AppendEmpty().Metrics()
+	ms.AppendEmpty().SetEmptyGauge().DataPoints().AppendEmpty().Attributes().PutStr("host.id", "host-1")
+	ms.AppendEmpty().SetEmptySum().DataPoints().AppendEmpty().Attributes().PutStr("host.id", "host-1")
+	ms.AppendEmpty().SetEmptyHistogram().DataPoints().AppendEmpty().Attributes().PutStr("host.id", "host-1")
+	ms.AppendEmpty().SetEmptyExponentialHistogram().DataPoints().AppendEmpty().Attributes().PutStr("host.id", "host-1")
+	ms.AppendEmpty().SetEmptySummary().DataPoints().AppendEmpty().Attributes().PutStr("host.id", "host-1")
+
+	require.NoError(t, proc.ConsumeMetrics(t.Context(), metrics))
+	require.Len(t, sink.AllMetrics(), 1)
+	processed := sink.AllMetrics()[0].ResourceMetrics().At(0)
+
+	serviceName, exists := processed.Resource().Attributes().Get("service.display_name")
+	require.True(t, exists)
+	assert.Equal(t, "Frontend Web App", serviceName.Str())
+
+	processedMetrics := processed.ScopeMetrics().At(0).Metrics()
+	attributes := []pcommon.Map{
+		processedMetrics.At(0).Gauge().DataPoints().At(0).Attributes(),
+		processedMetrics.At(1).Sum().DataPoints().At(0).Attributes(),
+		processedMetrics.At(2).Histogram().DataPoints().At(0).Attributes(),
+		processedMetrics.At(3).ExponentialHistogram().DataPoints().At(0).Attributes(),
+		processedMetrics.At(4).Summary().DataPoints().At(0).Attributes(),
+	}
+	for i, attrs := range attributes {
+		hostName, exists := attrs.Get("host.display_name")
+		require.True(t, exists, "metric %d", i)
+		assert.Equal(t, "Database Server", hostName.Str())
+		_, exists = attrs.Get("span.host.display_name")
+		assert.False(t, exists, "span context should not apply to data points")
+	}
+}
+
+func TestProcessorColumns(t *testing.T) {
+	mappings := map[string]any{
+		"db-01": map[string]any{
+			"hostname":    "db-01",
+			"owner":       "team-data",
+			"environment": "production",
+		},
+		"web-01": map[string]any{
+			"hostname": "web-01",
+			"owner":    "team-web",
+		},
+		"flat": "not a row",
+	}
+
+	factory := NewFactoryWithOptions(WithSources(mockMapSourceFactory(mappings)))
+	cfg := &Config{
+		Source: SourceConfig{Type: "mockmap"},
+		Attributes: []AttributeConfig{
+			{
+				FromAttribute: "host.name",
+				Action:        ActionInsert,
+				Columns: []ColumnConfig{
+					{Column: "owner", Key: "host.owner", Default: "unknown"},
+					{Column: "environment", Key: "deployment.environment"},
+				},
+			},
+			{
+				Key:           "host.inventory",
+				FromAttribute: "host.name",
+			},
+		},
+	}
+
+	sink := &consumertest.LogsSink{}
+	settings := processortest.NewNopSettings(metadata.Type)
+
+	proc, err := factory.CreateLogs(t.Context(), settings, cfg, sink)
+	require.NoError(t, err)
+
+	host := &testHost{}
+	require.NoError(t, proc.Start(t.Context(), host))
+	defer func() { _ = proc.Shutdown(t.Context()) }()
+
+	logs := plog.NewLogs()
+	lrs := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	for _, hostName := range []string{"db-01", "web-01", "flat", "unknown-host"} {
+		lrs.AppendEmpty().Attributes().PutStr("host.name", hostName)
+	}
+	// insert action keeps existing attributes
+	existing := lrs.AppendEmpty().Attributes()
+	existing.PutStr("host.name", "db-01")
+	existing.PutStr("host.owner", "team-override")
+
+	require.NoError(t, proc.ConsumeLogs(t.Context(), logs))
+	processed := sink.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+
+	expected := []map[string]any{
+		{
+			"host.name":              "db-01",
+			"host.owner":             "team-data",
+			"deployment.environment": "production",
+			"host.inventory": map[string]any{
+				"hostname":    "db-01",
+				"owner":       "team-data",
+				"environment": "production",
+			},
+		},
+		{
+			"host.name":  "web-01",
+			"host.owner": "team-web",
+			"host.inventory": map[string]any{
+				"hostname": "web-01",
+				"owner":    "team-web",
+			},
+		},
+		{
+			"host.name":      "flat",
+			"host.owner":     "unknown",
+			"host.inventory": "not a row",
+		},
+		{
+			"host.name":  "unknown-host",
+			"host.owner": "unknown",
+		},
+		{
+			"host.name":              "db-01",
+			"host.owner":             "team-override",
+			"deployment.environment": "production",
+			"host.inventory": map[string]any{
+				"hostname":    "db-01",
+				"owner":       "team-data",
+				"environment": "production",
+			},
+		},
+	}
+	require.Equal(t, len(expected), processed.Len())
+	for i, attrs := range expected {
+		assert.Equal(t, attrs, processed.At(i).Attributes().AsRaw(), "log record %d", i)
+	}
+}
+
 // mockSourceFactory creates a source factory that always returns the given value.
 func mockSourceFactory(value string) lookupsource.SourceFactory {
 	return lookupsource.NewSourceFactory(
@@ -0,0 +1,265 @@
+package metricfrequencyprocessor
+
+import (
+	"math"
+	"slices"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+const (
+	// thinningModeDrop drops sifted data points.
+	thinningModeDrop = "drop"
+	// thinningModeAggregate folds sifted data points into the next reported data point of their series.
+	thinningModeAggregate = "aggregate"
+
+	minSuffix = ".min"
+	maxSuffix = ".max"
+	avgSuffix = ".avg"
+)
+
+// gaugeWindow accumulates gauge values seen since the last reported data point of a series.
+type gaugeWindow struct {
+	start pcommon.Timestamp
+	count int
+	sum   float64
+	min   float64
+	max   float64
+}
+
+func (w *gaugeWindow) add(timestamp pcommon.Timestamp, value float64) {
+	if w.count == 0 {
+		w.start = timestamp
+		w.min = value
+		w.max = value
+	}
+
+	w.count++
+	w.sum += value
+	w.min = math.Min(w.min, value)
+	w.max = math.Max(w.max, value)
+}
+
+// gaugeCompanions creates metrics summarising gauge values folded into reported data points of a metric.
+// Companion metrics are created lazily, only when there is something to summarise.
+type gaugeCompanions struct {
+	metric pmetric.Metric
+	out    pmetric.MetricSlice
+
+	created bool
+	min     pmetric.NumberDataPointSlice
+	max     pmetric.NumberDataPointSlice
+	avg     pmetric.NumberDataPointSlice
+}
+
+func newGaugeCompanions(metric pmetric.Metric, out pmetric.MetricSlice) *gaugeCompanions {
+	return &gaugeCompanions{metric: metric, out: out}
+}
+
+// add appends data points describing the window to companion metrics. Their attributes and timestamp
+// are taken from the reported data point.
+func (c *gaugeCompanions) add(dataPoint pmetric.NumberDataPoint, window *gaugeWindow) {
+	if !c.created {
+		c.min = c.newCompanion(minSuffix)
+		c.max = c.newCompanion(maxSuffix)
+		c.avg = c.newCompanion(avgSuffix)
+		c.created = true
+	}
+
+	for _, companion := range []struct {
+		dataPoints pmetric.NumberDataPointSlice
+		value      float64
+	}{
+		{c.min, window.min},
+		{c.max, window.max},
+		{c.avg, window.sum / float64(window.count)},
+	} {
+		out := companion.dataPoints.AppendEmpty()
+		dataPoint.Attributes().CopyTo(out.Attributes())
+		out.SetStartTimestamp(window.start)
+		out.SetTimestamp(dataPoint.Timestamp())
+		out.SetDoubleValue(companion.value)
+	}
+}
+
+func (c *gaugeCompanions) newCompanion(suffix string) pmetric.NumberDataPointSlice {
+	companion := c.out.AppendEmpty()
+	companion.SetName(c.metric.Name() + suffix)
+	companion.SetDescription(c.metric.Description())
+	companion.SetUnit(c.metric.Unit())
+	return companion.SetEmptyGauge().DataPoints()
+}
+
+// foldGauge adds the gauge value to the series window. When the data point is reported after some were removed,
+// minimum, maximum and average of the window are reported with companion metrics.
+// The caller has to hold the series lock.
+func foldGauge(s *series, dataPoint pmetric.NumberDataPoint, removed bool, companions *gaugeCompanions) {
+	value := getVal(dataPoint)
+	if math.IsNaN(value) {
+		return
+	}
+
+	if s.gaugeWindow == nil {
+		s.gaugeWindow = &gaugeWindow{}
+	}
+	s.gaugeWindow.add(dataPoint.Timestamp(), value)
+	if removed {
+		return
+	}
+
+	if s.gaugeWindow.count > 1 {
+		companions.add(dataPoint, s.gaugeWindow)
+	}
+	s.gaugeWindow = nil
+}
+
+// foldDeltaSum accumulates removed delta sum data points and adds them to the next reported one,
+// so that the sum over time is preserved. The caller has to hold the series lock.
+func foldDeltaSum(s *series, scope metricScope, metric pmetric.Metric, dataPoint pmetric.NumberDataPoint, removed bool) {
+	if removed {
+		if s.pendingSum == nil {
+			pending := newPendingMetric(s, scope, metric).SetEmptySum()
+			pending.SetAggregationTemporality(metric.Sum().AggregationTemporality())
+			pending.SetIsMonotonic(metric.Sum().IsMonotonic())
+			pendingDataPoint := pending.DataPoints().AppendEmpty()
+			dataPoint.CopyTo(pendingDataPoint)
+			s.pendingSum = &pendingDataPoint
+			return
+		}
+		addNumberDataPoint(*s.pendingSum, dataPoint)
+		return
+	}
+
+	if s.pendingSum != nil {
+		addNumberDataPoint(dataPoint, *s.pendingSum)
+		s.clearPending()
+	}
+}
+
+// addNumberDataPoint adds value of the other delta data point to the target one, extending its time range.
+func addNumberDataPoint(target pmetric.NumberDataPoint, other pmetric.NumberDataPoint) {
+	if target.ValueType() == pmetric.NumberDataPointValueTypeInt && other.ValueType() == pmetric.NumberDataPointValueTypeInt {
+		target.SetIntValue(target.IntValue() + other.IntValue())
+	} else {
+		target.SetDoubleValue(getVal(target) + getVal(other))
+	}
+
+	target.SetStartTimestamp(earlierStart(target.StartTimestamp(), other.StartTimestamp()))
+	target.SetTimestamp(max(target.Timestamp(), other.Timestamp()))
+}
+
+// foldDeltaHistogram accumulates removed delta histogram data points and merges them into the next reported one.
+// Data points with bucket boundaries different from the accumulated ones cannot be merged, so the accumulated
+// data point is appended to flushed to be reported on its own. The caller has to hold the series lock.
+func foldDeltaHistogram(
+	s *series,
+	scope metricScope,
+	metric pmetric.Metric,
+	dataPoint pmetric.HistogramDataPoint,
+	removed bool,
+	flushed pmetric.HistogramDataPointSlice,
+) {
+	if s.pendingHistogram != nil && !slices.Equal(s.pendingHistogram.ExplicitBounds().AsRaw(), dataPoint.ExplicitBounds().AsRaw()) {
+		s.pendingHistogram.MoveTo(flushed.AppendEmpty())
+		s.clearPending()
+	}
+
+	if removed {
+		if s.pendingHistogram == nil {
+			pending := newPendingMetric(s, scope, metric).SetEmptyHistogram()
+			pending.SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+			pendingDataPoint := pending.DataPoints().AppendEmpty()
+			dataPoint.CopyTo(pendingDataPoint)
+			s.pendingHistogram = &pendingDataPoint
+			return
+		}
+		mergeHistogramDataPoint(*s.pendingHistogram, dataPoint)
+		return
+	}
+
+	if s.pendingHistogram != nil {
+		mergeHistogramDataPoint(dataPoint, *s.pendingHistogram)
+		s.clearPending()
+	}
+}
+
+// mergeHistogramDataPoint merges the other delta histogram data point with the same bucket boundaries into the target one,
+// extending its time range.
+func mergeHistogramDataPoint(target pmetric.HistogramDataPoint, other pmetric.HistogramDataPoint) {
+	target.SetCount(target.Count() + other.Count())
+	if target.HasSum() || other.HasSum() {
+		target.SetSum(target.Sum() + other.Sum())
+	}
+	if other.HasMin() && (!target.HasMin() || other.Min() < target.Min()) {
+		target.SetMin(other.Min())
+	}
+	if other.HasMax() && (!target.HasMax() || other.Max() > target.Max()) {
+		target.SetMax(other.Max())
+	}
+
+	bucketCounts := target.BucketCounts()
+	for i := 0; i < bucketCounts.Len() && i < other.BucketCounts().Len(); i++ {
+		bucketCounts.SetAt(i, bucketCounts.At(i)+other.BucketCounts().At(i))
+	}
+
+	target.SetStartTimestamp(earlierStart(target.StartTimestamp(), other.StartTimestamp()))
+	target.SetTimestamp(max(target.Timestamp(), other.Timestamp()))
+}
+
+func earlierStart(a pcommon.Timestamp, b pcommon.Timestamp) pcommon.Timestamp {
+	if a == 0 || (b != 0 && b < a) {
+		return b
+	}
+	return a
+}
+
+// newPendingMetric sets up the series to hold removed data points in a copy of the resource, scope and metric
+// they belong to, so that the data points can be reported on their own if the series is evicted before any
+// of its data points is reported. It returns the empty metric. The caller has to hold the series lock.
+func newPendingMetric(s *series, scope metricScope, metric pmetric.Metric) pmetric.Metric {
+	pending := pmetric.NewMetrics()
+	rm := pending.ResourceMetrics().AppendEmpty()
+	scope.resource.CopyTo(rm.Resource())
+	sm := rm.ScopeMetrics().AppendEmpty()
+	scope.scope.CopyTo(sm.Scope())
+	out := sm.Metrics().AppendEmpty()
+	out.SetName(metric.Name())
+	out.SetDescription(metric.Description())
+	out.SetUnit(metric.Unit())
+
+	s.pending = &pending
+	return out
+}
+
+// clearPending forgets data points removed from the series. The caller has to hold the series lock.
+func (s *series) clearPending() {
+	s.pending = nil
+	s.pendingSum = nil
+	s.pendingHistogram = nil
+}
+
+// takePending returns metrics holding data points removed from the series, if there are any, and forgets them.
+// The caller has to hold the series lock.
+func (s *series) takePending() (pmetric.Metrics, bool) {
+	if s.pending == nil {
+		return pmetric.Metrics{}, false
+	}
+
+	pending := *s.pending
+	s.clearPending()
+	return pending, true
+}
+
+// flusher is implemented by sieves holding data points of evicted series, which have to be reported on their own.
+type flusher interface {
+	// Flush moves the held data points to md.
+	Flush(md pmetric.Metrics)
+}
+
+// Flush moves data points removed from evicted series to md.
+func (ms *defaultMetricSieve) Flush(md pmetric.Metrics) {
+	for _, pending := range ms.metricCache.TakeFlushed() {
+		pending.ResourceMetrics().MoveAndAppendTo(md.ResourceMetrics())
+	}
+}
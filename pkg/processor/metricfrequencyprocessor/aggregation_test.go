@@ -0,0 +1,228 @@
+package metricfrequencyprocessor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func TestFoldGauge(t *testing.T) {
+	metric := pmetric.NewMetric()
+	metric.SetName("test")
+	metric.SetUnit("By")
+	out := pmetric.NewMetricSlice()
+	companions := newGaugeCompanions(metric, out)
+	s := &series{}
+	start := time.Unix(0, 0)
+
+	for i, value := range []float64{1.0, 5.0, 2.0} {
+		foldGauge(s, gaugeDataPoint(minutesAfter(start, i), value), true, companions)
+	}
+	assert.Equal(t, 0, out.Len())
+
+	reported := gaugeDataPoint(minutesAfter(start, 3), 3.0)
+	reported.Attributes().PutStr("host", "a")
+	foldGauge(s, reported, false, companions)
+
+	require.Equal(t, 3, out.Len())
+	expected := map[string]float64{"test.min": 1.0, "test.max": 5.0, "test.avg": 2.75}
+	for i := 0; i < out.Len(); i++ {
+		companion := out.At(i)
+		assert.Equal(t, "By", companion.Unit())
+		require.Equal(t, 1, companion.Gauge().DataPoints().Len())
+		dataPoint := companion.Gauge().DataPoints().At(0)
+		assert.Equal(t, expected[companion.Name()], dataPoint.DoubleValue(), companion.Name())
+		assert.Equal(t, pcommon.NewTimestampFromTime(start), dataPoint.StartTimestamp())
+		assert.Equal(t, reported.Timestamp(), dataPoint.Timestamp())
+		assert.Equal(t, map[string]any{"host": "a"}, dataPoint.Attributes().AsRaw())
+	}
+	assert.Nil(t, s.gaugeWindow)
+
+	// nothing was removed since the last reported data point, so there is nothing to summarise
+	foldGauge(s, gaugeDataPoint(minutesAfter(start, 4), 3.0), false, companions)
+	assert.Equal(t, 1, out.At(0).Gauge().DataPoints().Len())
+}
+
+func TestFoldDeltaSum(t *testing.T) {
+	s := &series{}
+	scope := testScope()
+	metric := sumMetric(pmetric.AggregationTemporalityDelta, time.Unix(0, 0), time.Unix(0, 0), 0)
+	start := time.Unix(3600, 0)
+	for i := 0; i < 3; i++ {
+		dataPoint := pmetric.NewNumberDataPoint()
+		dataPoint.SetStartTimestamp(pcommon.NewTimestampFromTime(minutesAfter(start, i)))
+		dataPoint.SetTimestamp(pcommon.NewTimestampFromTime(minutesAfter(start, i+1)))
+		dataPoint.SetIntValue(int64(i + 1))
+		foldDeltaSum(s, scope, metric, dataPoint, i < 2)
+		if i == 2 {
+			assert.Equal(t, int64(6), dataPoint.IntValue())
+			assert.Equal(t, pcommon.NewTimestampFromTime(start), dataPoint.StartTimestamp())
+		}
+	}
+
+	assert.Nil(t, s.pendingSum)
+	assert.Nil(t, s.pending)
+}
+
+func TestFoldDeltaHistogram(t *testing.T) {
+	s := &series{}
+	scope := testScope()
+	metric := histogramMetric(pmetric.AggregationTemporalityDelta, time.Unix(0, 0), time.Unix(0, 0), 0, 0)
+	flushed := pmetric.NewHistogramDataPointSlice()
+
+	foldDeltaHistogram(s, scope, metric, histogramDataPoint([]float64{1, 10}, []uint64{1, 2, 3}, 1, 20), true, flushed)
+	foldDeltaHistogram(s, scope, metric, histogramDataPoint([]float64{1, 10}, []uint64{0, 1, 0}, 5, 5), true, flushed)
+
+	reported := histogramDataPoint([]float64{1, 10}, []uint64{1, 0, 0}, 0.5, 0.5)
+	foldDeltaHistogram(s, scope, metric, reported, false, flushed)
+	assert.Equal(t, []uint64{2, 3, 3}, reported.BucketCounts().AsRaw())
+	assert.Equal(t, uint64(8), reported.Count())
+	assert.Equal(t, 0.5, reported.Min())
+	assert.Equal(t, 20.0, reported.Max())
+	assert.Nil(t, s.pendingHistogram)
+	assert.Equal(t, 0, flushed.Len())
+
+	// data points with different bucket boundaries cannot be merged, so the accumulated one is reported on its own
+	foldDeltaHistogram(s, scope, metric, histogramDataPoint([]float64{1, 10}, []uint64{1, 0, 0}, 1, 1), true, flushed)
+	foldDeltaHistogram(s, scope, metric, histogramDataPoint([]float64{5}, []uint64{0, 2}, 7, 9), true, flushed)
+	require.Equal(t, 1, flushed.Len())
+	assert.Equal(t, []float64{1, 10}, flushed.At(0).ExplicitBounds().AsRaw())
+	assert.Equal(t, []uint64{1, 0, 0}, flushed.At(0).BucketCounts().AsRaw())
+	require.NotNil(t, s.pendingHistogram)
+	assert.Equal(t, []float64{5}, s.pendingHistogram.ExplicitBounds().AsRaw())
+}
+
+func TestSiftAggregateDeltaSum(t *testing.T) {
+	config := createDefaultConfig().(*Config)
+	config.MinPointAccumulationTime = time.Minute
+	config.ThinningMode = thinningModeAggregate
+	sieve := newTestSieve(t, config)
+	start := time.Unix(0, 0)
+
+	kept, total := 0, 0.0
+	for i := 0; i <= 10; i++ {
+		metric := sumMetric(pmetric.AggregationTemporalityDelta, minutesAfter(start, i-1), minutesAfter(start, i), 10)
+		if !sieve.Sift(testScope(), pmetric.NewMetricSlice(), metric) {
+			kept++
+			total += metric.Sum().DataPoints().At(0).DoubleValue()
+		}
+	}
+
+	assert.Equal(t, 3, kept)
+	assert.Equal(t, 110.0, total)
+}
+
+func TestSiftAggregateHistogramReportsAccumulatedOnBoundsChange(t *testing.T) {
+	config := createDefaultConfig().(*Config)
+	config.MinPointAccumulationTime = time.Minute
+	config.ThinningMode = thinningModeAggregate
+	sieve := newTestSieve(t, config)
+	start := time.Unix(3600, 0)
+
+	for i := 0; i <= 2; i++ {
+		metric := histogramMetric(pmetric.AggregationTemporalityDelta, minutesAfter(start, i-1), minutesAfter(start, i), 1, 1)
+		metric.Histogram().DataPoints().At(0).ExplicitBounds().FromRaw([]float64{1})
+		metric.Histogram().DataPoints().At(0).BucketCounts().FromRaw([]uint64{1, 0})
+		sieve.Sift(testScope(), pmetric.NewMetricSlice(), metric)
+	}
+
+	// sifted data point with new bucket boundaries, the accumulated one is reported instead
+	metric := histogramMetric(pmetric.AggregationTemporalityDelta, minutesAfter(start, 2), minutesAfter(start, 3), 1, 1)
+	metric.Histogram().DataPoints().At(0).ExplicitBounds().FromRaw([]float64{5})
+	metric.Histogram().DataPoints().At(0).BucketCounts().FromRaw([]uint64{1, 0})
+	require.False(t, sieve.Sift(testScope(), pmetric.NewMetricSlice(), metric))
+
+	require.Equal(t, 1, metric.Histogram().DataPoints().Len())
+	reported := metric.Histogram().DataPoints().At(0)
+	assert.Equal(t, []float64{1}, reported.ExplicitBounds().AsRaw())
+	assert.Equal(t, uint64(2), reported.Count())
+	assert.Equal(t, pcommon.NewTimestampFromTime(start), reported.StartTimestamp())
+	assert.Equal(t, pcommon.NewTimestampFromTime(minutesAfter(start, 2)), reported.Timestamp())
+	assert.NotNil(t, sieve.metricCache.Series(seriesKey(testScope().identity, "test", pcommon.NewMap())).pendingHistogram)
+}
+
+func TestSiftAggregateFlushesEvictedSeries(t *testing.T) {
+	config := createDefaultConfig().(*Config)
+	config.MinPointAccumulationTime = time.Minute
+	config.ThinningMode = thinningModeAggregate
+	sieve := newTestSieve(t, config)
+	start := time.Unix(3600, 0)
+
+	for i := 0; i <= 2; i++ {
+		sieve.Sift(testScope(), pmetric.NewMetricSlice(),
+			sumMetric(pmetric.AggregationTemporalityDelta, minutesAfter(start, i-1), minutesAfter(start, i), 10))
+	}
+	md := pmetric.NewMetrics()
+	sieve.Flush(md)
+	assert.Equal(t, 0, md.DataPointCount())
+
+	sieve.metricCache.internalCaches.Purge()
+	sieve.Flush(md)
+
+	require.Equal(t, 1, md.DataPointCount())
+	rm := md.ResourceMetrics().At(0)
+	assert.Equal(t, map[string]any{"host.name": "a"}, rm.Resource().Attributes().AsRaw())
+	assert.Equal(t, "scope", rm.ScopeMetrics().At(0).Scope().Name())
+	metric := rm.ScopeMetrics().At(0).Metrics().At(0)
+	assert.Equal(t, "test", metric.Name())
+	assert.Equal(t, pmetric.AggregationTemporalityDelta, metric.Sum().AggregationTemporality())
+	assert.True(t, metric.Sum().IsMonotonic())
+	dataPoint := metric.Sum().DataPoints().At(0)
+	assert.Equal(t, 20.0, dataPoint.DoubleValue())
+	assert.Equal(t, pcommon.NewTimestampFromTime(start), dataPoint.StartTimestamp())
+	assert.Equal(t, pcommon.NewTimestampFromTime(minutesAfter(start, 2)), dataPoint.Timestamp())
+
+	md = pmetric.NewMetrics()
+	sieve.Flush(md)
+	assert.Equal(t, 0, md.DataPointCount())
+}
+
+func TestSiftAggregateGaugeReportsCompanions(t *testing.T) {
+	config := createDefaultConfig().(*Config)
+	config.MinPointAccumulationTime = time.Minute
+	config.ThinningMode = thinningModeAggregate
+	sieve := newTestSieve(t, config)
+	start := time.Unix(0, 0)
+
+	companions := pmetric.NewMetricSlice()
+	for i := 0; i <= 5; i++ {
+		sieve.Sift(metricScope{}, companions, dataPointsToMetric(map[time.Time]float64{minutesAfter(start, i): 1.0}))
+	}
+
+	require.Equal(t, 3, companions.Len())
+	assert.Equal(t, "test.min", companions.At(0).Name())
+	assert.Equal(t, 1.0, companions.At(0).Gauge().DataPoints().At(0).DoubleValue())
+}
+
+func gaugeDataPoint(timestamp time.Time, value float64) pmetric.NumberDataPoint {
+	out := pmetric.NewNumberDataPoint()
+	out.SetTimestamp(pcommon.NewTimestampFromTime(timestamp))
+	out.SetDoubleValue(value)
+	return out
+}
+
+func histogramDataPoint(bounds []float64, buckets []uint64, min float64, max float64) pmetric.HistogramDataPoint {
+	out := pmetric.NewHistogramDataPoint()
+	out.ExplicitBounds().FromRaw(bounds)
+	out.BucketCounts().FromRaw(buckets)
+	count := uint64(0)
+	for _, bucket := range buckets {
+		count += bucket
+	}
+	out.SetCount(count)
+	out.SetMin(min)
+	out.SetMax(max)
+	return out
+}
+
+func testScope() metricScope {
+	resource := pcommon.NewResource()
+	resource.Attributes().PutStr("host.name", "a")
+	scope := pcommon.NewInstrumentationScope()
+	scope.SetName("scope")
+	return newMetricScope(resource, scope)
+}
@@ -0,0 +1,68 @@
+package metricfrequencyprocessor
+
+import (
+	"go.opentelemetry.io/collector/pdata/pcommon"
+
+	"github.com/SumoLogic/sumologic-otel-collector/processor/metricfrequencyprocessor/observability"
+)
+
+// categoryAttribute is set on reported data points to their series category if enabled.
+const categoryAttribute = "metric.frequency.category"
+
+// seriesCategory is the category a time series is assigned to by the sieve based on its recent data points.
+type seriesCategory int
+
+const (
+	// categoryWarmup marks series without enough data points to be categorised.
+	categoryWarmup seriesCategory = iota
+	categoryConstant
+	categoryLowInfo
+	categoryNormal
+
+	categoryCount
+)
+
+func (c seriesCategory) String() string {
+	switch c {
+	case categoryConstant:
+		return "constant"
+	case categoryLowInfo:
+		return "low_info"
+	case categoryNormal:
+		return "normal"
+	default:
+		return "warmup"
+	}
+}
+
+// categoryCounts counts occurrences per series category.
+type categoryCounts [categoryCount]int
+
+func (c *categoryCounts) recordDropped(processorID string) {
+	for category, n := range c {
+		if n > 0 {
+			observability.RecordDataPointsDroppedN(processorID, seriesCategory(category).String(), n)
+		}
+	}
+}
+
+func (c *categoryCounts) recordSeries(processorID string) {
+	for category, n := range c {
+		observability.RecordSeries(processorID, seriesCategory(category).String(), n)
+	}
+}
+
+// seriesDescription is a human readable identity of a time series, kept for debugging purposes only.
+type seriesDescription struct {
+	Metric             string         `json:"metric"`
+	ResourceAttributes map[string]any `json:"resource_attributes"`
+	Attributes         map[string]any `json:"attributes"`
+}
+
+func newSeriesDescription(resource pcommon.Resource, metricName string, attributes pcommon.Map) *seriesDescription {
+	return &seriesDescription{
+		Metric:             metricName,
+		ResourceAttributes: resource.Attributes().AsRaw(),
+		Attributes:         attributes.AsRaw(),
+	}
+}
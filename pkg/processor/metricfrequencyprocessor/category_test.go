@@ -0,0 +1,168 @@
+package metricfrequencyprocessor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func TestSiftCategorisesSeries(t *testing.T) {
+	config := createDefaultConfig().(*Config)
+	config.MinPointAccumulationTime = time.Minute
+	sieve := newTestSieve(t, config)
+	start := time.Unix(0, 0)
+
+	for i := 0; i <= 10; i++ {
+		sieve.Sift(metricScope{identity: "scope"}, pmetric.NewMetricSlice(), twoSeriesMetric(minutesAfter(start, i), 1.0, float64(100*(i%2))))
+	}
+
+	assert.Equal(t, categoryConstant, sieve.metricCache.Series(seriesKey("scope", "test", seriesAttributes("constant"))).category)
+	assert.Equal(t, categoryNormal, sieve.metricCache.Series(seriesKey("scope", "test", seriesAttributes("oscillating"))).category)
+}
+
+func TestSiftWarmup(t *testing.T) {
+	sieve := newTestSieve(t, createDefaultConfig().(*Config))
+	start := time.Unix(0, 0)
+
+	for i := 0; i <= 5; i++ {
+		sieve.Sift(metricScope{}, pmetric.NewMetricSlice(), dataPointsToMetric(map[time.Time]float64{minutesAfter(start, i): 1.0}))
+	}
+
+	assert.Equal(t, categoryWarmup, sieve.metricCache.Series(seriesKey("", "test", pcommon.NewMap())).category)
+}
+
+func TestSiftAddsCategoryAttribute(t *testing.T) {
+	config := createDefaultConfig().(*Config)
+	config.MinPointAccumulationTime = time.Minute
+	config.AddCategoryAttribute = true
+	sieve := newTestSieve(t, config)
+	start := time.Unix(0, 0)
+
+	categories := map[string]int{}
+	for i := 0; i <= 10; i++ {
+		metric := twoSeriesMetric(minutesAfter(start, i), 1.0, float64(100*(i%2)))
+		sieve.Sift(metricScope{identity: "scope"}, pmetric.NewMetricSlice(), metric)
+		for j := 0; j < metric.Gauge().DataPoints().Len(); j++ {
+			category, ok := metric.Gauge().DataPoints().At(j).Attributes().Get(categoryAttribute)
+			require.True(t, ok)
+			categories[category.Str()]++
+		}
+	}
+
+	assert.Positive(t, categories["warmup"])
+	assert.Positive(t, categories["normal"])
+	assert.Positive(t, categories["constant"])
+}
+
+func TestSiftRecordsDroppedDataPoints(t *testing.T) {
+	config := createDefaultConfig().(*Config)
+	config.MinPointAccumulationTime = time.Minute
+	sieve := newTestSieve(t, config)
+	start := time.Unix(0, 0)
+	before := droppedDataPoints(t, t.Name(), "constant")
+
+	for i := 0; i <= 10; i++ {
+		sieve.Sift(metricScope{}, pmetric.NewMetricSlice(), dataPointsToMetric(map[time.Time]float64{minutesAfter(start, i): 1.0}))
+	}
+
+	// all but the first point, the warm up one and the one past constant frequency are dropped
+	assert.Equal(t, 8.0, droppedDataPoints(t, t.Name(), "constant")-before)
+}
+
+func TestSiftCountsSeriesPerCategory(t *testing.T) {
+	config := createDefaultConfig().(*Config)
+	config.MinPointAccumulationTime = time.Minute
+	sieve := newTestSieve(t, config)
+	start := time.Unix(0, 0)
+
+	sieve.Sift(metricScope{identity: "scope"}, pmetric.NewMetricSlice(), twoSeriesMetric(start, 1.0, 0.0))
+	assert.Equal(t, int64(2), sieve.metricCache.categories[categoryWarmup].Load())
+
+	for i := 1; i <= 10; i++ {
+		sieve.Sift(metricScope{identity: "scope"}, pmetric.NewMetricSlice(), twoSeriesMetric(minutesAfter(start, i), 1.0, float64(100*(i%2))))
+	}
+	assert.Equal(t, int64(0), sieve.metricCache.categories[categoryWarmup].Load())
+	assert.Equal(t, int64(1), sieve.metricCache.categories[categoryConstant].Load())
+	assert.Equal(t, int64(1), sieve.metricCache.categories[categoryNormal].Load())
+
+	sieve.metricCache.RecordSeries()
+	assert.Equal(t, 1.0, seriesCount(t, t.Name(), "constant"))
+	assert.Equal(t, 0.0, seriesCount(t, t.Name(), "warmup"))
+
+	sieve.metricCache.internalCaches.Remove(seriesKey("scope", "test", seriesAttributes("constant")))
+	assert.Equal(t, int64(0), sieve.metricCache.categories[categoryConstant].Load())
+	assert.Equal(t, int64(1), sieve.metricCache.categories[categoryNormal].Load())
+}
+
+func TestSeriesCountsPerProcessor(t *testing.T) {
+	config := createDefaultConfig().(*Config)
+	first, err := newMetricSieve(config, "metric_frequency/first")
+	require.NoError(t, err)
+	second := newTestSieve(t, config)
+
+	first.Sift(metricScope{}, pmetric.NewMetricSlice(), dataPointsToMetric(map[time.Time]float64{time.Unix(0, 0): 1.0}))
+	first.metricCache.RecordSeries()
+	second.metricCache.RecordSeries()
+	assert.Equal(t, 1.0, seriesCount(t, "metric_frequency/first", "warmup"))
+	assert.Equal(t, 0.0, seriesCount(t, t.Name(), "warmup"))
+
+	first.Shutdown()
+	assert.Equal(t, 0.0, seriesCount(t, "metric_frequency/first", "warmup"))
+}
+
+func seriesCount(t *testing.T, processorID string, category string) float64 {
+	rows, err := view.RetrieveData("otelsvc/sumo/metric_frequency_series")
+	require.NoError(t, err)
+	for _, row := range rows {
+		if hasTags(row.Tags, processorID, category) {
+			return row.Data.(*view.LastValueData).Value
+		}
+	}
+	return -1
+}
+
+func droppedDataPoints(t *testing.T, processorID string, category string) float64 {
+	rows, err := view.RetrieveData("otelsvc/sumo/metric_frequency_data_points_dropped")
+	require.NoError(t, err)
+	for _, row := range rows {
+		if hasTags(row.Tags, processorID, category) {
+			return row.Data.(*view.SumData).Value
+		}
+	}
+	return 0
+}
+
+func hasTags(tags []tag.Tag, processorID string, category string) bool {
+	matched := 0
+	for _, t := range tags {
+		if t.Key.Name() == "processor" && t.Value == processorID || t.Key.Name() == "category" && t.Value == category {
+			matched++
+		}
+	}
+	return matched == 2
+}
+
+func twoSeriesMetric(timestamp time.Time, constantValue float64, oscillatingValue float64) pmetric.Metric {
+	metric := pmetric.NewMetric()
+	metric.SetName("test")
+	dataPoints := metric.SetEmptyGauge().DataPoints()
+	for series, value := range map[string]float64{"constant": constantValue, "oscillating": oscillatingValue} {
+		dataPoint := dataPoints.AppendEmpty()
+		seriesAttributes(series).CopyTo(dataPoint.Attributes())
+		dataPoint.SetTimestamp(pcommon.NewTimestampFromTime(timestamp))
+		dataPoint.SetDoubleValue(value)
+	}
+	return metric
+}
+
+func seriesAttributes(series string) pcommon.Map {
+	attributes := pcommon.NewMap()
+	attributes.PutStr("series", series)
+	return attributes
+}
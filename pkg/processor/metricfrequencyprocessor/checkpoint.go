@@ -0,0 +1,270 @@
+package metricfrequencyprocessor
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// checkpointVersion is bumped whenever the checkpoint format changes incompatibly.
+// Checkpoints of other versions are ignored.
+const checkpointVersion = 2
+
+// Approximate encoded sizes of type definitions, a data point and a series without its key and data points,
+// used to estimate how many data points per series fit in the maximum checkpoint size.
+const (
+	checkpointHeaderSize = 1024
+	checkpointPointSize  = 36
+	checkpointSeriesSize = 64
+)
+
+// checkpointLimits bound the size of a checkpoint.
+type checkpointLimits struct {
+	// maxPointsPerSeries is the maximum number of the most recent data points saved for a series.
+	maxPointsPerSeries int
+	// maxSize is the maximum size of an encoded checkpoint in bytes.
+	maxSize int
+}
+
+// checkpoint is a serializable snapshot of the metric cache.
+// Series are ordered from least to most recently seen, so that restoring them preserves eviction order.
+type checkpoint struct {
+	Version int
+	Series  []seriesCheckpoint
+	// Flushed holds data points removed from evicted series which were not reported yet, in OTLP protobuf format.
+	Flushed [][]byte
+}
+
+type seriesCheckpoint struct {
+	Key            string
+	DataPoints     []dataPointCheckpoint
+	LastReported   pcommon.Timestamp
+	Reported       bool
+	LastCumulative *cumulativeCheckpoint
+	Category       seriesCategory
+	// Pending holds data points removed from the series in aggregate thinning mode, in OTLP protobuf format.
+	Pending []byte
+}
+
+type dataPointCheckpoint struct {
+	Timestamp pcommon.Timestamp
+	Value     float64
+	Expires   time.Time
+}
+
+type cumulativeCheckpoint struct {
+	Start     pcommon.Timestamp
+	Timestamp pcommon.Timestamp
+	Count     float64
+	Sum       float64
+}
+
+// Checkpoint returns serialized state of all tracked series, including delta sums and histograms accumulated
+// in aggregate thinning mode. Gauge summaries are not included.
+// Only the most recent data points of each series are included, as many as fit within the limits.
+// It returns an error if the checkpoint does not fit even without data points.
+func (mc *metricCache) Checkpoint(limits checkpointLimits) ([]byte, error) {
+	keys := mc.internalCaches.Keys()
+	maxPoints := checkpointPointsPerSeries(keys, limits)
+
+	cp := checkpoint{Version: checkpointVersion}
+	for _, key := range keys {
+		s, found := mc.internalCaches.Peek(key)
+		if !found {
+			continue
+		}
+
+		s.mu.Lock()
+		sc, err := newSeriesCheckpoint(key, s, maxPoints)
+		s.mu.Unlock()
+		if err != nil {
+			return nil, err
+		}
+		cp.Series = append(cp.Series, sc)
+	}
+
+	mc.flushedMu.Lock()
+	flushed := mc.flushed
+	mc.flushedMu.Unlock()
+	for _, pending := range flushed {
+		data, err := marshalPending(pending)
+		if err != nil {
+			return nil, err
+		}
+		cp.Flushed = append(cp.Flushed, data)
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(cp); err != nil {
+		return nil, fmt.Errorf("failed to encode checkpoint: %w", err)
+	}
+	if buf.Len() > limits.maxSize {
+		return nil, fmt.Errorf("checkpoint of %d series takes %d bytes, exceeding checkpoint_max_size of %d bytes",
+			len(cp.Series), buf.Len(), limits.maxSize)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// checkpointPointsPerSeries returns the number of data points per series which can be saved,
+// so that the checkpoint of the given series is expected to fit in the maximum size.
+func checkpointPointsPerSeries(keys []string, limits checkpointLimits) int {
+	if len(keys) == 0 {
+		return limits.maxPointsPerSeries
+	}
+
+	available := limits.maxSize - checkpointHeaderSize
+	for _, key := range keys {
+		available -= len(key) + checkpointSeriesSize
+	}
+	if available <= 0 {
+		return 0
+	}
+
+	return min(limits.maxPointsPerSeries, available/(len(keys)*checkpointPointSize))
+}
+
+// newSeriesCheckpoint returns the state of the series with at most maxPoints most recent data points.
+// The caller has to hold the series lock.
+func newSeriesCheckpoint(key string, s *series, maxPoints int) (seriesCheckpoint, error) {
+	first := max(0, s.window.len()-maxPoints)
+	out := seriesCheckpoint{
+		Key:          key,
+		DataPoints:   make([]dataPointCheckpoint, 0, s.window.len()-first),
+		LastReported: s.lastReported,
+		Reported:     s.reported,
+		Category:     s.category,
+	}
+	for i := first; i < s.window.len(); i++ {
+		point := s.window.at(i)
+		out.DataPoints = append(out.DataPoints, dataPointCheckpoint{
+			Timestamp: point.Timestamp,
+			Value:     point.Value,
+			Expires:   point.expires,
+		})
+	}
+	if s.lastCumulative != nil {
+		out.LastCumulative = &cumulativeCheckpoint{
+			Start:     s.lastCumulative.start,
+			Timestamp: s.lastCumulative.timestamp,
+			Count:     s.lastCumulative.count,
+			Sum:       s.lastCumulative.sum,
+		}
+	}
+	if s.pending != nil {
+		data, err := marshalPending(*s.pending)
+		if err != nil {
+			return seriesCheckpoint{}, err
+		}
+		out.Pending = data
+	}
+
+	return out, nil
+}
+
+// Restore loads series from a checkpoint created by Checkpoint. Expired data points are skipped
+// and series already seen since start are left intact, their accumulated data points are reported on their own.
+func (mc *metricCache) Restore(data []byte) error {
+	var cp checkpoint
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&cp); err != nil {
+		return fmt.Errorf("failed to decode checkpoint: %w", err)
+	}
+	if cp.Version != checkpointVersion {
+		return fmt.Errorf("unsupported checkpoint version %d", cp.Version)
+	}
+
+	now := mc.now()
+	for _, sc := range cp.Series {
+		s := &series{
+			lastReported: sc.LastReported,
+			reported:     sc.Reported,
+			category:     sc.Category,
+		}
+		for _, point := range sc.DataPoints {
+			if now.Before(point.Expires) {
+				s.window.add(cachedDataPoint{
+					DataPoint: DataPoint{Timestamp: point.Timestamp, Value: point.Value},
+					expires:   point.Expires,
+				}, mc.config.MaxPointsPerSeries)
+			}
+		}
+		if sc.LastCumulative != nil {
+			s.lastCumulative = &cumulativePoint{
+				start:     sc.LastCumulative.Start,
+				timestamp: sc.LastCumulative.Timestamp,
+				count:     sc.LastCumulative.Count,
+				sum:       sc.LastCumulative.Sum,
+			}
+		}
+		if sc.Pending != nil {
+			if err := restorePending(s, sc.Pending); err != nil {
+				return err
+			}
+		}
+
+		if found, _ := mc.internalCaches.ContainsOrAdd(sc.Key, s); found {
+			if pending, ok := s.takePending(); ok {
+				mc.addFlushed(pending)
+			}
+		} else {
+			mc.categories[s.category].Add(1)
+		}
+	}
+
+	for _, data := range cp.Flushed {
+		pending, err := unmarshalPending(data)
+		if err != nil {
+			return err
+		}
+		mc.addFlushed(pending)
+	}
+
+	return nil
+}
+
+func marshalPending(pending pmetric.Metrics) ([]byte, error) {
+	data, err := (&pmetric.ProtoMarshaler{}).MarshalMetrics(pending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode accumulated data points: %w", err)
+	}
+	return data, nil
+}
+
+func unmarshalPending(data []byte) (pmetric.Metrics, error) {
+	pending, err := (&pmetric.ProtoUnmarshaler{}).UnmarshalMetrics(data)
+	if err != nil {
+		return pmetric.Metrics{}, fmt.Errorf("failed to decode accumulated data points: %w", err)
+	}
+	return pending, nil
+}
+
+// restorePending sets up the series to hold data points accumulated in aggregate thinning mode,
+// as created by newPendingMetric.
+func restorePending(s *series, data []byte) error {
+	pending, err := unmarshalPending(data)
+	if err != nil {
+		return err
+	}
+	if pending.DataPointCount() != 1 {
+		return fmt.Errorf("invalid accumulated data points: expected a single data point, got %d", pending.DataPointCount())
+	}
+
+	metric := pending.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0)
+	switch metric.Type() {
+	case pmetric.MetricTypeSum:
+		dataPoint := metric.Sum().DataPoints().At(0)
+		s.pendingSum = &dataPoint
+	case pmetric.MetricTypeHistogram:
+		dataPoint := metric.Histogram().DataPoints().At(0)
+		s.pendingHistogram = &dataPoint
+	default:
+		return fmt.Errorf("invalid accumulated data points: unexpected metric type %s", metric.Type())
+	}
+	s.pending = &pending
+
+	return nil
+}
@@ -0,0 +1,164 @@
+package metricfrequencyprocessor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+func TestCheckpointRoundTrip(t *testing.T) {
+	cache := newCache()
+	defer cache.Stop()
+	cache.Register("a", timestamp1, 0.0)
+	cache.Register("a", timestamp2, 1.0)
+	cache.Register("b", timestamp2, 2.0)
+	s := cache.Series("b")
+	s.report(timestamp2)
+	s.lastCumulative = &cumulativePoint{start: timestamp1, timestamp: timestamp2, count: 3, sum: 4}
+
+	data, err := cache.Checkpoint(defaultCheckpointLimits())
+	require.NoError(t, err)
+
+	restored := newCache()
+	defer restored.Stop()
+	require.NoError(t, restored.Restore(data))
+
+	assert.Equal(t, []string{"a", "b"}, restored.internalCaches.Keys())
+	assert.Equal(t, map[pcommon.Timestamp]float64{timestamp1: 0.0, timestamp2: 1.0}, restored.List("a"))
+	restoredSeries := restored.Series("b")
+	assert.True(t, restoredSeries.reported)
+	assert.Equal(t, timestamp2, restoredSeries.lastReported)
+	assert.Equal(t, s.lastCumulative, restoredSeries.lastCumulative)
+}
+
+func TestCheckpointRoundTripPendingDataPoints(t *testing.T) {
+	cache := newCache()
+	defer cache.Stop()
+	cache.Register("a", timestamp1, 0.0)
+	addPendingSum(cache.Series("a"), 5)
+	cache.Register("b", timestamp1, 0.0)
+	addPendingSum(cache.Series("b"), 7)
+	cache.internalCaches.Remove("b")
+
+	data, err := cache.Checkpoint(defaultCheckpointLimits())
+	require.NoError(t, err)
+
+	restored := newCache()
+	defer restored.Stop()
+	require.NoError(t, restored.Restore(data))
+
+	restoredSeries := restored.Series("a")
+	require.NotNil(t, restoredSeries.pendingSum)
+	assert.Equal(t, 5.0, restoredSeries.pendingSum.DoubleValue())
+	flushed := restored.TakeFlushed()
+	require.Len(t, flushed, 1)
+	assert.Equal(t, 7.0, flushed[0].ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Sum().DataPoints().At(0).DoubleValue())
+
+	// accumulated data points of series seen since start are reported on their own
+	current := newCache()
+	defer current.Stop()
+	current.Register("a", timestamp2, 1.0)
+	require.NoError(t, current.Restore(data))
+	assert.Nil(t, current.Series("a").pending)
+	assert.Len(t, current.TakeFlushed(), 2)
+}
+
+func TestCheckpointKeepsMostRecentDataPoints(t *testing.T) {
+	cache := newCache()
+	defer cache.Stop()
+	for i := 0; i < 5; i++ {
+		cache.Register("a", pcommon.Timestamp(i), float64(i))
+	}
+	cache.Series("a").report(4)
+
+	data, err := cache.Checkpoint(checkpointLimits{maxPointsPerSeries: 2, maxSize: defaultCheckpointMaxSize})
+	require.NoError(t, err)
+
+	restored := newCache()
+	defer restored.Stop()
+	require.NoError(t, restored.Restore(data))
+	assert.Equal(t, map[pcommon.Timestamp]float64{3: 3.0, 4: 4.0}, restored.List("a"))
+	assert.Equal(t, pcommon.Timestamp(4), restored.Series("a").lastReported)
+}
+
+func TestCheckpointFitsMaxSize(t *testing.T) {
+	cache := newCache()
+	defer cache.Stop()
+	for _, key := range []string{"a", "b"} {
+		for i := 0; i < 100; i++ {
+			cache.Register(key, pcommon.Timestamp(i), float64(i))
+		}
+		cache.Series(key).report(99)
+	}
+
+	limits := checkpointLimits{
+		maxPointsPerSeries: 100,
+		maxSize:            checkpointHeaderSize + 2*(1+checkpointSeriesSize+10*checkpointPointSize),
+	}
+	data, err := cache.Checkpoint(limits)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, len(data), limits.maxSize)
+
+	restored := newCache()
+	defer restored.Stop()
+	require.NoError(t, restored.Restore(data))
+	assert.Len(t, restored.List("a"), 10)
+	assert.Len(t, restored.List("b"), 10)
+
+	// reporting state of the series does not fit
+	limits.maxSize = 100
+	_, err = cache.Checkpoint(limits)
+	assert.ErrorContains(t, err, "exceeding checkpoint_max_size of 100 bytes")
+}
+
+func TestCheckpointPointsPerSeries(t *testing.T) {
+	limits := checkpointLimits{maxPointsPerSeries: 100, maxSize: checkpointHeaderSize + 1000}
+	assert.Equal(t, 100, checkpointPointsPerSeries(nil, limits))
+	assert.Equal(t, 100, checkpointPointsPerSeries([]string{"a"}, checkpointLimits{maxPointsPerSeries: 100, maxSize: 1 << 20}))
+	assert.Equal(t, (1000-2*(1+checkpointSeriesSize))/(2*checkpointPointSize), checkpointPointsPerSeries([]string{"a", "b"}, limits))
+	assert.Equal(t, 0, checkpointPointsPerSeries(make([]string, 100), limits))
+}
+
+func TestRestoreSkipsExpiredDataPoints(t *testing.T) {
+	cache := newCache()
+	defer cache.Stop()
+	cache.Register("a", timestamp1, 0.0)
+	data, err := cache.Checkpoint(defaultCheckpointLimits())
+	require.NoError(t, err)
+
+	restored := newCache()
+	defer restored.Stop()
+	restored.now = func() time.Time { return time.Now().Add(2 * restored.config.DataPointExpirationTime) }
+	require.NoError(t, restored.Restore(data))
+
+	assert.Equal(t, emptyResult, restored.List("a"))
+}
+
+func TestRestoreKeepsCurrentSeries(t *testing.T) {
+	cache := newCache()
+	defer cache.Stop()
+	cache.Register("a", timestamp1, 0.0)
+	data, err := cache.Checkpoint(defaultCheckpointLimits())
+	require.NoError(t, err)
+
+	restored := newCache()
+	defer restored.Stop()
+	restored.Register("a", timestamp2, 1.0)
+	require.NoError(t, restored.Restore(data))
+
+	assert.Equal(t, map[pcommon.Timestamp]float64{timestamp2: 1.0}, restored.List("a"))
+}
+
+func TestRestoreInvalidCheckpoint(t *testing.T) {
+	cache := newCache()
+	defer cache.Stop()
+
+	assert.ErrorContains(t, cache.Restore([]byte("invalid")), "failed to decode checkpoint")
+}
+
+func defaultCheckpointLimits() checkpointLimits {
+	return checkpointLimits{maxPointsPerSeries: defaultCheckpointMaxPointsPerSeries, maxSize: defaultCheckpointMaxSize}
+}
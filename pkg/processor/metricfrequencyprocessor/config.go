@@ -1,13 +1,74 @@
 package metricfrequencyprocessor
 
 import (
+	"fmt"
 	"time"
+
+	"go.opentelemetry.io/collector/component"
 )
 
 // Config defines configuration for metricfrequencyprocessor.
 type Config struct {
 	sieveConfig `mapstructure:",squash"`
 	cacheConfig `mapstructure:",squash"`
+
+	// Rules override report frequencies for matching metrics. The first matching rule applies.
+	Rules []MetricRuleConfig `mapstructure:"rules"`
+
+	// Storage is the ID of a storage extension used to persist processor's state between restarts.
+	// State is not persisted if it is not set.
+	Storage *component.ID `mapstructure:"storage"`
+
+	// CheckpointInterval defines how often processor's state is saved to the storage.
+	// The state is also saved on shutdown.
+	CheckpointInterval time.Duration `mapstructure:"checkpoint_interval"`
+
+	// CheckpointMaxPointsPerSeries defines maximum number of data points saved for a single time series,
+	// the most recent ones are saved. Reporting state of the series is always saved.
+	CheckpointMaxPointsPerSeries int `mapstructure:"checkpoint_max_points_per_series"`
+
+	// CheckpointMaxSize defines maximum size of the saved state in bytes. Fewer data points per series
+	// are saved if needed to fit it, and the state is not saved at all if it does not fit without them.
+	CheckpointMaxSize int `mapstructure:"checkpoint_max_size"`
+
+	// AddCategoryAttribute makes the processor set the `metric.frequency.category` attribute
+	// on reported data points to the category of their time series.
+	AddCategoryAttribute bool `mapstructure:"add_category_attribute"`
+
+	// Debug section allows to expose time series classifications over HTTP.
+	Debug DebugConfig `mapstructure:"debug"`
+}
+
+// DebugConfig allows exposing time series classifications for debugging.
+type DebugConfig struct {
+	// Endpoint is the address the debug HTTP server listens on, e.g. localhost:8090.
+	// The server is disabled when it's empty.
+	Endpoint string `mapstructure:"endpoint"`
+}
+
+// MetricRuleConfig matches metrics by name and resource attributes and overrides how they are sifted.
+type MetricRuleConfig struct {
+	// MetricName is a glob pattern matched against metric name, `*` matches any sequence of characters.
+	MetricName string `mapstructure:"metric_name"`
+
+	// MetricNameRegex is a regular expression matched against the whole metric name.
+	// It is mutually exclusive with MetricName.
+	MetricNameRegex string `mapstructure:"metric_name_regex"`
+
+	// ResourceAttributes maps resource attribute names to regular expressions their values have to match.
+	ResourceAttributes map[string]string `mapstructure:"resource_attributes"`
+
+	// Exempt disables sifting of matching metrics, all their data points are reported.
+	Exempt bool `mapstructure:"exempt"`
+
+	// ConstantMetricsReportFrequency overrides constant_metrics_report_frequency for matching metrics.
+	ConstantMetricsReportFrequency time.Duration `mapstructure:"constant_metrics_report_frequency"`
+
+	// LowInfoMetricsReportFrequency overrides low_info_metrics_report_frequency for matching metrics.
+	LowInfoMetricsReportFrequency time.Duration `mapstructure:"low_info_metrics_report_frequency"`
+
+	// MaxReportFrequency overrides max_report_frequency for matching metrics.
+	MaxReportFrequency time.Duration `mapstructure:"max_report_frequency"`
 }
 
 type sieveConfig struct {
@@ -34,6 +95,10 @@ type sieveConfig struct {
 	// I.e. if current variation v of a metric satisfies v / Iqr > VariationIqrThresholdCoef
 	// then the metric is not considered low info.
 	VariationIqrThresholdCoef float64 `mapstructure:"variation_iqr_threshold_coefficient"`
+
+	// ThinningMode defines what happens to sifted data points, either `drop` or `aggregate`.
+	// In `aggregate` mode sifted data points are folded into the next reported data point of their series.
+	ThinningMode string `mapstructure:"thinning_mode"`
 }
 
 type cacheConfig struct {
@@ -45,4 +110,47 @@ type cacheConfig struct {
 
 	// MetricCacheCleanupInterval defines how often no longer seen metrics are removed from memory.
 	MetricCacheCleanupInterval time.Duration `mapstructure:"metric_cache_cleanup_interval"`
+
+	// MaxSeries defines maximum number of time series tracked at once.
+	// When exceeded, least recently seen series are forgotten.
+	MaxSeries int `mapstructure:"max_series"`
+
+	// MaxPointsPerSeries defines maximum number of data points kept for a single time series.
+	// When exceeded, oldest data points are forgotten.
+	MaxPointsPerSeries int `mapstructure:"max_points_per_series"`
+}
+
+var _ component.Config = (*Config)(nil)
+
+// Validate checks if the processor configuration is valid.
+func (cfg *Config) Validate() error {
+	if cfg.ThinningMode != thinningModeDrop && cfg.ThinningMode != thinningModeAggregate {
+		return fmt.Errorf("thinning_mode must be either %s or %s, got %q", thinningModeDrop, thinningModeAggregate, cfg.ThinningMode)
+	}
+	if cfg.MaxSeries <= 0 {
+		return fmt.Errorf("max_series must be positive, got %d", cfg.MaxSeries)
+	}
+	if cfg.MaxPointsPerSeries <= 0 {
+		return fmt.Errorf("max_points_per_series must be positive, got %d", cfg.MaxPointsPerSeries)
+	}
+	if cfg.DataPointCacheCleanupInterval <= 0 {
+		return fmt.Errorf("data_point_cache_cleanup_interval must be positive, got %s", cfg.DataPointCacheCleanupInterval)
+	}
+	if cfg.MetricCacheCleanupInterval <= 0 {
+		return fmt.Errorf("metric_cache_cleanup_interval must be positive, got %s", cfg.MetricCacheCleanupInterval)
+	}
+	if cfg.Storage != nil && cfg.CheckpointInterval <= 0 {
+		return fmt.Errorf("checkpoint_interval must be positive, got %s", cfg.CheckpointInterval)
+	}
+	if cfg.CheckpointMaxPointsPerSeries < 0 {
+		return fmt.Errorf("checkpoint_max_points_per_series can't be negative, got %d", cfg.CheckpointMaxPointsPerSeries)
+	}
+	if cfg.Storage != nil && cfg.CheckpointMaxSize <= 0 {
+		return fmt.Errorf("checkpoint_max_size must be positive, got %d", cfg.CheckpointMaxSize)
+	}
+	if _, err := newMetricRules(cfg.Rules); err != nil {
+		return err
+	}
+
+	return nil
 }
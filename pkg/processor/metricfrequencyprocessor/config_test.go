@@ -3,6 +3,7 @@ package metricfrequencyprocessor
 import (
 	"path"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -25,4 +26,76 @@ func TestLoadConfig(t *testing.T) {
 	id := component.NewID(Type)
 
 	assert.Equal(t, cfg.Processors[id], createDefaultConfig())
+
+	expected := createDefaultConfig().(*Config)
+	expected.Rules = []MetricRuleConfig{
+		{
+			MetricName: "slo_*",
+			Exempt:     true,
+		},
+		{
+			MetricNameRegex:                "system\\..*",
+			ResourceAttributes:             map[string]string{"host.name": "noisy-.*"},
+			ConstantMetricsReportFrequency: 30 * time.Minute,
+			LowInfoMetricsReportFrequency:  10 * time.Minute,
+			MaxReportFrequency:             2 * time.Minute,
+		},
+	}
+	assert.Equal(t, expected, cfg.Processors[component.NewIDWithName(Type, "rules")])
+
+	storageID := component.MustNewID("file_storage")
+	expected = createDefaultConfig().(*Config)
+	expected.Storage = &storageID
+	expected.CheckpointInterval = 30 * time.Second
+	expected.CheckpointMaxPointsPerSeries = 50
+	expected.CheckpointMaxSize = 1 << 20
+	assert.Equal(t, expected, cfg.Processors[component.NewIDWithName(Type, "storage")])
+
+	expected = createDefaultConfig().(*Config)
+	expected.AddCategoryAttribute = true
+	expected.Debug.Endpoint = "localhost:8090"
+	assert.Equal(t, expected, cfg.Processors[component.NewIDWithName(Type, "debug")])
+}
+
+func TestValidateConfig(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	assert.NoError(t, cfg.Validate())
+
+	cfg.MaxSeries = 0
+	assert.EqualError(t, cfg.Validate(), "max_series must be positive, got 0")
+
+	cfg = createDefaultConfig().(*Config)
+	cfg.ThinningMode = "sample"
+	assert.EqualError(t, cfg.Validate(), `thinning_mode must be either drop or aggregate, got "sample"`)
+
+	cfg = createDefaultConfig().(*Config)
+	cfg.MaxPointsPerSeries = -1
+	assert.EqualError(t, cfg.Validate(), "max_points_per_series must be positive, got -1")
+
+	cfg = createDefaultConfig().(*Config)
+	cfg.MetricCacheCleanupInterval = 0
+	assert.EqualError(t, cfg.Validate(), "metric_cache_cleanup_interval must be positive, got 0s")
+
+	cfg = createDefaultConfig().(*Config)
+	storageID := component.MustNewID("file_storage")
+	cfg.Storage = &storageID
+	cfg.CheckpointInterval = 0
+	assert.EqualError(t, cfg.Validate(), "checkpoint_interval must be positive, got 0s")
+
+	cfg = createDefaultConfig().(*Config)
+	cfg.CheckpointMaxPointsPerSeries = -1
+	assert.EqualError(t, cfg.Validate(), "checkpoint_max_points_per_series can't be negative, got -1")
+
+	cfg = createDefaultConfig().(*Config)
+	cfg.Storage = &storageID
+	cfg.CheckpointMaxSize = 0
+	assert.EqualError(t, cfg.Validate(), "checkpoint_max_size must be positive, got 0")
+
+	cfg = createDefaultConfig().(*Config)
+	cfg.Rules = []MetricRuleConfig{{MetricName: "a*"}, {MetricNameRegex: "("}}
+	assert.ErrorContains(t, cfg.Validate(), "rules[1]: invalid metric name pattern")
+
+	cfg = createDefaultConfig().(*Config)
+	cfg.Rules = []MetricRuleConfig{{MetricName: "a*", MetricNameRegex: "a.*"}}
+	assert.EqualError(t, cfg.Validate(), "rules[0]: metric_name and metric_name_regex are mutually exclusive")
 }
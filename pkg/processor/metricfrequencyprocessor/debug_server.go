@@ -0,0 +1,197 @@
+package metricfrequencyprocessor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	debugClassificationsPath = "/classifications"
+	debugMetricParam         = "metric"
+)
+
+// debugServers holds the debug servers by endpoint. The same processor configuration
+// is usually used in multiple pipelines, so processors using the same endpoint share a server.
+var (
+	debugServersMu sync.Mutex
+	debugServers   = map[string]*debugServer{}
+)
+
+// classifier is implemented by sieves which can list categories of tracked time series.
+type classifier interface {
+	Classifications(metricName string) []seriesClassification
+}
+
+// seriesClassification describes the category of a single time series.
+type seriesClassification struct {
+	seriesDescription
+	Category     string    `json:"category"`
+	LastReported time.Time `json:"last_reported"`
+	DataPoints   int       `json:"data_points"`
+}
+
+// debugServer exposes time series classifications of the processors over HTTP.
+type debugServer struct {
+	logger *zap.Logger
+	server *http.Server
+
+	mu         sync.RWMutex
+	processors map[*metricsfrequencyprocessor]struct{}
+}
+
+type debugClassificationsEntry struct {
+	Processor string                 `json:"processor"`
+	Series    []seriesClassification `json:"series"`
+}
+
+type debugErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// registerDebugEndpoint adds the processor to the debug server listening on the endpoint,
+// starting the server if needed.
+func registerDebugEndpoint(endpoint string, mfp *metricsfrequencyprocessor) error {
+	debugServersMu.Lock()
+	defer debugServersMu.Unlock()
+
+	ds, ok := debugServers[endpoint]
+	if !ok {
+		listener, err := net.Listen("tcp", endpoint)
+		if err != nil {
+			return fmt.Errorf("failed to start debug endpoint on %s: %w", endpoint, err)
+		}
+		ds = newDebugServer(mfp.logger)
+		go func() {
+			if err := ds.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				ds.logger.Error("Debug endpoint failed", zap.String("endpoint", endpoint), zap.Error(err))
+			}
+		}()
+		debugServers[endpoint] = ds
+		mfp.logger.Info("Started debug endpoint", zap.String("endpoint", listener.Addr().String()))
+	}
+
+	ds.mu.Lock()
+	ds.processors[mfp] = struct{}{}
+	ds.mu.Unlock()
+	return nil
+}
+
+// unregisterDebugEndpoint removes the processor from the debug server listening on the endpoint,
+// stopping the server if it was the last one.
+func unregisterDebugEndpoint(endpoint string, mfp *metricsfrequencyprocessor) error {
+	debugServersMu.Lock()
+	defer debugServersMu.Unlock()
+
+	ds, ok := debugServers[endpoint]
+	if !ok {
+		return nil
+	}
+
+	ds.mu.Lock()
+	delete(ds.processors, mfp)
+	remaining := len(ds.processors)
+	ds.mu.Unlock()
+
+	if remaining > 0 {
+		return nil
+	}
+	delete(debugServers, endpoint)
+	return ds.server.Close()
+}
+
+func newDebugServer(logger *zap.Logger) *debugServer {
+	ds := &debugServer{
+		logger:     logger,
+		processors: map[*metricsfrequencyprocessor]struct{}{},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(debugClassificationsPath, ds.handleClassifications)
+	ds.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return ds
+}
+
+// handleClassifications returns categories of time series of the requested metric in all the processors
+func (ds *debugServer) handleClassifications(w http.ResponseWriter, r *http.Request) {
+	metricName := r.URL.Query().Get(debugMetricParam)
+	if metricName == "" {
+		ds.writeJSON(w, http.StatusBadRequest, debugErrorResponse{
+			Error: fmt.Sprintf("missing %q query parameter", debugMetricParam),
+		})
+		return
+	}
+
+	ds.mu.RLock()
+	entries := make([]debugClassificationsEntry, 0, len(ds.processors))
+	for mfp := range ds.processors {
+		c, ok := mfp.sieve.(classifier)
+		if !ok {
+			continue
+		}
+		entries = append(entries, debugClassificationsEntry{
+			Processor: mfp.debugName,
+			Series:    c.Classifications(metricName),
+		})
+	}
+	ds.mu.RUnlock()
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Processor < entries[j].Processor
+	})
+	ds.writeJSON(w, http.StatusOK, entries)
+}
+
+func (ds *debugServer) writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		ds.logger.Debug("Failed to write debug endpoint response", zap.Error(err))
+	}
+}
+
+// Classifications returns categories of tracked time series of the metric.
+func (ms *defaultMetricSieve) Classifications(metricName string) []seriesClassification {
+	return ms.metricCache.Classifications(metricName)
+}
+
+// Classifications returns categories of tracked time series of the metric.
+// Only series described by the sieve, i.e. seen while the debug endpoint is enabled, are included.
+func (mc *metricCache) Classifications(metricName string) []seriesClassification {
+	out := []seriesClassification{}
+	for _, key := range mc.internalCaches.Keys() {
+		s, found := mc.internalCaches.Peek(key)
+		if !found {
+			continue
+		}
+
+		s.mu.Lock()
+		if s.description != nil && s.description.Metric == metricName {
+			out = append(out, seriesClassification{
+				seriesDescription: *s.description,
+				Category:          s.category.String(),
+				LastReported:      reportTime(s),
+				DataPoints:        s.window.len(),
+			})
+		}
+		s.mu.Unlock()
+	}
+
+	return out
+}
+
+func reportTime(s *series) time.Time {
+	if !s.reported {
+		return time.Time{}
+	}
+	return s.lastReported.AsTime()
+}
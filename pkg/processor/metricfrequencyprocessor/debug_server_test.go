@@ -0,0 +1,80 @@
+package metricfrequencyprocessor
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+)
+
+func TestDebugServerClassifications(t *testing.T) {
+	config := createDefaultConfig().(*Config)
+	config.MinPointAccumulationTime = time.Minute
+	config.Debug.Endpoint = "localhost:0"
+	sieve := newTestSieve(t, config)
+	start := time.Unix(0, 0)
+
+	resource := pcommon.NewResource()
+	resource.Attributes().PutStr("host.name", "my-host")
+	scope := newMetricScope(resource, pcommon.NewInstrumentationScope())
+	for i := 0; i <= 10; i++ {
+		sieve.Sift(scope, pmetric.NewMetricSlice(), twoSeriesMetric(minutesAfter(start, i), 1.0, float64(100*(i%2))))
+	}
+	other := sumMetric(pmetric.AggregationTemporalityDelta, start, start, 1.0)
+	other.SetName("other")
+	sieve.Sift(scope, pmetric.NewMetricSlice(), other)
+
+	ds := newDebugServer(zap.NewNop())
+	ds.processors[&metricsfrequencyprocessor{debugName: "metric_frequency", sieve: sieve}] = struct{}{}
+
+	rec := httptest.NewRecorder()
+	ds.server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, debugClassificationsPath+"?metric=test", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var entries []debugClassificationsEntry
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &entries))
+	require.Len(t, entries, 1)
+	assert.Equal(t, "metric_frequency", entries[0].Processor)
+	require.Len(t, entries[0].Series, 2)
+
+	categories := map[any]string{}
+	for _, s := range entries[0].Series {
+		assert.Equal(t, "test", s.Metric)
+		assert.Equal(t, map[string]any{"host.name": "my-host"}, s.ResourceAttributes)
+		assert.Positive(t, s.DataPoints)
+		assert.False(t, s.LastReported.IsZero())
+		categories[s.Attributes["series"]] = s.Category
+	}
+	assert.Equal(t, map[any]string{"constant": "constant", "oscillating": "normal"}, categories)
+}
+
+func TestDebugServerClassificationsRequireMetric(t *testing.T) {
+	ds := newDebugServer(zap.NewNop())
+
+	rec := httptest.NewRecorder()
+	ds.server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, debugClassificationsPath, nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestDebugEndpointSharedByProcessors(t *testing.T) {
+	first := &metricsfrequencyprocessor{logger: zap.NewNop(), sieve: &keepAllSieve{}}
+	second := &metricsfrequencyprocessor{logger: zap.NewNop(), sieve: &keepAllSieve{}}
+	endpoint := "localhost:0"
+
+	require.NoError(t, registerDebugEndpoint(endpoint, first))
+	require.NoError(t, registerDebugEndpoint(endpoint, second))
+	assert.Len(t, debugServers[endpoint].processors, 2)
+
+	require.NoError(t, unregisterDebugEndpoint(endpoint, first))
+	assert.Contains(t, debugServers, endpoint)
+	require.NoError(t, unregisterDebugEndpoint(endpoint, second))
+	assert.NotContains(t, debugServers, endpoint)
+}
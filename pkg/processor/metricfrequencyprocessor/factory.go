@@ -22,6 +22,11 @@ const (
 	defaultDataPointExpirationTime        = 1 * time.Hour
 	defaultDataPointCacheCleanupInterval  = 10 * time.Minute
 	defaultMetricCacheCleanupInterval     = 3 * time.Hour
+	defaultMaxSeries                      = 100000
+	defaultMaxPointsPerSeries             = 1000
+	defaultCheckpointInterval             = 1 * time.Minute
+	defaultCheckpointMaxPointsPerSeries   = 100
+	defaultCheckpointMaxSize              = 64 << 20
 	stabilityLevel                        = component.StabilityLevelBeta
 )
 
@@ -37,19 +42,25 @@ func NewFactory() processor.Factory {
 
 func createDefaultConfig() component.Config {
 	return &Config{
-		sieveConfig{
+		sieveConfig: sieveConfig{
 			MinPointAccumulationTime:       defaultMinPointAccumulationTime,
 			ConstantMetricsReportFrequency: defaultConstantMetricsReportFrequency,
 			LowInfoMetricsReportFrequency:  defaultLowInfoMetricsReportFrequency,
 			MaxReportFrequency:             defaultMaxReportFrequency,
 			IqrAnomalyCoef:                 defaultIqrAnomalyCoef,
 			VariationIqrThresholdCoef:      defaultVariationIqrThresholdCoef,
+			ThinningMode:                   thinningModeDrop,
 		},
-		cacheConfig{
+		cacheConfig: cacheConfig{
 			DataPointExpirationTime:       defaultDataPointExpirationTime,
 			DataPointCacheCleanupInterval: defaultDataPointCacheCleanupInterval,
 			MetricCacheCleanupInterval:    defaultMetricCacheCleanupInterval,
+			MaxSeries:                     defaultMaxSeries,
+			MaxPointsPerSeries:            defaultMaxPointsPerSeries,
 		},
+		CheckpointInterval:           defaultCheckpointInterval,
+		CheckpointMaxPointsPerSeries: defaultCheckpointMaxPointsPerSeries,
+		CheckpointMaxSize:            defaultCheckpointMaxSize,
 	}
 }
 
@@ -59,8 +70,28 @@ func createMetricsProcessor(
 	cfg component.Config,
 	nextConsumer consumer.Metrics,
 ) (processor.Metrics, error) {
+	config := cfg.(*Config)
+	sieve, err := newMetricSieve(config, params.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
 	var internalProcessor = &metricsfrequencyprocessor{
-		sieve: newMetricSieve(cfg.(*Config)),
+		sieve:              sieve,
+		id:                 params.ID,
+		logger:             params.Logger,
+		storageID:          config.Storage,
+		checkpointInterval: config.CheckpointInterval,
+		debugEndpoint:      config.Debug.Endpoint,
+		debugName:          params.ID.String(),
 	}
-	return processorhelper.NewMetrics(ctx, params, cfg, nextConsumer, internalProcessor.ProcessMetrics)
+	return processorhelper.NewMetrics(
+		ctx,
+		params,
+		cfg,
+		nextConsumer,
+		internalProcessor.ProcessMetrics,
+		processorhelper.WithStart(internalProcessor.Start),
+		processorhelper.WithShutdown(internalProcessor.Shutdown),
+	)
 }
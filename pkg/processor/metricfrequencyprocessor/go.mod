@@ -3,14 +3,18 @@ module github.com/SumoLogic/sumologic-otel-collector/processor/metricfrequencypr
 go 1.25.0
 
 require (
-	github.com/patrickmn/go-cache v2.1.0+incompatible
+	github.com/hashicorp/golang-lru/v2 v2.0.7
+	github.com/open-telemetry/opentelemetry-collector-contrib/pkg/pdatautil v0.155.0
 	github.com/stretchr/testify v1.11.1
+	go.opencensus.io v0.24.0
 	go.opentelemetry.io/collector/component v1.61.0
 	go.opentelemetry.io/collector/consumer v1.61.0
+	go.opentelemetry.io/collector/extension/xextension v0.155.0
 	go.opentelemetry.io/collector/otelcol/otelcoltest v0.155.0
 	go.opentelemetry.io/collector/pdata v1.61.0
 	go.opentelemetry.io/collector/processor v1.61.0
 	go.opentelemetry.io/collector/processor/processorhelper v0.155.0
+	go.uber.org/zap v1.28.0
 )
 
 require (
@@ -112,7 +116,6 @@ require (
 	go.opentelemetry.io/otel/trace v1.44.0 // indirect
 	go.opentelemetry.io/proto/otlp v1.10.0 // indirect
 	go.uber.org/multierr v1.11.0 // indirect
-	go.uber.org/zap v1.28.0 // indirect
 	go.yaml.in/yaml/v3 v3.0.4 // indirect
 	golang.org/x/exp v0.0.0-20260527015227-08cc5374adb3 // indirect
 	golang.org/x/net v0.56.0 // indirect
@@ -0,0 +1,54 @@
+package metricfrequencyprocessor
+
+import (
+	"strings"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/pdatautil"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+const identitySeparator = "\x00"
+
+// metricScope describes resource and instrumentation scope shared by a slice of metrics.
+type metricScope struct {
+	identity string
+	resource pcommon.Resource
+	scope    pcommon.InstrumentationScope
+}
+
+func newMetricScope(resource pcommon.Resource, scope pcommon.InstrumentationScope) metricScope {
+	return metricScope{
+		identity: scopeIdentity(resource, scope),
+		resource: resource,
+		scope:    scope,
+	}
+}
+
+// scopeIdentity returns a stable identity of a resource and instrumentation scope pair.
+// It is computed once per scope and shared by all metrics within it.
+func scopeIdentity(resource pcommon.Resource, scope pcommon.InstrumentationScope) string {
+	resourceHash := pdatautil.MapHash(resource.Attributes())
+	scopeHash := pdatautil.MapHash(scope.Attributes())
+
+	var b strings.Builder
+	b.Write(resourceHash[:])
+	b.Write(scopeHash[:])
+	b.WriteString(scope.Name())
+	b.WriteString(identitySeparator)
+	b.WriteString(scope.Version())
+	return b.String()
+}
+
+// seriesKey returns a stable identity of a single time series,
+// i.e. a data point attribute set of a metric within a given scope.
+func seriesKey(scope string, metricName string, attributes pcommon.Map) string {
+	attributesHash := pdatautil.MapHash(attributes)
+
+	var b strings.Builder
+	b.WriteString(scope)
+	b.WriteString(identitySeparator)
+	b.WriteString(metricName)
+	b.WriteString(identitySeparator)
+	b.Write(attributesHash[:])
+	return b.String()
+}
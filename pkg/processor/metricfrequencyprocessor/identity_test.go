@@ -0,0 +1,34 @@
+package metricfrequencyprocessor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+func TestScopeIdentity(t *testing.T) {
+	newScope := func(host string, name string) string {
+		resource := pcommon.NewResource()
+		resource.Attributes().PutStr("host.name", host)
+		scope := pcommon.NewInstrumentationScope()
+		scope.SetName(name)
+		return scopeIdentity(resource, scope)
+	}
+
+	assert.Equal(t, newScope("host-1", "lib"), newScope("host-1", "lib"))
+	assert.NotEqual(t, newScope("host-1", "lib"), newScope("host-2", "lib"))
+	assert.NotEqual(t, newScope("host-1", "lib"), newScope("host-1", "other-lib"))
+}
+
+func TestSeriesKey(t *testing.T) {
+	newKey := func(metricName string, attributes map[string]any) string {
+		m := pcommon.NewMap()
+		assert.NoError(t, m.FromRaw(attributes))
+		return seriesKey("scope", metricName, m)
+	}
+
+	assert.Equal(t, newKey("m", map[string]any{"a": "1", "b": "2"}), newKey("m", map[string]any{"b": "2", "a": "1"}))
+	assert.NotEqual(t, newKey("m", map[string]any{"a": "1"}), newKey("m", map[string]any{"a": "2"}))
+	assert.NotEqual(t, newKey("m", map[string]any{"a": "1"}), newKey("n", map[string]any{"a": "1"}))
+}
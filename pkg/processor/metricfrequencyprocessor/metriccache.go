@@ -2,81 +2,318 @@ package metricfrequencyprocessor
 
 import (
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/patrickmn/go-cache"
+	lru "github.com/hashicorp/golang-lru/v2"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	"github.com/SumoLogic/sumologic-otel-collector/processor/metricfrequencyprocessor/observability"
 )
 
+// seriesRecordInterval defines how often the numbers of tracked series per category are recorded.
+const seriesRecordInterval = 10 * time.Second
+
 type DataPoint struct {
 	Timestamp pcommon.Timestamp
 	Value     float64
 }
 
+type cachedDataPoint struct {
+	DataPoint
+	expires time.Time
+}
+
+// series keeps recent data points and reporting state of a single time series.
+// All fields are guarded by mu.
+type series struct {
+	mu sync.Mutex
+
+	window window
+
+	lastReported   pcommon.Timestamp
+	reported       bool
+	lastCumulative *cumulativePoint
+	category       seriesCategory
+	// evicted is set once the series is no longer tracked, so that it's not counted in its category anymore
+	evicted bool
+
+	// description is kept only if the debug endpoint is enabled
+	description *seriesDescription
+
+	// state of data points removed in aggregate thinning mode, pendingSum and pendingHistogram point into pending
+	gaugeWindow      *gaugeWindow
+	pending          *pmetric.Metrics
+	pendingSum       *pmetric.NumberDataPoint
+	pendingHistogram *pmetric.HistogramDataPoint
+}
+
 // metricCache caches data points into two level mapping structure.
-// To easily list all data points of a given metric it keeps a separate cache for each incoming metric.
+// To easily list all data points of a given time series it keeps a separate cache for each incoming series.
+// The number of tracked series is bounded by MaxSeries, least recently seen series are evicted first.
+// The number of data points kept for a single series is bounded by MaxPointsPerSeries, oldest are evicted first.
+// Data points removed from evicted series in aggregate thinning mode are kept until taken with TakeFlushed.
+// metricCache is safe for concurrent use.
 type metricCache struct {
-	config cacheConfig
+	config      cacheConfig
+	processorID string
+	now         func() time.Time
 
-	internalCaches map[string]*cache.Cache
+	internalCaches *lru.Cache[string, *series]
+
+	// categories counts tracked series per category, it is kept up to date as series change their categories
+	categories [categoryCount]atomic.Int64
+
+	flushedMu sync.Mutex
+	flushed   []pmetric.Metrics
+
+	done     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
 }
 
-func newMetricCache(config cacheConfig) *metricCache {
+func newMetricCache(config cacheConfig, processorID string) *metricCache {
 	c := &metricCache{
-		config:         config,
-		internalCaches: make(map[string]*cache.Cache),
+		config:      config,
+		processorID: processorID,
+		now:         time.Now,
+		done:        make(chan struct{}),
 	}
 
-	go func(c *metricCache) {
-		t := time.NewTicker(c.config.MetricCacheCleanupInterval)
-		defer t.Stop()
-		for range t.C {
-			c.Cleanup()
-		}
-	}(c)
+	internalCaches, err := lru.NewWithEvict[string, *series](config.MaxSeries, c.onEvict)
+	if err != nil {
+		panic(fmt.Sprintf("invalid max_series: %v", err))
+	}
+	c.internalCaches = internalCaches
+
+	c.wg.Add(1)
+	go c.cleanupLoop()
 
 	return c
 }
 
-func (mc *metricCache) Register(name string, dataPoint pmetric.NumberDataPoint) {
+func (mc *metricCache) cleanupLoop() {
+	defer mc.wg.Done()
+
+	dataPointTicker := time.NewTicker(mc.config.DataPointCacheCleanupInterval)
+	defer dataPointTicker.Stop()
+	metricTicker := time.NewTicker(mc.config.MetricCacheCleanupInterval)
+	defer metricTicker.Stop()
+	seriesTicker := time.NewTicker(seriesRecordInterval)
+	defer seriesTicker.Stop()
+
+	for {
+		select {
+		case <-dataPointTicker.C:
+			mc.DeleteExpired()
+		case <-metricTicker.C:
+			mc.Cleanup()
+		case <-seriesTicker.C:
+			mc.RecordSeries()
+		case <-mc.done:
+			return
+		}
+	}
+}
+
+// Stop stops background cleanup and waits for it to finish. It is safe to call it multiple times.
+// The numbers of tracked series are recorded as zero, as the last recorded values would be reported otherwise.
+func (mc *metricCache) Stop() {
+	mc.stopOnce.Do(func() {
+		close(mc.done)
+		mc.wg.Wait()
+
+		var categories categoryCounts
+		categories.recordSeries(mc.processorID)
+	})
+}
+
+// Series returns state of the time series identified by key, creating it if it does not exist yet.
+// The caller has to hold the series lock while accessing it.
+func (mc *metricCache) Series(key string) *series {
+	s, exists := mc.internalCaches.Get(key)
+	if exists {
+		return s
+	}
+
+	s = &series{}
+	previous, exists, evicted := mc.internalCaches.PeekOrAdd(key, s)
+	if exists {
+		return previous
+	}
+	mc.categories[categoryWarmup].Add(1)
+	if evicted {
+		observability.RecordSeriesEvictedN(observability.ReasonMaxSeries, 1)
+	}
+
+	return s
+}
+
+// setCategory changes the category of the series, keeping the numbers of series per category up to date.
+// The caller has to hold the series lock.
+func (mc *metricCache) setCategory(s *series, category seriesCategory) {
+	if s.category == category {
+		return
+	}
+
+	if !s.evicted {
+		mc.categories[s.category].Add(-1)
+		mc.categories[category].Add(1)
+	}
+	s.category = category
+}
+
+// RecordSeries records the numbers of tracked series per category.
+func (mc *metricCache) RecordSeries() {
+	var categories categoryCounts
+	for category := range categories {
+		categories[category] = int(mc.categories[category].Load())
+	}
+	categories.recordSeries(mc.processorID)
+}
+
+func (mc *metricCache) Register(key string, timestamp pcommon.Timestamp, value float64) {
+	s := mc.Series(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	mc.register(s, timestamp, value)
+}
+
+func (mc *metricCache) List(key string) map[pcommon.Timestamp]float64 {
+	s, found := mc.internalCaches.Peek(key)
+	if !found {
+		return make(map[pcommon.Timestamp]float64)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	internalCache, exists := mc.internalCaches[name]
-	if !exists {
-		newCache := mc.newCache()
-		mc.internalCaches[name] = newCache
-		internalCache = newCache
+	return mc.list(s)
+}
+
+// register adds a data point to the series, replacing one with the same timestamp. The caller has to hold the series lock.
+func (mc *metricCache) register(s *series, timestamp pcommon.Timestamp, value float64) {
+	point := cachedDataPoint{
+		DataPoint: DataPoint{Timestamp: timestamp, Value: value},
+		expires:   mc.now().Add(mc.config.DataPointExpirationTime),
 	}
 
-	key := dataPoint.Timestamp().String()
-	value := &DataPoint{Timestamp: dataPoint.Timestamp(), Value: getVal(dataPoint)}
-	internalCache.Set(key, value, cache.DefaultExpiration)
+	if evicted := s.window.add(point, mc.config.MaxPointsPerSeries); evicted > 0 {
+		observability.RecordDataPointsEvictedN(observability.ReasonMaxPoints, evicted)
+	}
 }
 
-func (mc *metricCache) List(metricName string) map[pcommon.Timestamp]float64 {
-	out := make(map[pcommon.Timestamp]float64)
-	internalCache, found := mc.internalCaches[metricName]
-	if found {
-		for _, item := range internalCache.Items() {
-			dataPoint, ok := item.Object.(*DataPoint)
-			if !ok {
-				panic(fmt.Sprintf("item.Object is not a DataPoint but a %T", item.Object))
-			}
-			out[dataPoint.Timestamp] = dataPoint.Value
+// list returns not expired data points of the series. The caller has to hold the series lock.
+func (mc *metricCache) list(s *series) map[pcommon.Timestamp]float64 {
+	now := mc.now()
+	out := make(map[pcommon.Timestamp]float64, s.window.len())
+	for i := 0; i < s.window.len(); i++ {
+		if point := s.window.at(i); now.Before(point.expires) {
+			out[point.Timestamp] = point.Value
 		}
 	}
 
 	return out
 }
 
+// expireOldest removes expired data points from the beginning of the series window, so that they are not taken into
+// account when categorising the series. The caller has to hold the series lock.
+func (mc *metricCache) expireOldest(s *series) {
+	if expired := s.window.expireOldest(mc.now()); expired > 0 {
+		observability.RecordDataPointsEvictedN(observability.ReasonExpired, expired)
+	}
+}
+
+// deleteExpired removes expired data points of the series and returns their number.
+// The caller has to hold the series lock.
+func (mc *metricCache) deleteExpired(s *series) int {
+	return s.window.deleteExpired(mc.now())
+}
+
+// DeleteExpired removes expired data points from all series.
+func (mc *metricCache) DeleteExpired() {
+	expired := 0
+	for _, key := range mc.internalCaches.Keys() {
+		s, found := mc.internalCaches.Peek(key)
+		if !found {
+			continue
+		}
+
+		s.mu.Lock()
+		expired += mc.deleteExpired(s)
+		s.mu.Unlock()
+	}
+
+	if expired > 0 {
+		observability.RecordDataPointsEvictedN(observability.ReasonExpired, expired)
+	}
+}
+
+// Cleanup removes stale series, i.e. the ones with all data points expired.
 func (mc *metricCache) Cleanup() {
-	for key, internalCache := range mc.internalCaches {
-		if internalCache.ItemCount() == 0 {
-			delete(mc.internalCaches, key)
+	stale, expired := 0, 0
+	for _, key := range mc.internalCaches.Keys() {
+		s, found := mc.internalCaches.Peek(key)
+		if !found {
+			continue
+		}
+
+		s.mu.Lock()
+		expired += mc.deleteExpired(s)
+		isStale := s.window.len() == 0
+		s.mu.Unlock()
+
+		// the series lock is released first, as it is taken by onEvict
+		if isStale {
+			mc.internalCaches.Remove(key)
+			stale++
 		}
 	}
+
+	if expired > 0 {
+		observability.RecordDataPointsEvictedN(observability.ReasonExpired, expired)
+	}
+	if stale > 0 {
+		observability.RecordSeriesEvictedN(observability.ReasonStale, stale)
+	}
+}
+
+// onEvict stops counting the evicted series in its category and keeps data points removed from it,
+// so that they are reported on their own.
+// It is called by the LRU cache for both evicted and removed series, without the cache lock held.
+func (mc *metricCache) onEvict(_ string, s *series) {
+	s.mu.Lock()
+	s.evicted = true
+	mc.categories[s.category].Add(-1)
+	pending, ok := s.takePending()
+	s.mu.Unlock()
+
+	if ok {
+		mc.addFlushed(pending)
+	}
+}
+
+func (mc *metricCache) addFlushed(pending pmetric.Metrics) {
+	mc.flushedMu.Lock()
+	defer mc.flushedMu.Unlock()
+
+	mc.flushed = append(mc.flushed, pending)
+}
+
+// TakeFlushed returns data points removed from evicted series and forgets them.
+func (mc *metricCache) TakeFlushed() []pmetric.Metrics {
+	mc.flushedMu.Lock()
+	defer mc.flushedMu.Unlock()
+
+	flushed := mc.flushed
+	mc.flushed = nil
+	return flushed
 }
 
-func (mc *metricCache) newCache() *cache.Cache {
-	return cache.New(mc.config.DataPointExpirationTime, mc.config.DataPointCacheCleanupInterval)
+func (s *series) report(timestamp pcommon.Timestamp) {
+	s.lastReported = timestamp
+	s.reported = true
 }
@@ -1,10 +1,13 @@
 package metricfrequencyprocessor
 
 import (
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/pmetric"
 )
@@ -19,7 +22,7 @@ func TestEmptyRead(t *testing.T) {
 
 func TestSingleRegister(t *testing.T) {
 	cache := newCache()
-	cache.Register("a", newDataPoint(timestamp1, 0.0))
+	cache.Register("a", timestamp1, 0.0)
 
 	result := cache.List("a")
 
@@ -28,8 +31,8 @@ func TestSingleRegister(t *testing.T) {
 
 func TestTwoRegistersOfSingleMetric(t *testing.T) {
 	cache := newCache()
-	cache.Register("a", newDataPoint(timestamp1, 0.0))
-	cache.Register("a", newDataPoint(timestamp2, 1.0))
+	cache.Register("a", timestamp1, 0.0)
+	cache.Register("a", timestamp2, 1.0)
 
 	result := cache.List("a")
 
@@ -38,8 +41,8 @@ func TestTwoRegistersOfSingleMetric(t *testing.T) {
 
 func TestTwoRegistersOnTwoMetrics(t *testing.T) {
 	cache := newCache()
-	cache.Register("a", newDataPoint(timestamp1, 0.0))
-	cache.Register("b", newDataPoint(timestamp2, 1.0))
+	cache.Register("a", timestamp1, 0.0)
+	cache.Register("b", timestamp2, 1.0)
 
 	result1 := cache.List("a")
 	result2 := cache.List("b")
@@ -48,17 +51,117 @@ func TestTwoRegistersOnTwoMetrics(t *testing.T) {
 	assert.Equal(t, map[pcommon.Timestamp]float64{timestamp2: 1.0}, result2)
 }
 
+func TestMaxSeries(t *testing.T) {
+	config := createDefaultConfig().(*Config).cacheConfig
+	config.MaxSeries = 1
+	cache := newMetricCache(config, t.Name())
+	defer cache.Stop()
+	cache.Register("a", timestamp1, 0.0)
+	cache.Register("b", timestamp2, 1.0)
+
+	assert.Equal(t, emptyResult, cache.List("a"))
+	assert.Equal(t, map[pcommon.Timestamp]float64{timestamp2: 1.0}, cache.List("b"))
+}
+
+func TestCleanupRemovesStaleSeries(t *testing.T) {
+	cache := newCache()
+	now := time.Unix(0, 0)
+	cache.now = func() time.Time { return now }
+	cache.Register("a", timestamp1, 0.0)
+	cache.Series("a").report(timestamp1)
+
+	now = now.Add(2 * cache.config.DataPointExpirationTime)
+	cache.Cleanup()
+
+	assert.False(t, cache.Series("a").reported)
+}
+
+func TestCleanupFlushesPendingDataPoints(t *testing.T) {
+	cache := newCache()
+	now := time.Unix(0, 0)
+	cache.now = func() time.Time { return now }
+	cache.Register("a", timestamp1, 0.0)
+	addPendingSum(cache.Series("a"), 5)
+
+	now = now.Add(2 * cache.config.DataPointExpirationTime)
+	cache.Cleanup()
+
+	flushed := cache.TakeFlushed()
+	require.Len(t, flushed, 1)
+	assert.Equal(t, 5.0, flushed[0].ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Sum().DataPoints().At(0).DoubleValue())
+	assert.Nil(t, cache.Series("a").pending)
+	assert.Empty(t, cache.TakeFlushed())
+}
+
+func TestDataPointsExpire(t *testing.T) {
+	cache := newCache()
+	now := time.Unix(0, 0)
+	cache.now = func() time.Time { return now }
+	cache.Register("a", timestamp1, 0.0)
+	now = now.Add(cache.config.DataPointExpirationTime / 2)
+	cache.Register("a", timestamp2, 1.0)
+
+	now = now.Add(cache.config.DataPointExpirationTime / 2)
+	assert.Equal(t, map[pcommon.Timestamp]float64{timestamp2: 1.0}, cache.List("a"))
+
+	cache.DeleteExpired()
+	assert.Equal(t, 1, cache.Series("a").window.len())
+}
+
+func TestMaxPointsPerSeries(t *testing.T) {
+	config := createDefaultConfig().(*Config).cacheConfig
+	config.MaxPointsPerSeries = 2
+	cache := newMetricCache(config, t.Name())
+	defer cache.Stop()
+	timestamp3 := pcommon.NewTimestampFromTime(time.Unix(2, 0))
+	cache.Register("a", timestamp1, 0.0)
+	cache.Register("a", timestamp2, 1.0)
+	cache.Register("a", timestamp2, 2.0)
+	cache.Register("a", timestamp3, 3.0)
+
+	assert.Equal(t, map[pcommon.Timestamp]float64{timestamp2: 2.0, timestamp3: 3.0}, cache.List("a"))
+}
+
+func TestConcurrentAccess(t *testing.T) {
+	cache := newCache()
+	defer cache.Stop()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				key := fmt.Sprintf("series-%d", j%10)
+				cache.Register(key, pcommon.NewTimestampFromTime(time.Unix(int64(i*100+j), 0)), float64(j))
+				cache.List(key)
+				if j%25 == 0 {
+					cache.Cleanup()
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 10, cache.internalCaches.Len())
+}
+
+func TestStop(t *testing.T) {
+	cache := newCache()
+	cache.Stop()
+	cache.Stop()
+}
+
 var emptyResult = make(map[pcommon.Timestamp]float64)
 var timestamp1 = pcommon.NewTimestampFromTime(time.Unix(0, 0))
 var timestamp2 = pcommon.NewTimestampFromTime(time.Unix(1, 0))
 
 func newCache() *metricCache {
-	return newMetricCache(createDefaultConfig().(*Config).cacheConfig)
+	return newMetricCache(createDefaultConfig().(*Config).cacheConfig, "test")
 }
 
-func newDataPoint(timestamp pcommon.Timestamp, value float64) pmetric.NumberDataPoint {
-	result := pmetric.NewNumberDataPoint()
-	result.SetTimestamp(timestamp)
-	result.SetDoubleValue(value)
-	return result
+// addPendingSum sets up the series as if a delta sum data point was removed in aggregate thinning mode.
+func addPendingSum(s *series, value float64) {
+	metric := sumMetric(pmetric.AggregationTemporalityDelta, time.Unix(0, 0), time.Unix(60, 0), value)
+	foldDeltaSum(s, testScope(), metric, metric.Sum().DataPoints().At(0), true)
 }
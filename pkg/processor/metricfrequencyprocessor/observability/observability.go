@@ -0,0 +1,115 @@
+package observability
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+const (
+	// ReasonMaxSeries marks series evicted because max_series limit was reached.
+	ReasonMaxSeries = "max_series"
+	// ReasonStale marks series evicted because all their data points expired.
+	ReasonStale = "stale"
+	// ReasonMaxPoints marks data points evicted because max_points_per_series limit was reached.
+	ReasonMaxPoints = "max_points"
+	// ReasonExpired marks data points evicted because they were older than data_point_expiration_time.
+	ReasonExpired = "expired"
+)
+
+func init() {
+	err := view.Register(
+		viewSeriesEvicted,
+		viewDataPointsEvicted,
+		viewSeries,
+		viewDataPointsDropped,
+	)
+	if err != nil {
+		fmt.Printf("Error registering metric frequency processor's views: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+var (
+	mSeriesEvicted     = stats.Int64("otelsvc/sumo/metric_frequency_series_evicted", "Number of time series evicted from the metric frequency processor cache", "1")
+	mDataPointsEvicted = stats.Int64("otelsvc/sumo/metric_frequency_data_points_evicted", "Number of data points evicted from the metric frequency processor cache", "1")
+	mSeries            = stats.Int64("otelsvc/sumo/metric_frequency_series", "Number of time series in the metric frequency processor cache", "1")
+	mDataPointsDropped = stats.Int64("otelsvc/sumo/metric_frequency_data_points_dropped", "Number of data points dropped by the metric frequency processor", "1")
+
+	tagReason    = tag.MustNewKey("reason")
+	tagCategory  = tag.MustNewKey("category")
+	tagProcessor = tag.MustNewKey("processor")
+)
+
+var viewSeriesEvicted = &view.View{
+	Name:        mSeriesEvicted.Name(),
+	Description: mSeriesEvicted.Description(),
+	Measure:     mSeriesEvicted,
+	TagKeys:     []tag.Key{tagReason},
+	Aggregation: view.Sum(),
+}
+
+var viewDataPointsEvicted = &view.View{
+	Name:        mDataPointsEvicted.Name(),
+	Description: mDataPointsEvicted.Description(),
+	Measure:     mDataPointsEvicted,
+	TagKeys:     []tag.Key{tagReason},
+	Aggregation: view.Sum(),
+}
+
+var viewSeries = &view.View{
+	Name:        mSeries.Name(),
+	Description: mSeries.Description(),
+	Measure:     mSeries,
+	TagKeys:     []tag.Key{tagProcessor, tagCategory},
+	Aggregation: view.LastValue(),
+}
+
+var viewDataPointsDropped = &view.View{
+	Name:        mDataPointsDropped.Name(),
+	Description: mDataPointsDropped.Description(),
+	Measure:     mDataPointsDropped,
+	TagKeys:     []tag.Key{tagProcessor, tagCategory},
+	Aggregation: view.Sum(),
+}
+
+// RecordSeriesEvictedN increments the metric that records time series evicted for the given reason
+func RecordSeriesEvictedN(reason string, n int) {
+	_ = stats.RecordWithTags(
+		context.Background(),
+		[]tag.Mutator{tag.Upsert(tagReason, reason)},
+		mSeriesEvicted.M(int64(n)),
+	)
+}
+
+// RecordDataPointsEvictedN increments the metric that records data points evicted for the given reason
+func RecordDataPointsEvictedN(reason string, n int) {
+	_ = stats.RecordWithTags(
+		context.Background(),
+		[]tag.Mutator{tag.Upsert(tagReason, reason)},
+		mDataPointsEvicted.M(int64(n)),
+	)
+}
+
+// RecordSeries records the number of time series of the given category in the cache of the given processor
+func RecordSeries(processor string, category string, n int) {
+	_ = stats.RecordWithTags(
+		context.Background(),
+		[]tag.Mutator{tag.Upsert(tagProcessor, processor), tag.Upsert(tagCategory, category)},
+		mSeries.M(int64(n)),
+	)
+}
+
+// RecordDataPointsDroppedN increments the metric that records data points of series of the given category dropped
+// by the sieve of the given processor
+func RecordDataPointsDroppedN(processor string, category string, n int) {
+	_ = stats.RecordWithTags(
+		context.Background(),
+		[]tag.Mutator{tag.Upsert(tagProcessor, processor), tag.Upsert(tagCategory, category)},
+		mDataPointsDropped.M(int64(n)),
+	)
+}
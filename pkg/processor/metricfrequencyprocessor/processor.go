@@ -2,17 +2,89 @@ package metricfrequencyprocessor
 
 import (
 	"context"
+	"sync"
+	"time"
 
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension/xextension/storage"
 	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/processor/processorhelper"
+	"go.uber.org/zap"
 )
 
 type metricsfrequencyprocessor struct {
 	sieve metricSieve
+
+	id                 component.ID
+	logger             *zap.Logger
+	storageID          *component.ID
+	checkpointInterval time.Duration
+	debugEndpoint      string
+	debugName          string
+
+	storage storage.Client
+	done    chan struct{}
+	wg      sync.WaitGroup
 }
 
 var _ processorhelper.ProcessMetricsFunc = (*metricsfrequencyprocessor)(nil).ProcessMetrics
 
+// Start restores the sieve state from the configured storage extension and starts periodic checkpointing.
+// It also starts the debug endpoint if configured.
+func (mfp *metricsfrequencyprocessor) Start(ctx context.Context, host component.Host) error {
+	if mfp.debugEndpoint != "" {
+		if err := registerDebugEndpoint(mfp.debugEndpoint, mfp); err != nil {
+			return err
+		}
+	}
+
+	sieve, ok := mfp.sieve.(checkpointer)
+	if mfp.storageID == nil || !ok {
+		return nil
+	}
+
+	client, err := getStorageClient(ctx, host, *mfp.storageID, mfp.id)
+	if err != nil {
+		return err
+	}
+	mfp.storage = client
+	mfp.restoreCheckpoint(ctx, sieve)
+
+	mfp.done = make(chan struct{})
+	mfp.wg.Add(1)
+	go mfp.checkpointLoop(sieve, mfp.checkpointInterval)
+
+	return nil
+}
+
+// Shutdown stops background work of the processor and saves the final checkpoint.
+func (mfp *metricsfrequencyprocessor) Shutdown(ctx context.Context) error {
+	var err error
+	if mfp.debugEndpoint != "" {
+		err = unregisterDebugEndpoint(mfp.debugEndpoint, mfp)
+	}
+
+	mfp.sieve.Shutdown()
+	if mfp.storage == nil {
+		return err
+	}
+
+	close(mfp.done)
+	mfp.wg.Wait()
+
+	if sieve, ok := mfp.sieve.(checkpointer); ok {
+		if saveErr := mfp.saveCheckpoint(ctx, sieve); err == nil {
+			err = saveErr
+		}
+	}
+	if closeErr := mfp.storage.Close(ctx); err == nil {
+		err = closeErr
+	}
+	mfp.storage = nil
+
+	return err
+}
+
 // ProcessMetrics applies metricSieve to incoming metrics. It mutates the argument.
 func (mfp *metricsfrequencyprocessor) ProcessMetrics(_ context.Context, md pmetric.Metrics) (pmetric.Metrics, error) {
 	rms := md.ResourceMetrics()
@@ -21,12 +93,19 @@ func (mfp *metricsfrequencyprocessor) ProcessMetrics(_ context.Context, md pmetr
 		sms := rm.ScopeMetrics()
 		for j := 0; j < sms.Len(); j++ {
 			ilm := sms.At(j)
-			metrics := ilm.Metrics()
-			metrics.RemoveIf(mfp.sieve.Sift)
+			scope := newMetricScope(rm.Resource(), ilm.Scope())
+			companions := pmetric.NewMetricSlice()
+			ilm.Metrics().RemoveIf(func(metric pmetric.Metric) bool {
+				return mfp.sieve.Sift(scope, companions, metric)
+			})
+			companions.MoveAndAppendTo(ilm.Metrics())
 		}
 		sms.RemoveIf(metricSliceEmpty)
 	}
 	rms.RemoveIf(ilmSliceEmpty)
+	if sieve, ok := mfp.sieve.(flusher); ok {
+		sieve.Flush(md)
+	}
 
 	return md, nil
 }
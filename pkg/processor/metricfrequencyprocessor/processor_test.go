@@ -134,6 +134,22 @@ func TestSelectionWithTwoLibraries(t *testing.T) {
 	assert.Equal(t, "m2", result.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Name())
 }
 
+func TestFlushedMetricsAreReported(t *testing.T) {
+	sieve := &flushingSieve{flushed: createMetrics(map[string][]string{"lib-2": {"m2"}})}
+	processor := &metricsfrequencyprocessor{sieve: sieve}
+
+	input := createMetrics(map[string][]string{"lib-1": {"m1"}})
+	result, err := processor.ProcessMetrics(context.Background(), input)
+
+	require.NoError(t, err)
+	require.Equal(t, 2, result.ResourceMetrics().Len())
+	assert.Equal(t, "m2", result.ResourceMetrics().At(1).ScopeMetrics().At(0).Metrics().At(0).Name())
+
+	result, err = processor.ProcessMetrics(context.Background(), pmetric.NewMetrics())
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.ResourceMetrics().Len())
+}
+
 func createGauge() pmetric.Gauge {
 	dpSlice := pmetric.NewNumberDataPointSlice()
 	pmetric.NewNumberDataPoint().CopyTo(dpSlice.AppendEmpty())
@@ -204,3 +220,16 @@ func getStringKeySlice(mapping map[string][]string) []string {
 
 	return out
 }
+
+func TestShutdownStopsSieve(t *testing.T) {
+	sieve := newTestSieve(t, createDefaultConfig().(*Config))
+	processor := &metricsfrequencyprocessor{sieve: sieve}
+
+	require.NoError(t, processor.Shutdown(context.Background()))
+
+	select {
+	case <-sieve.metricCache.done:
+	default:
+		t.Fatal("metric cache cleanup was not stopped")
+	}
+}
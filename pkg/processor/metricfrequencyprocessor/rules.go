@@ -0,0 +1,120 @@
+package metricfrequencyprocessor
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+// metricRule overrides report frequencies or exempts from sifting metrics matching its name and resource patterns.
+type metricRule struct {
+	metricName         *regexp.Regexp
+	resourceAttributes map[string]*regexp.Regexp
+
+	exempt                         bool
+	constantMetricsReportFrequency time.Duration
+	lowInfoMetricsReportFrequency  time.Duration
+	maxReportFrequency             time.Duration
+}
+
+func newMetricRules(configs []MetricRuleConfig) ([]metricRule, error) {
+	rules := make([]metricRule, 0, len(configs))
+	for i, cfg := range configs {
+		rule, err := newMetricRule(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("rules[%d]: %w", i, err)
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+func newMetricRule(cfg MetricRuleConfig) (metricRule, error) {
+	rule := metricRule{
+		resourceAttributes:             make(map[string]*regexp.Regexp, len(cfg.ResourceAttributes)),
+		exempt:                         cfg.Exempt,
+		constantMetricsReportFrequency: cfg.ConstantMetricsReportFrequency,
+		lowInfoMetricsReportFrequency:  cfg.LowInfoMetricsReportFrequency,
+		maxReportFrequency:             cfg.MaxReportFrequency,
+	}
+
+	if cfg.MetricName != "" && cfg.MetricNameRegex != "" {
+		return metricRule{}, fmt.Errorf("metric_name and metric_name_regex are mutually exclusive")
+	}
+	if cfg.ConstantMetricsReportFrequency < 0 || cfg.LowInfoMetricsReportFrequency < 0 || cfg.MaxReportFrequency < 0 {
+		return metricRule{}, fmt.Errorf("report frequencies must not be negative")
+	}
+
+	var err error
+	switch {
+	case cfg.MetricName != "":
+		rule.metricName, err = regexp.Compile(globToRegex(cfg.MetricName))
+	case cfg.MetricNameRegex != "":
+		rule.metricName, err = regexp.Compile("^(?:" + cfg.MetricNameRegex + ")$")
+	}
+	if err != nil {
+		return metricRule{}, fmt.Errorf("invalid metric name pattern: %w", err)
+	}
+
+	for key, pattern := range cfg.ResourceAttributes {
+		rule.resourceAttributes[key], err = regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			return metricRule{}, fmt.Errorf("invalid regex for resource attribute %s: %w", key, err)
+		}
+	}
+
+	return rule, nil
+}
+
+// globToRegex translates a glob pattern, where `*` matches any sequence of characters
+// and `?` matches a single character, into an anchored regular expression.
+func globToRegex(glob string) string {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range glob {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
+
+func (r metricRule) matches(resource pcommon.Resource, metricName string) bool {
+	if r.metricName != nil && !r.metricName.MatchString(metricName) {
+		return false
+	}
+
+	for key, pattern := range r.resourceAttributes {
+		value, ok := resource.Attributes().Get(key)
+		if !ok || !pattern.MatchString(value.AsString()) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// apply returns the sieve configuration with report frequencies overridden by the rule.
+func (r metricRule) apply(config sieveConfig) sieveConfig {
+	if r.constantMetricsReportFrequency > 0 {
+		config.ConstantMetricsReportFrequency = r.constantMetricsReportFrequency
+	}
+	if r.lowInfoMetricsReportFrequency > 0 {
+		config.LowInfoMetricsReportFrequency = r.lowInfoMetricsReportFrequency
+	}
+	if r.maxReportFrequency > 0 {
+		config.MaxReportFrequency = r.maxReportFrequency
+	}
+
+	return config
+}
@@ -0,0 +1,96 @@
+package metricfrequencyprocessor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func TestMetricRuleMatches(t *testing.T) {
+	resource := pcommon.NewResource()
+	resource.Attributes().PutStr("host.name", "noisy-1")
+
+	testCases := []struct {
+		name     string
+		rule     MetricRuleConfig
+		metric   string
+		expected bool
+	}{
+		{
+			name:     "glob",
+			rule:     MetricRuleConfig{MetricName: "slo_*"},
+			metric:   "slo_latency",
+			expected: true,
+		},
+		{
+			name:     "glob is anchored",
+			rule:     MetricRuleConfig{MetricName: "slo_*"},
+			metric:   "app_slo_latency",
+			expected: false,
+		},
+		{
+			name:     "glob escapes regex characters",
+			rule:     MetricRuleConfig{MetricName: "system.cpu.?"},
+			metric:   "system_cpu_1",
+			expected: false,
+		},
+		{
+			name:     "regex",
+			rule:     MetricRuleConfig{MetricNameRegex: "system\\.(cpu|memory)\\..*"},
+			metric:   "system.memory.usage",
+			expected: true,
+		},
+		{
+			name:     "resource attributes",
+			rule:     MetricRuleConfig{ResourceAttributes: map[string]string{"host.name": "noisy-.*"}},
+			metric:   "any",
+			expected: true,
+		},
+		{
+			name:     "missing resource attribute",
+			rule:     MetricRuleConfig{ResourceAttributes: map[string]string{"service.name": ".*"}},
+			metric:   "any",
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rule, err := newMetricRule(tc.rule)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, rule.matches(resource, tc.metric))
+		})
+	}
+}
+
+func TestSiftWithRules(t *testing.T) {
+	config := createDefaultConfig().(*Config)
+	config.MinPointAccumulationTime = time.Minute
+	config.Rules = []MetricRuleConfig{
+		{MetricName: "slo_*", Exempt: true},
+		{MetricName: "noisy_*", ConstantMetricsReportFrequency: 8 * time.Minute},
+	}
+	sieve := newTestSieve(t, config)
+	scope := newMetricScope(pcommon.NewResource(), pcommon.NewInstrumentationScope())
+	start := time.Unix(0, 0)
+
+	kept := map[string]int{}
+	for _, name := range []string{"slo_errors", "noisy_gauge", "gauge"} {
+		for i := 0; i <= 10; i++ {
+			metric := pmetric.NewMetric()
+			metric.SetName(name)
+			dataPoint := metric.SetEmptyGauge().DataPoints().AppendEmpty()
+			dataPoint.SetTimestamp(pcommon.NewTimestampFromTime(minutesAfter(start, i)))
+			dataPoint.SetDoubleValue(1.0)
+			if !sieve.Sift(scope, pmetric.NewMetricSlice(), metric) {
+				kept[name]++
+			}
+		}
+	}
+
+	assert.Equal(t, map[string]int{"slo_errors": 11, "noisy_gauge": 2, "gauge": 3}, kept)
+}
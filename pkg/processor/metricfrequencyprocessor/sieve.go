@@ -2,7 +2,6 @@ package metricfrequencyprocessor
 
 import (
 	"math"
-	"sort"
 	"time"
 
 	"go.opentelemetry.io/collector/pdata/pcommon"
@@ -15,7 +14,13 @@ const (
 )
 
 type metricSieve interface {
-	Sift(metric pmetric.Metric) bool
+	// Sift removes data points of the metric and returns true if the metric should be removed.
+	// The scope argument describes resource and instrumentation scope the metric belongs to.
+	// Metrics summarising removed data points, if any, are appended to companions.
+	Sift(scope metricScope, companions pmetric.MetricSlice, metric pmetric.Metric) bool
+
+	// Shutdown stops background work of the sieve.
+	Shutdown()
 }
 
 // defaultMetricSieve removes data points from MetricSlices that would be reported more often than preset
@@ -24,192 +29,370 @@ type metricSieve interface {
 // 1) Constant metrics
 // 2) Low info metrics - i.e. no anomaly in terms of iqr and low variation
 // 3) All other metrics
+// Each time series, identified by resource, scope, metric name and data point attributes, is categorised separately.
+// Report frequencies can be overridden for specific metrics by rules, the first matching rule applies.
 type defaultMetricSieve struct {
-	config sieveConfig
+	processorID       string
+	config            sieveConfig
+	rules             []metricRule
+	aggregate         bool
+	categoryAttribute bool
+	describeSeries    bool
+	checkpointLimits  checkpointLimits
+
+	metricCache *metricCache
+}
 
-	metricCache  *metricCache
-	lastReported map[string]pcommon.Timestamp
+// cumulativePoint is the last seen state of a cumulative metric, used to derive deltas between data points.
+type cumulativePoint struct {
+	start     pcommon.Timestamp
+	timestamp pcommon.Timestamp
+	count     float64
+	sum       float64
 }
 
-var _ metricSieve = (*defaultMetricSieve)(nil)
+var (
+	_ metricSieve = (*defaultMetricSieve)(nil)
+	_ flusher     = (*defaultMetricSieve)(nil)
+)
+
+// newMetricSieve returns a sieve of the processor identified by processorID, which tags its telemetry.
+func newMetricSieve(config *Config, processorID string) (*defaultMetricSieve, error) {
+	rules, err := newMetricRules(config.Rules)
+	if err != nil {
+		return nil, err
+	}
 
-func newMetricSieve(config *Config) *defaultMetricSieve {
 	return &defaultMetricSieve{
-		metricCache:  newMetricCache(config.cacheConfig),
-		lastReported: make(map[string]pcommon.Timestamp),
-		config:       config.sieveConfig,
-	}
+		processorID: processorID,
+		metricCache: newMetricCache(config.cacheConfig, processorID),
+		config:      config.sieveConfig,
+		rules:       rules,
+		aggregate:   config.ThinningMode == thinningModeAggregate,
+
+		categoryAttribute: config.AddCategoryAttribute,
+		describeSeries:    config.Debug.Endpoint != "",
+		checkpointLimits: checkpointLimits{
+			maxPointsPerSeries: config.CheckpointMaxPointsPerSeries,
+			maxSize:            config.CheckpointMaxSize,
+		},
+	}, nil
+}
+
+// Shutdown stops background cleanup of the sieve's cache.
+func (ms *defaultMetricSieve) Shutdown() {
+	ms.metricCache.Stop()
 }
 
 // Sift removes data points from MetricSlices of the metric argument according to specified strategy.
 // It returns true if the metric should be removed.
-func (ms *defaultMetricSieve) Sift(metric pmetric.Metric) bool {
+// In aggregate thinning mode removed data points are folded into the next reported data point of their series.
+func (ms *defaultMetricSieve) Sift(scope metricScope, companions pmetric.MetricSlice, metric pmetric.Metric) bool {
+	config, exempt := ms.configFor(scope.resource, metric.Name())
+	if exempt {
+		return false
+	}
+
+	run := &siftRun{scope: scope, name: metric.Name(), config: config}
+	defer run.dropped.recordDropped(ms.processorID)
+
 	switch metric.Type() {
 	case pmetric.MetricTypeGauge:
-		return ms.siftDropGauge(metric)
+		return ms.siftDropGauge(run, metric, companions)
+	case pmetric.MetricTypeSum:
+		return ms.siftDropSum(run, metric)
+	case pmetric.MetricTypeHistogram:
+		return ms.siftDropHistogram(run, metric)
+	case pmetric.MetricTypeExponentialHistogram:
+		return ms.siftDropExponentialHistogram(run, metric)
+	case pmetric.MetricTypeSummary:
+		return ms.siftDropSummary(run, metric)
 	default:
 		return false
 	}
 }
 
-func (ms *defaultMetricSieve) siftDropGauge(metric pmetric.Metric) bool {
-	metric.Gauge().DataPoints().RemoveIf(ms.siftDataPoint(metric.Name()))
+// configFor returns the sieve configuration for a metric, with overrides of the first matching rule applied.
+// It returns true if the metric is exempt from sifting.
+func (ms *defaultMetricSieve) configFor(resource pcommon.Resource, metricName string) (sieveConfig, bool) {
+	for _, rule := range ms.rules {
+		if rule.matches(resource, metricName) {
+			return rule.apply(ms.config), rule.exempt
+		}
+	}
 
-	return metric.Gauge().DataPoints().Len() == 0
+	return ms.config, false
 }
 
-func (ms *defaultMetricSieve) siftDataPoint(name string) func(pmetric.NumberDataPoint) bool {
-	return func(dataPoint pmetric.NumberDataPoint) bool {
-		if math.IsNaN(getVal(dataPoint)) {
-			return false
-		}
+// siftRun holds state shared by all data points of a single sifted metric.
+type siftRun struct {
+	scope   metricScope
+	name    string
+	config  sieveConfig
+	dropped categoryCounts
+}
 
-		cachedPoints := ms.metricCache.List(name)
-		ms.metricCache.Register(name, dataPoint)
-		lastReported, exists := ms.lastReported[name]
-		if !exists {
-			ms.lastReported[name] = dataPoint.Timestamp()
-			return false
-		}
-		earliest := earliestTimestamp(cachedPoints)
-		cachedPoints[dataPoint.Timestamp()] = getVal(dataPoint)
+// lockSeries returns the time series of a data point with its lock held.
+func (ms *defaultMetricSieve) lockSeries(run *siftRun, attributes pcommon.Map) *series {
+	s := ms.metricCache.Series(seriesKey(run.scope.identity, run.name, attributes))
+	s.mu.Lock()
+	if ms.describeSeries && s.description == nil {
+		s.description = newSeriesDescription(run.scope.resource, run.name, attributes)
+	}
+	return s
+}
 
-		if ms.metricRequiresSamples(dataPoint, earliest) {
-			ms.lastReported[name] = dataPoint.Timestamp()
-			return false
-		}
+// decide records the decision about a data point of the series and returns it.
+// The caller has to hold the series lock.
+func (ms *defaultMetricSieve) decide(run *siftRun, s *series, attributes pcommon.Map, removed bool) bool {
+	if removed {
+		run.dropped[s.category]++
+		return true
+	}
 
-		if pastCategoryFrequency(dataPoint, lastReported, ms.config.ConstantMetricsReportFrequency) {
-			ms.lastReported[name] = dataPoint.Timestamp()
-			return false
-		}
+	if ms.categoryAttribute {
+		attributes.PutStr(categoryAttribute, s.category.String())
+	}
+	return false
+}
 
-		if isConstant(dataPoint, cachedPoints) {
-			return true
+func (ms *defaultMetricSieve) siftDropGauge(run *siftRun, metric pmetric.Metric, companions pmetric.MetricSlice) bool {
+	gaugeCompanions := newGaugeCompanions(metric, companions)
+	metric.Gauge().DataPoints().RemoveIf(func(dataPoint pmetric.NumberDataPoint) bool {
+		s := ms.lockSeries(run, dataPoint.Attributes())
+		defer s.mu.Unlock()
+		removed := ms.siftValue(s, run.config, dataPoint.Timestamp(), getVal(dataPoint))
+		if ms.aggregate {
+			foldGauge(s, dataPoint, removed, gaugeCompanions)
 		}
+		return ms.decide(run, s, dataPoint.Attributes(), removed)
+	})
 
-		if pastCategoryFrequency(dataPoint, lastReported, ms.config.LowInfoMetricsReportFrequency) {
-			ms.lastReported[name] = dataPoint.Timestamp()
-			return false
+	return metric.Gauge().DataPoints().Len() == 0
+}
+
+// siftDropSum classifies delta sums by their value and cumulative sums by their rate of change.
+func (ms *defaultMetricSieve) siftDropSum(run *siftRun, metric pmetric.Metric) bool {
+	sum := metric.Sum()
+	cumulative := sum.AggregationTemporality() == pmetric.AggregationTemporalityCumulative
+	sum.DataPoints().RemoveIf(func(dataPoint pmetric.NumberDataPoint) bool {
+		s := ms.lockSeries(run, dataPoint.Attributes())
+		defer s.mu.Unlock()
+		value := getVal(dataPoint)
+		if !cumulative {
+			removed := ms.siftValue(s, run.config, dataPoint.Timestamp(), value)
+			if ms.aggregate {
+				foldDeltaSum(s, run.scope, metric, dataPoint, removed)
+			}
+			return ms.decide(run, s, dataPoint.Attributes(), removed)
 		}
 
-		if ms.isLowInformation(cachedPoints) {
-			return true
+		_, deltaValue, elapsed, ok := cumulativeDelta(s, dataPoint.StartTimestamp(), dataPoint.Timestamp(), 0, value)
+		if !ok || (sum.IsMonotonic() && deltaValue < 0) {
+			return ms.decide(run, s, dataPoint.Attributes(), false)
 		}
+		removed := ms.siftValue(s, run.config, dataPoint.Timestamp(), deltaValue/elapsed)
+		return ms.decide(run, s, dataPoint.Attributes(), removed)
+	})
 
-		if pastCategoryFrequency(dataPoint, lastReported, ms.config.MaxReportFrequency) {
-			ms.lastReported[name] = dataPoint.Timestamp()
-			return false
+	return sum.DataPoints().Len() == 0
+}
+
+func (ms *defaultMetricSieve) siftDropHistogram(run *siftRun, metric pmetric.Metric) bool {
+	histogram := metric.Histogram()
+	cumulative := histogram.AggregationTemporality() == pmetric.AggregationTemporalityCumulative
+	flushed := pmetric.NewHistogramDataPointSlice()
+	histogram.DataPoints().RemoveIf(func(dataPoint pmetric.HistogramDataPoint) bool {
+		s := ms.lockSeries(run, dataPoint.Attributes())
+		defer s.mu.Unlock()
+		removed := ms.siftDistribution(s, run.config, cumulative, dataPoint.StartTimestamp(), dataPoint.Timestamp(),
+			float64(dataPoint.Count()), dataPoint.Sum(), dataPoint.HasSum())
+		if ms.aggregate && !cumulative {
+			foldDeltaHistogram(s, run.scope, metric, dataPoint, removed, flushed)
 		}
+		return ms.decide(run, s, dataPoint.Attributes(), removed)
+	})
+	flushed.MoveAndAppendTo(histogram.DataPoints())
 
-		return true
-	}
+	return histogram.DataPoints().Len() == 0
 }
 
-func (ms *defaultMetricSieve) metricRequiresSamples(point pmetric.NumberDataPoint, earliest pcommon.Timestamp) bool {
-	return point.Timestamp().AsTime().Before(earliest.AsTime().Add(ms.config.MinPointAccumulationTime))
+// siftDropExponentialHistogram does not sift delta exponential histograms in aggregate thinning mode,
+// as merging their buckets is not supported.
+func (ms *defaultMetricSieve) siftDropExponentialHistogram(run *siftRun, metric pmetric.Metric) bool {
+	histogram := metric.ExponentialHistogram()
+	cumulative := histogram.AggregationTemporality() == pmetric.AggregationTemporalityCumulative
+	if ms.aggregate && !cumulative {
+		return false
+	}
+	histogram.DataPoints().RemoveIf(func(dataPoint pmetric.ExponentialHistogramDataPoint) bool {
+		s := ms.lockSeries(run, dataPoint.Attributes())
+		defer s.mu.Unlock()
+		removed := ms.siftDistribution(s, run.config, cumulative, dataPoint.StartTimestamp(), dataPoint.Timestamp(),
+			float64(dataPoint.Count()), dataPoint.Sum(), dataPoint.HasSum())
+		return ms.decide(run, s, dataPoint.Attributes(), removed)
+	})
+
+	return histogram.DataPoints().Len() == 0
 }
 
-func pastCategoryFrequency(point pmetric.NumberDataPoint, lastReport pcommon.Timestamp, categoryFrequency time.Duration) bool {
-	return point.Timestamp().AsTime().Add(safetyInterval).After(lastReport.AsTime().Add(categoryFrequency))
+// siftDropSummary treats summaries as cumulative distributions, as they carry count and sum since start time.
+func (ms *defaultMetricSieve) siftDropSummary(run *siftRun, metric pmetric.Metric) bool {
+	metric.Summary().DataPoints().RemoveIf(func(dataPoint pmetric.SummaryDataPoint) bool {
+		s := ms.lockSeries(run, dataPoint.Attributes())
+		defer s.mu.Unlock()
+		removed := ms.siftDistribution(s, run.config, true, dataPoint.StartTimestamp(), dataPoint.Timestamp(),
+			float64(dataPoint.Count()), dataPoint.Sum(), true)
+		return ms.decide(run, s, dataPoint.Attributes(), removed)
+	})
+
+	return metric.Summary().DataPoints().Len() == 0
 }
 
-func isConstant(point pmetric.NumberDataPoint, points map[pcommon.Timestamp]float64) bool {
-	for _, value := range points {
-		if !almostEqual(getVal(point), value) {
+// siftDistribution classifies a histogram or summary data point by the mean of observations made since
+// the previous data point, i.e. sum delta divided by count delta. Distributions without sum are classified
+// by their observation rate instead. An interval without observations is classified as zero.
+func (ms *defaultMetricSieve) siftDistribution(
+	s *series,
+	config sieveConfig,
+	cumulative bool,
+	start pcommon.Timestamp,
+	timestamp pcommon.Timestamp,
+	count float64,
+	sum float64,
+	hasSum bool,
+) bool {
+	deltaCount, deltaSum := count, sum
+	elapsed := 0.0
+	if cumulative {
+		var ok bool
+		deltaCount, deltaSum, elapsed, ok = cumulativeDelta(s, start, timestamp, count, sum)
+		if !ok || deltaCount < 0 {
 			return false
 		}
 	}
 
-	return true
+	switch {
+	case !hasSum && cumulative:
+		return ms.siftValue(s, config, timestamp, deltaCount/elapsed)
+	case !hasSum:
+		return ms.siftValue(s, config, timestamp, deltaCount)
+	case deltaCount == 0:
+		return ms.siftValue(s, config, timestamp, 0)
+	default:
+		return ms.siftValue(s, config, timestamp, deltaSum/deltaCount)
+	}
 }
 
-// isLowInformation is a heuristic attempt at defining uninteresting metrics. Requirements:
-// 1) no big changes - defined by no iqr anomalies
-// 2) little oscillations - defined by low variation
-func (ms *defaultMetricSieve) isLowInformation(points map[pcommon.Timestamp]float64) bool {
-	q1, q3 := calculateQ1Q3(points)
-	iqr := q3 - q1
-	variation := calculateVariation(points)
-
-	noAnomaly := withinBounds(points, q1-ms.config.IqrAnomalyCoef*iqr, q3+ms.config.IqrAnomalyCoef*iqr)
-	return noAnomaly && ms.lowVariation(variation, iqr)
-}
+// cumulativeDelta returns count and sum deltas since the previous data point of a cumulative series
+// along with the elapsed time in seconds. It returns false if there is no previous data point or the
+// series has been restarted, in which case the data point should be reported as is.
+// The caller has to hold the series lock.
+func cumulativeDelta(
+	s *series,
+	start pcommon.Timestamp,
+	timestamp pcommon.Timestamp,
+	count float64,
+	sum float64,
+) (float64, float64, float64, bool) {
+	previous := s.lastCumulative
+	s.lastCumulative = &cumulativePoint{start: start, timestamp: timestamp, count: count, sum: sum}
+	if previous == nil || previous.start != start || timestamp <= previous.timestamp {
+		return 0, 0, 0, false
+	}
 
-// calculateQ1Q3 returns specific quantiles - it refers to quantiles .25 and .75 respectively
-func calculateQ1Q3(points map[pcommon.Timestamp]float64) (float64, float64) {
-	values := valueSlice(points)
-	sort.Float64s(values)
-	q1Index := len(points) / 4
-	q3Index := 3 * len(points) / 4
-	return values[q1Index], values[q3Index]
+	elapsed := timestamp.AsTime().Sub(previous.timestamp.AsTime()).Seconds()
+	return count - previous.count, sum - previous.sum, elapsed, true
 }
 
-func withinBounds(points map[pcommon.Timestamp]float64, lowerBound float64, upperBound float64) bool {
-	for _, v := range points {
-		if v < lowerBound {
-			return false
-		}
-		if v > upperBound {
-			return false
-		}
+// siftValue categorises a value of the time series and returns true if it should be removed.
+// Values which are not finite are always reported and not taken into account when categorising the series.
+// The caller has to hold the series lock.
+func (ms *defaultMetricSieve) siftValue(s *series, config sieveConfig, timestamp pcommon.Timestamp, value float64) bool {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return false
 	}
 
-	return true
-}
-
-// calculateVariation returns a sum of absolute values of differences of subsequent data points.
-func calculateVariation(points map[pcommon.Timestamp]float64) float64 {
-	keys := keySlice(points)
-	sortTimestampArray(keys)
+	ms.metricCache.expireOldest(s)
+	warmup := s.window.len() == 0 || ms.metricRequiresSamples(timestamp, s.window.earliest())
+	ms.metricCache.register(s, timestamp, value)
+	if !s.reported {
+		ms.metricCache.setCategory(s, categoryWarmup)
+		s.report(timestamp)
+		return false
+	}
+	lastReported := s.lastReported
 
-	variation := 0.0
-	previous := keys[0]
-	for i := 1; i < len(keys); i++ {
-		current := keys[i]
-		variation += math.Abs(points[current] - points[previous])
-		previous = current
+	ms.metricCache.setCategory(s, ms.categorise(warmup, value, &s.window))
+	if pastReportFrequency(s.category, config, timestamp, lastReported) {
+		s.report(timestamp)
+		return false
 	}
 
-	return variation
+	return true
 }
 
-// lowVariation returns a heuristic check indicating that data points display little oscillations
-func (ms *defaultMetricSieve) lowVariation(variation float64, iqr float64) bool {
-	return variation < ms.config.VariationIqrThresholdCoef*iqr
+func (ms *defaultMetricSieve) categorise(warmup bool, value float64, w *window) seriesCategory {
+	switch {
+	case warmup:
+		return categoryWarmup
+	case isConstant(value, w):
+		return categoryConstant
+	case ms.isLowInformation(w):
+		return categoryLowInfo
+	default:
+		return categoryNormal
+	}
 }
 
-func earliestTimestamp(points map[pcommon.Timestamp]float64) pcommon.Timestamp {
-	min := pcommon.NewTimestampFromTime(time.Now())
-	for k := range points {
-		if k < min {
-			min = k
+// pastReportFrequency checks if a data point of the given category should be reported. Less informative
+// categories are reported less often, but any category is reported when past report frequency of a
+// less informative one.
+func pastReportFrequency(category seriesCategory, config sieveConfig, timestamp pcommon.Timestamp, lastReported pcommon.Timestamp) bool {
+	switch category {
+	case categoryNormal:
+		if pastCategoryFrequency(timestamp, lastReported, config.MaxReportFrequency) {
+			return true
 		}
+		fallthrough
+	case categoryLowInfo:
+		if pastCategoryFrequency(timestamp, lastReported, config.LowInfoMetricsReportFrequency) {
+			return true
+		}
+		fallthrough
+	case categoryConstant:
+		return pastCategoryFrequency(timestamp, lastReported, config.ConstantMetricsReportFrequency)
+	default:
+		return true
 	}
+}
 
-	return min
+func (ms *defaultMetricSieve) metricRequiresSamples(timestamp pcommon.Timestamp, earliest pcommon.Timestamp) bool {
+	return timestamp.AsTime().Before(earliest.AsTime().Add(ms.config.MinPointAccumulationTime))
 }
 
-func keySlice(mapping map[pcommon.Timestamp]float64) []pcommon.Timestamp {
-	out := make([]pcommon.Timestamp, len(mapping))
-	i := 0
-	for k := range mapping {
-		out[i] = k
-		i++
-	}
+func pastCategoryFrequency(timestamp pcommon.Timestamp, lastReport pcommon.Timestamp, categoryFrequency time.Duration) bool {
+	return timestamp.AsTime().Add(safetyInterval).After(lastReport.AsTime().Add(categoryFrequency))
+}
 
-	return out
+func isConstant(value float64, w *window) bool {
+	return almostEqual(value, w.min()) && almostEqual(value, w.max())
 }
 
-func valueSlice(mapping map[pcommon.Timestamp]float64) []float64 {
-	out := make([]float64, len(mapping))
-	i := 0
-	for _, v := range mapping {
-		out[i] = v
-		i++
-	}
+// isLowInformation is a heuristic attempt at defining uninteresting metrics. Requirements:
+// 1) no big changes - defined by no iqr anomalies
+// 2) little oscillations - defined by low variation
+func (ms *defaultMetricSieve) isLowInformation(w *window) bool {
+	q1, q3 := w.quartiles()
+	iqr := q3 - q1
 
-	return out
+	noAnomaly := w.min() >= q1-ms.config.IqrAnomalyCoef*iqr && w.max() <= q3+ms.config.IqrAnomalyCoef*iqr
+	return noAnomaly && ms.lowVariation(w.totalVariation(), iqr)
+}
+
+// lowVariation returns a heuristic check indicating that data points display little oscillations
+func (ms *defaultMetricSieve) lowVariation(variation float64, iqr float64) bool {
+	return variation < ms.config.VariationIqrThresholdCoef*iqr
 }
 
 func almostEqual(a, b float64) bool {
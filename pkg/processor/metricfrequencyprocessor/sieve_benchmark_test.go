@@ -0,0 +1,111 @@
+package metricfrequencyprocessor
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// BenchmarkSift measures throughput of the sieve with many time series, each having a full window of cached data points.
+// A single iteration sifts one data point of every series.
+func BenchmarkSift(b *testing.B) {
+	benchmarks := []struct {
+		name            string
+		numSeries       int
+		pointsPerSeries int
+	}{
+		{"1k_series_60_points", 1000, 60},
+		{"100k_series_60_points", 100000, 60},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			runSiftBenchmark(b, bm.numSeries, bm.pointsPerSeries)
+		})
+	}
+}
+
+func runSiftBenchmark(b *testing.B, numSeries int, pointsPerSeries int) {
+	config := createDefaultConfig().(*Config)
+	config.MinPointAccumulationTime = time.Minute
+	sieve, err := newMetricSieve(config, b.Name())
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer sieve.Shutdown()
+
+	scope := newMetricScope(pcommon.NewResource(), pcommon.NewInstrumentationScope())
+	start := time.Unix(0, 0)
+	for i := range pointsPerSeries {
+		sieve.Sift(scope, pmetric.NewMetricSlice(), generateGauge(numSeries, minutesAfter(start, i), i))
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	i := pointsPerSeries
+	for b.Loop() {
+		b.StopTimer()
+		metric := generateGauge(numSeries, minutesAfter(start, i), i)
+		i++
+		b.StartTimer()
+
+		sieve.Sift(scope, pmetric.NewMetricSlice(), metric)
+	}
+	b.ReportMetric(float64(b.N*numSeries)/b.Elapsed().Seconds(), "points/s")
+}
+
+// BenchmarkSiftSeries measures the cost of sifting a single data point depending on the number of cached ones.
+func BenchmarkSiftSeries(b *testing.B) {
+	for _, pointsPerSeries := range []int{60, 1000} {
+		b.Run(fmt.Sprintf("%d_points", pointsPerSeries), func(b *testing.B) {
+			config := createDefaultConfig().(*Config)
+			config.MinPointAccumulationTime = time.Minute
+			config.MaxPointsPerSeries = pointsPerSeries
+			config.DataPointExpirationTime = 24 * time.Hour
+			sieve, err := newMetricSieve(config, b.Name())
+			if err != nil {
+				b.Fatal(err)
+			}
+			defer sieve.Shutdown()
+
+			scope := newMetricScope(pcommon.NewResource(), pcommon.NewInstrumentationScope())
+			start := time.Unix(0, 0)
+			i := 0
+			for ; i < pointsPerSeries; i++ {
+				sieve.Sift(scope, pmetric.NewMetricSlice(), generateGauge(1, minutesAfter(start, i), i))
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for b.Loop() {
+				b.StopTimer()
+				metric := generateGauge(1, minutesAfter(start, i), i)
+				i++
+				b.StartTimer()
+
+				sieve.Sift(scope, pmetric.NewMetricSlice(), metric)
+			}
+		})
+	}
+}
+
+// generateGauge returns a gauge with a data point for each of numSeries time series. Values vary between
+// series and iterations, so that series are neither constant nor trivially categorised.
+func generateGauge(numSeries int, timestamp time.Time, iteration int) pmetric.Metric {
+	metric := pmetric.NewMetric()
+	metric.SetName("benchmark")
+	dataPoints := metric.SetEmptyGauge().DataPoints()
+	dataPoints.EnsureCapacity(numSeries)
+	for j := range numSeries {
+		dataPoint := dataPoints.AppendEmpty()
+		dataPoint.Attributes().PutInt("series", int64(j))
+		dataPoint.SetTimestamp(pcommon.NewTimestampFromTime(timestamp))
+		dataPoint.SetDoubleValue(float64((iteration*7919 + j*31) % 100))
+	}
+	return metric
+}
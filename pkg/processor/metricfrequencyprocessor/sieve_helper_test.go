@@ -1,16 +1,32 @@
 package metricfrequencyprocessor
 
-import "go.opentelemetry.io/collector/pdata/pmetric"
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func newTestSieve(t *testing.T, config *Config) *defaultMetricSieve {
+	sieve, err := newMetricSieve(config, t.Name())
+	require.NoError(t, err)
+	t.Cleanup(sieve.Shutdown)
+	return sieve
+}
 
 type siftAllSieve struct{}
 
-func (s *siftAllSieve) Sift(metric pmetric.Metric) bool {
+func (s *siftAllSieve) Shutdown() {}
+
+func (s *siftAllSieve) Sift(_ metricScope, _ pmetric.MetricSlice, metric pmetric.Metric) bool {
 	return true
 }
 
 type keepAllSieve struct{}
 
-func (s *keepAllSieve) Sift(metric pmetric.Metric) bool {
+func (s *keepAllSieve) Shutdown() {}
+
+func (s *keepAllSieve) Sift(_ metricScope, _ pmetric.MetricSlice, metric pmetric.Metric) bool {
 	return false
 }
 
@@ -18,6 +34,18 @@ type singleMetricSieve struct {
 	name string
 }
 
-func (s *singleMetricSieve) Sift(metric pmetric.Metric) bool {
+func (s *singleMetricSieve) Shutdown() {}
+
+func (s *singleMetricSieve) Sift(_ metricScope, _ pmetric.MetricSlice, metric pmetric.Metric) bool {
 	return metric.Name() == s.name
 }
+
+// flushingSieve keeps all metrics and reports the flushed ones once.
+type flushingSieve struct {
+	keepAllSieve
+	flushed pmetric.Metrics
+}
+
+func (s *flushingSieve) Flush(md pmetric.Metrics) {
+	s.flushed.ResourceMetrics().MoveAndAppendTo(md.ResourceMetrics())
+}
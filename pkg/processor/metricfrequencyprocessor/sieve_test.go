@@ -1,6 +1,7 @@
 package metricfrequencyprocessor
 
 import (
+	"sync"
 	"testing"
 	"time"
 
@@ -10,11 +11,11 @@ import (
 )
 
 func TestAccumulate(t *testing.T) {
-	sieve := newMetricSieve(createDefaultConfig().(*Config))
+	sieve := newTestSieve(t, createDefaultConfig().(*Config))
 	var timestamp = time.Unix(0, 0)
 	setupHistory(sieve, map[time.Time]float64{timestamp: 0.0})
 
-	result := sieve.Sift(dataPointsToMetric(map[time.Time]float64{
+	result := sieve.Sift(metricScope{}, pmetric.NewMetricSlice(), dataPointsToMetric(map[time.Time]float64{
 		timestamp.Add(1 * time.Minute): 0.0,
 	}))
 
@@ -22,6 +23,145 @@ func TestAccumulate(t *testing.T) {
 	assert.False(t, result)
 }
 
+func TestSiftNonGaugeMetrics(t *testing.T) {
+	start := time.Unix(0, 0)
+	testCases := []struct {
+		name         string
+		metric       func(i int) pmetric.Metric
+		expectedKept int
+	}{
+		{
+			name: "cumulative sum with constant rate",
+			metric: func(i int) pmetric.Metric {
+				return sumMetric(pmetric.AggregationTemporalityCumulative, start, minutesAfter(start, i), float64(10*i))
+			},
+			// first point has no rate, second one starts the history, sixth one is past constant frequency
+			expectedKept: 3,
+		},
+		{
+			name: "delta sum with constant value",
+			metric: func(i int) pmetric.Metric {
+				return sumMetric(pmetric.AggregationTemporalityDelta, minutesAfter(start, i-1), minutesAfter(start, i), 10)
+			},
+			expectedKept: 3,
+		},
+		{
+			name: "cumulative histogram with constant mean",
+			metric: func(i int) pmetric.Metric {
+				return histogramMetric(pmetric.AggregationTemporalityCumulative, start, minutesAfter(start, i), uint64(10*i), float64(50*i))
+			},
+			expectedKept: 3,
+		},
+		{
+			name: "delta exponential histogram with constant mean",
+			metric: func(i int) pmetric.Metric {
+				return exponentialHistogramMetric(minutesAfter(start, i), 10, 50)
+			},
+			expectedKept: 3,
+		},
+		{
+			name: "summary with constant mean",
+			metric: func(i int) pmetric.Metric {
+				return summaryMetric(start, minutesAfter(start, i), uint64(10*i), float64(50*i))
+			},
+			expectedKept: 3,
+		},
+		{
+			name: "cumulative sum restarted every point",
+			metric: func(i int) pmetric.Metric {
+				return sumMetric(pmetric.AggregationTemporalityCumulative, minutesAfter(start, i), minutesAfter(start, i), float64(10*i))
+			},
+			expectedKept: 11,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := createDefaultConfig().(*Config)
+			config.MinPointAccumulationTime = time.Minute
+			sieve := newTestSieve(t, config)
+
+			kept := 0
+			for i := 0; i <= 10; i++ {
+				if !sieve.Sift(metricScope{}, pmetric.NewMetricSlice(), tc.metric(i)) {
+					kept++
+				}
+			}
+
+			assert.Equal(t, tc.expectedKept, kept)
+		})
+	}
+}
+
+func TestSiftCumulativeSumByRate(t *testing.T) {
+	config := createDefaultConfig().(*Config)
+	config.MinPointAccumulationTime = time.Minute
+	sieve := newTestSieve(t, config)
+	start := time.Unix(0, 0)
+
+	for i := 0; i <= 5; i++ {
+		sieve.Sift(metricScope{}, pmetric.NewMetricSlice(), sumMetric(pmetric.AggregationTemporalityCumulative, start, minutesAfter(start, i), float64(10*i)))
+	}
+
+	// the value keeps growing, but rate of change is constant
+	points := sieve.metricCache.List(seriesKey("", "test", pcommon.NewMap()))
+	assert.Len(t, points, 5)
+	for _, value := range points {
+		assert.InDelta(t, 10.0/60.0, value, float64EqualityThreshold)
+	}
+}
+
+func TestSiftSeriesSeparately(t *testing.T) {
+	config := createDefaultConfig().(*Config)
+	config.MinPointAccumulationTime = time.Minute
+	sieve := newTestSieve(t, config)
+	start := time.Unix(0, 0)
+
+	kept := map[string]int{}
+	for i := 0; i <= 10; i++ {
+		metric := pmetric.NewMetric()
+		metric.SetName("test")
+		dataPoints := metric.SetEmptyGauge().DataPoints()
+		constant := dataPoints.AppendEmpty()
+		constant.Attributes().PutStr("series", "constant")
+		constant.SetTimestamp(pcommon.NewTimestampFromTime(minutesAfter(start, i)))
+		constant.SetDoubleValue(1.0)
+		oscillating := dataPoints.AppendEmpty()
+		oscillating.Attributes().PutStr("series", "oscillating")
+		oscillating.SetTimestamp(pcommon.NewTimestampFromTime(minutesAfter(start, i)))
+		oscillating.SetDoubleValue(float64(100 * (i % 2)))
+
+		sieve.Sift(metricScope{identity: "scope"}, pmetric.NewMetricSlice(), metric)
+		for j := 0; j < dataPoints.Len(); j++ {
+			series, _ := dataPoints.At(j).Attributes().Get("series")
+			kept[series.Str()]++
+		}
+	}
+
+	// constant series is thinned, while the oscillating one is not affected by it
+	assert.Equal(t, 3, kept["constant"])
+	assert.Greater(t, kept["oscillating"], 2*kept["constant"])
+}
+
+func TestSiftConcurrently(t *testing.T) {
+	sieve := newTestSieve(t, createDefaultConfig().(*Config))
+	start := time.Unix(0, 0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				sieve.Sift(metricScope{identity: "scope"}, pmetric.NewMetricSlice(), sumMetric(pmetric.AggregationTemporalityCumulative, start, minutesAfter(start, i*50+j), float64(j)))
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, sieve.metricCache.internalCaches.Len())
+}
+
 func TestIsConstant(t *testing.T) {
 	type testCase struct {
 		dataPoint     pmetric.NumberDataPoint
@@ -64,7 +204,7 @@ func TestIsConstant(t *testing.T) {
 	}
 
 	for _, test := range testCases {
-		result := isConstant(test.dataPoint, unixPointsToPdata(test.values))
+		result := isConstant(getVal(test.dataPoint), unixPointsToWindow(test.values))
 		assert.Equal(t, result, test.expectedValue)
 	}
 }
@@ -108,10 +248,10 @@ func TestIsLowInfo(t *testing.T) {
 		},
 	}
 
-	sieve := newMetricSieve(createDefaultConfig().(*Config))
+	sieve := newTestSieve(t, createDefaultConfig().(*Config))
 
 	for _, test := range testCases {
-		result := sieve.isLowInformation(unixPointsToPdata(test.values))
+		result := sieve.isLowInformation(unixPointsToWindow(test.values))
 		assert.Equal(t, result, test.expectedValue)
 	}
 }
@@ -149,7 +289,7 @@ func TestQuantiles(t *testing.T) {
 	}
 
 	for _, test := range testCases {
-		resultQ1, resultQ3 := calculateQ1Q3(unixPointsToPdata(test.values))
+		resultQ1, resultQ3 := unixPointsToWindow(test.values).quartiles()
 		assert.True(t, almostEqual(resultQ1, test.expectedQ1Value))
 		assert.True(t, almostEqual(resultQ3, test.expectedQ3Value))
 	}
@@ -203,16 +343,18 @@ func TestVariation(t *testing.T) {
 	}
 
 	for _, test := range testCases {
-		result := calculateVariation(unixPointsToPdata(test.values))
+		result := unixPointsToWindow(test.values).totalVariation()
 		assert.True(t, almostEqual(result, test.expectedValue))
 	}
 }
 
-func unixPointsToPdata(points map[int64]float64) map[pcommon.Timestamp]float64 {
-	out := make(map[pcommon.Timestamp]float64)
+func unixPointsToWindow(points map[int64]float64) *window {
+	out := &window{}
 	for unix, value := range points {
-		timestamp := pcommon.NewTimestampFromTime(time.Unix(unix, 0))
-		out[timestamp] = value
+		out.add(cachedDataPoint{
+			DataPoint: DataPoint{Timestamp: pcommon.NewTimestampFromTime(time.Unix(unix, 0)), Value: value},
+			expires:   time.Unix(unix, 0).Add(time.Hour),
+		}, len(points))
 	}
 
 	return out
@@ -227,7 +369,7 @@ func createDataPoint(timestamp time.Time, value float64) pmetric.NumberDataPoint
 }
 
 func setupHistory(sieve metricSieve, dataPoints map[time.Time]float64) {
-	sieve.Sift(dataPointsToMetric(dataPoints))
+	sieve.Sift(metricScope{}, pmetric.NewMetricSlice(), dataPointsToMetric(dataPoints))
 }
 
 func dataPointsToMetric(dataPoints map[time.Time]float64) pmetric.Metric {
@@ -240,3 +382,56 @@ func dataPointsToMetric(dataPoints map[time.Time]float64) pmetric.Metric {
 	}
 	return out
 }
+
+func minutesAfter(start time.Time, minutes int) time.Time {
+	return start.Add(time.Duration(minutes) * time.Minute)
+}
+
+func sumMetric(temporality pmetric.AggregationTemporality, start time.Time, timestamp time.Time, value float64) pmetric.Metric {
+	out := pmetric.NewMetric()
+	out.SetName("test")
+	sum := out.SetEmptySum()
+	sum.SetAggregationTemporality(temporality)
+	sum.SetIsMonotonic(true)
+	dataPoint := sum.DataPoints().AppendEmpty()
+	dataPoint.SetStartTimestamp(pcommon.NewTimestampFromTime(start))
+	dataPoint.SetTimestamp(pcommon.NewTimestampFromTime(timestamp))
+	dataPoint.SetDoubleValue(value)
+	return out
+}
+
+func histogramMetric(temporality pmetric.AggregationTemporality, start time.Time, timestamp time.Time, count uint64, sum float64) pmetric.Metric {
+	out := pmetric.NewMetric()
+	out.SetName("test")
+	histogram := out.SetEmptyHistogram()
+	histogram.SetAggregationTemporality(temporality)
+	dataPoint := histogram.DataPoints().AppendEmpty()
+	dataPoint.SetStartTimestamp(pcommon.NewTimestampFromTime(start))
+	dataPoint.SetTimestamp(pcommon.NewTimestampFromTime(timestamp))
+	dataPoint.SetCount(count)
+	dataPoint.SetSum(sum)
+	return out
+}
+
+func exponentialHistogramMetric(timestamp time.Time, count uint64, sum float64) pmetric.Metric {
+	out := pmetric.NewMetric()
+	out.SetName("test")
+	histogram := out.SetEmptyExponentialHistogram()
+	histogram.SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+	dataPoint := histogram.DataPoints().AppendEmpty()
+	dataPoint.SetTimestamp(pcommon.NewTimestampFromTime(timestamp))
+	dataPoint.SetCount(count)
+	dataPoint.SetSum(sum)
+	return out
+}
+
+func summaryMetric(start time.Time, timestamp time.Time, count uint64, sum float64) pmetric.Metric {
+	out := pmetric.NewMetric()
+	out.SetName("test")
+	dataPoint := out.SetEmptySummary().DataPoints().AppendEmpty()
+	dataPoint.SetStartTimestamp(pcommon.NewTimestampFromTime(start))
+	dataPoint.SetTimestamp(pcommon.NewTimestampFromTime(timestamp))
+	dataPoint.SetCount(count)
+	dataPoint.SetSum(sum)
+	return out
+}
@@ -0,0 +1,99 @@
+package metricfrequencyprocessor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension/xextension/storage"
+	"go.uber.org/zap"
+)
+
+const checkpointStorageKey = "sieve_checkpoint"
+
+// checkpointer is implemented by sieves which state can be persisted between restarts.
+type checkpointer interface {
+	Checkpoint() ([]byte, error)
+	Restore(data []byte) error
+}
+
+// Checkpoint returns serialized state of the sieve.
+func (ms *defaultMetricSieve) Checkpoint() ([]byte, error) {
+	return ms.metricCache.Checkpoint(ms.checkpointLimits)
+}
+
+// Restore loads state of the sieve from a checkpoint.
+func (ms *defaultMetricSieve) Restore(data []byte) error {
+	return ms.metricCache.Restore(data)
+}
+
+// getStorageClient returns a client of the configured storage extension.
+func getStorageClient(ctx context.Context, host component.Host, storageID component.ID, id component.ID) (storage.Client, error) {
+	ext, found := host.GetExtensions()[storageID]
+	if !found {
+		return nil, fmt.Errorf("storage extension '%s' not found", storageID)
+	}
+
+	storageExtension, ok := ext.(storage.Extension)
+	if !ok {
+		return nil, fmt.Errorf("extension '%s' is not a storage extension", storageID)
+	}
+
+	client, err := storageExtension.GetClient(ctx, component.KindProcessor, id, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get storage client for extension '%s': %w", storageID, err)
+	}
+
+	return client, nil
+}
+
+// restoreCheckpoint loads the sieve state saved by a previous run, if there is any.
+// A missing or unreadable checkpoint is not an error, the processor just starts from scratch.
+func (mfp *metricsfrequencyprocessor) restoreCheckpoint(ctx context.Context, sieve checkpointer) {
+	data, err := mfp.storage.Get(ctx, checkpointStorageKey)
+	if err != nil {
+		mfp.logger.Warn("Failed to read checkpoint from storage", zap.Error(err))
+		return
+	}
+	if data == nil {
+		mfp.logger.Info("Checkpoint not found in storage")
+		return
+	}
+
+	if err := sieve.Restore(data); err != nil {
+		mfp.logger.Warn("Failed to restore checkpoint", zap.Error(err))
+		return
+	}
+	mfp.logger.Info("Restored checkpoint from storage")
+}
+
+func (mfp *metricsfrequencyprocessor) saveCheckpoint(ctx context.Context, sieve checkpointer) error {
+	data, err := sieve.Checkpoint()
+	if err != nil {
+		return err
+	}
+
+	if err := mfp.storage.Set(ctx, checkpointStorageKey, data); err != nil {
+		return fmt.Errorf("failed to save checkpoint to storage: %w", err)
+	}
+
+	return nil
+}
+
+func (mfp *metricsfrequencyprocessor) checkpointLoop(sieve checkpointer, interval time.Duration) {
+	defer mfp.wg.Done()
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			if err := mfp.saveCheckpoint(context.Background(), sieve); err != nil {
+				mfp.logger.Warn("Failed to save checkpoint", zap.Error(err))
+			}
+		case <-mfp.done:
+			return
+		}
+	}
+}
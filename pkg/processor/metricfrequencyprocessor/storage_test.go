@@ -0,0 +1,116 @@
+package metricfrequencyprocessor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension/xextension/storage"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+)
+
+type memoryStorageClient struct {
+	data   map[string][]byte
+	closed bool
+}
+
+func (c *memoryStorageClient) Get(_ context.Context, key string) ([]byte, error) {
+	return c.data[key], nil
+}
+
+func (c *memoryStorageClient) Set(_ context.Context, key string, value []byte) error {
+	c.data[key] = value
+	return nil
+}
+
+func (c *memoryStorageClient) Delete(_ context.Context, key string) error {
+	delete(c.data, key)
+	return nil
+}
+
+func (c *memoryStorageClient) Batch(context.Context, ...*storage.Operation) error {
+	return nil
+}
+
+func (c *memoryStorageClient) Close(context.Context) error {
+	c.closed = true
+	return nil
+}
+
+type memoryStorageExtension struct {
+	component.StartFunc
+	component.ShutdownFunc
+
+	client *memoryStorageClient
+}
+
+func (e *memoryStorageExtension) GetClient(context.Context, component.Kind, component.ID, string) (storage.Client, error) {
+	e.client.closed = false
+	return e.client, nil
+}
+
+type hostWithExtensions struct {
+	extensions map[component.ID]component.Component
+}
+
+func (h *hostWithExtensions) GetExtensions() map[component.ID]component.Component {
+	return h.extensions
+}
+
+var storageID = component.MustNewID("memory_storage")
+
+func newStorageHost() (*hostWithExtensions, *memoryStorageClient) {
+	client := &memoryStorageClient{data: map[string][]byte{}}
+	return &hostWithExtensions{
+		extensions: map[component.ID]component.Component{
+			storageID: &memoryStorageExtension{client: client},
+		},
+	}, client
+}
+
+func newStorageProcessor(t *testing.T) *metricsfrequencyprocessor {
+	config := createDefaultConfig().(*Config)
+	config.MinPointAccumulationTime = time.Minute
+	return &metricsfrequencyprocessor{
+		sieve:              newTestSieve(t, config),
+		id:                 component.NewID(Type),
+		logger:             zap.NewNop(),
+		storageID:          &storageID,
+		checkpointInterval: time.Hour,
+	}
+}
+
+func TestStateSurvivesRestart(t *testing.T) {
+	host, client := newStorageHost()
+	start := time.Now().Add(-time.Hour)
+
+	processor := newStorageProcessor(t)
+	require.NoError(t, processor.Start(context.Background(), host))
+	for i := 0; i <= 2; i++ {
+		processor.sieve.Sift(metricScope{}, pmetric.NewMetricSlice(), dataPointsToMetric(map[time.Time]float64{minutesAfter(start, i): 1.0}))
+	}
+	require.NoError(t, processor.Shutdown(context.Background()))
+	assert.True(t, client.closed)
+	assert.Contains(t, client.data, checkpointStorageKey)
+
+	restarted := newStorageProcessor(t)
+	require.NoError(t, restarted.Start(context.Background(), host))
+	defer func() { require.NoError(t, restarted.Shutdown(context.Background())) }()
+
+	// the metric is known to be constant, so it is sifted right away instead of after warm up
+	removed := restarted.sieve.Sift(metricScope{}, pmetric.NewMetricSlice(), dataPointsToMetric(map[time.Time]float64{minutesAfter(start, 3): 1.0}))
+	assert.True(t, removed)
+}
+
+func TestStartWithMissingStorage(t *testing.T) {
+	processor := newStorageProcessor(t)
+	missing := component.MustNewID("missing")
+	processor.storageID = &missing
+
+	err := processor.Start(context.Background(), &hostWithExtensions{})
+	assert.EqualError(t, err, "storage extension 'missing' not found")
+}
@@ -0,0 +1,238 @@
+package metricfrequencyprocessor
+
+import (
+	"math"
+	"slices"
+	"sort"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+// minWindowCapacity is the initial capacity of a window buffer. Buffers grow up to max_points_per_series
+// only when needed, so that series reporting rarely do not preallocate memory for the maximum number of data points.
+const minWindowCapacity = 8
+
+// window keeps recent data points of a time series ordered by timestamp, along with statistics used to categorise
+// the series. Statistics are updated incrementally, so that categorising a data point does not require sorting
+// all the cached ones:
+//   - data points are kept in a ring buffer, as they usually arrive in timestamp order and expire oldest first,
+//   - values are additionally kept sorted, which gives exact quartiles, minimum and maximum. Data points leave
+//     the window in arbitrary value order, which rules out streaming quantile sketches, while keeping a sorted
+//     slice bounded by max_points_per_series only takes a binary search and a short copy per data point,
+//   - variation is a running sum of absolute differences between consecutive data points.
+//
+// window is not safe for concurrent use, it is guarded by the lock of its series.
+type window struct {
+	points []cachedDataPoint
+	head   int
+	size   int
+
+	sorted    []float64
+	variation float64
+}
+
+func (w *window) len() int {
+	return w.size
+}
+
+// at returns i-th oldest data point of the window.
+func (w *window) at(i int) cachedDataPoint {
+	return w.points[(w.head+i)%len(w.points)]
+}
+
+func (w *window) set(i int, point cachedDataPoint) {
+	w.points[(w.head+i)%len(w.points)] = point
+}
+
+// earliest returns timestamp of the oldest data point. The window must not be empty.
+func (w *window) earliest() pcommon.Timestamp {
+	return w.at(0).Timestamp
+}
+
+// min returns the lowest value in the window. The window must not be empty.
+func (w *window) min() float64 {
+	return w.sorted[0]
+}
+
+// max returns the highest value in the window. The window must not be empty.
+func (w *window) max() float64 {
+	return w.sorted[len(w.sorted)-1]
+}
+
+// quartiles returns quantiles .25 and .75 of values in the window. The window must not be empty.
+func (w *window) quartiles() (float64, float64) {
+	return w.sorted[w.size/4], w.sorted[3*w.size/4]
+}
+
+// totalVariation returns a sum of absolute values of differences of subsequent data points.
+func (w *window) totalVariation() float64 {
+	// incremental updates may leave a rounding error behind
+	return math.Max(w.variation, 0)
+}
+
+// add inserts the data point at its timestamp position, replacing a data point with the same timestamp.
+// When the window is full, the oldest data point is removed first. It returns the number of removed data points.
+func (w *window) add(point cachedDataPoint, maxPoints int) int {
+	i, found := w.search(point.Timestamp)
+	if found {
+		w.replace(i, point)
+		return 0
+	}
+
+	removed := 0
+	if w.size >= maxPoints {
+		w.removeFirst()
+		removed++
+		i = max(i-1, 0)
+	}
+	w.insert(i, point, maxPoints)
+
+	return removed
+}
+
+// search returns position of the first data point not older than the timestamp and whether it has the same timestamp.
+func (w *window) search(timestamp pcommon.Timestamp) (int, bool) {
+	// fast path for data points arriving in order
+	if w.size == 0 || w.at(w.size-1).Timestamp < timestamp {
+		return w.size, false
+	}
+
+	i := sort.Search(w.size, func(i int) bool {
+		return w.at(i).Timestamp >= timestamp
+	})
+	return i, i < w.size && w.at(i).Timestamp == timestamp
+}
+
+func (w *window) insert(i int, point cachedDataPoint, maxPoints int) {
+	if w.size == len(w.points) {
+		w.grow(maxPoints)
+	}
+
+	for j := w.size; j > i; j-- {
+		w.set(j, w.at(j-1))
+	}
+	w.set(i, point)
+	w.size++
+
+	hasPrevious, hasNext := i > 0, i < w.size-1
+	if hasPrevious {
+		w.variation += math.Abs(point.Value - w.at(i-1).Value)
+	}
+	if hasNext {
+		w.variation += math.Abs(w.at(i+1).Value - point.Value)
+	}
+	if hasPrevious && hasNext {
+		w.variation -= math.Abs(w.at(i+1).Value - w.at(i-1).Value)
+	}
+	w.insertSorted(point.Value)
+}
+
+func (w *window) replace(i int, point cachedDataPoint) {
+	previous := w.at(i)
+	if i > 0 {
+		neighbour := w.at(i - 1).Value
+		w.variation += math.Abs(point.Value-neighbour) - math.Abs(previous.Value-neighbour)
+	}
+	if i < w.size-1 {
+		neighbour := w.at(i + 1).Value
+		w.variation += math.Abs(neighbour-point.Value) - math.Abs(neighbour-previous.Value)
+	}
+	w.removeSorted(previous.Value)
+	w.insertSorted(point.Value)
+	w.set(i, point)
+}
+
+func (w *window) removeFirst() {
+	first := w.at(0)
+	if w.size > 1 {
+		w.variation -= math.Abs(w.at(1).Value - first.Value)
+	}
+	w.removeSorted(first.Value)
+
+	w.set(0, cachedDataPoint{})
+	w.head = (w.head + 1) % len(w.points)
+	w.size--
+	if w.size == 0 {
+		w.head = 0
+		w.variation = 0
+	}
+}
+
+// grow doubles capacity of the ring buffer, up to maxPoints.
+func (w *window) grow(maxPoints int) {
+	capacity := min(max(2*len(w.points), minWindowCapacity), max(maxPoints, w.size+1))
+	points := make([]cachedDataPoint, capacity)
+	for i := 0; i < w.size; i++ {
+		points[i] = w.at(i)
+	}
+	w.points = points
+	w.head = 0
+}
+
+func (w *window) insertSorted(value float64) {
+	i, _ := slices.BinarySearch(w.sorted, value)
+	w.sorted = slices.Insert(w.sorted, i, value)
+}
+
+func (w *window) removeSorted(value float64) {
+	if i, found := slices.BinarySearch(w.sorted, value); found {
+		w.sorted = slices.Delete(w.sorted, i, i+1)
+	}
+}
+
+// expireOldest removes expired data points from the beginning of the window and returns their number.
+// As data points usually arrive in timestamp order, these are the ones expiring first.
+func (w *window) expireOldest(now time.Time) int {
+	expired := 0
+	for w.size > 0 && !now.Before(w.at(0).expires) {
+		w.removeFirst()
+		expired++
+	}
+
+	return expired
+}
+
+// deleteExpired removes all expired data points and returns their number.
+func (w *window) deleteExpired(now time.Time) int {
+	expired := w.expireOldest(now)
+
+	// data points which arrived out of order may expire before older ones
+	var kept []cachedDataPoint
+	for i := 0; i < w.size; i++ {
+		point := w.at(i)
+		switch {
+		case kept != nil && now.Before(point.expires):
+			kept = append(kept, point)
+		case kept == nil && !now.Before(point.expires):
+			kept = make([]cachedDataPoint, 0, w.size)
+			for j := 0; j < i; j++ {
+				kept = append(kept, w.at(j))
+			}
+		}
+	}
+	if kept == nil {
+		return expired
+	}
+
+	expired += w.size - len(kept)
+	w.reset(kept)
+	return expired
+}
+
+// reset replaces content of the window with given data points, ordered by timestamp, recomputing all statistics.
+func (w *window) reset(points []cachedDataPoint) {
+	w.points = points
+	w.head = 0
+	w.size = len(points)
+
+	w.sorted = make([]float64, 0, len(points))
+	w.variation = 0
+	for i, point := range points {
+		w.sorted = append(w.sorted, point.Value)
+		if i > 0 {
+			w.variation += math.Abs(point.Value - points[i-1].Value)
+		}
+	}
+	slices.Sort(w.sorted)
+}
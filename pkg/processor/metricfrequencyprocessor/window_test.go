@@ -0,0 +1,99 @@
+package metricfrequencyprocessor
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+func TestWindowAdd(t *testing.T) {
+	w := &window{}
+	assert.Equal(t, 0, w.add(windowPoint(2, 5.0, 0), 3))
+	assert.Equal(t, 0, w.add(windowPoint(0, 1.0, 0), 3))
+	assert.Equal(t, 0, w.add(windowPoint(1, 3.0, 0), 3))
+
+	assertWindow(t, w, []float64{1.0, 3.0, 5.0})
+	assert.InDelta(t, 4.0, w.totalVariation(), float64EqualityThreshold)
+
+	// replacing a data point does not evict anything
+	assert.Equal(t, 0, w.add(windowPoint(1, 0.0, 0), 3))
+	assertWindow(t, w, []float64{1.0, 0.0, 5.0})
+	assert.InDelta(t, 6.0, w.totalVariation(), float64EqualityThreshold)
+
+	// adding to a full window evicts the oldest data point
+	assert.Equal(t, 1, w.add(windowPoint(3, 5.0, 0), 3))
+	assertWindow(t, w, []float64{0.0, 5.0, 5.0})
+	assert.InDelta(t, 5.0, w.totalVariation(), float64EqualityThreshold)
+	assert.Equal(t, pcommon.NewTimestampFromTime(time.Unix(1, 0)), w.earliest())
+}
+
+func TestWindowExpire(t *testing.T) {
+	w := &window{}
+	w.add(windowPoint(0, 1.0, 1), 10)
+	w.add(windowPoint(2, 2.0, 2), 10)
+	// arrives out of order and expires later than the next one
+	w.add(windowPoint(1, 3.0, 4), 10)
+	w.add(windowPoint(3, 4.0, 3), 10)
+
+	assert.Equal(t, 1, w.expireOldest(time.Unix(3, 0)))
+	assertWindow(t, w, []float64{3.0, 2.0, 4.0})
+
+	assert.Equal(t, 2, w.deleteExpired(time.Unix(3, 0)))
+	assertWindow(t, w, []float64{3.0})
+	assert.Equal(t, 0.0, w.totalVariation())
+}
+
+func TestWindowMatchesFullRecomputation(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	w := &window{}
+	const maxPoints = 16
+
+	for i := 0; i < 10000; i++ {
+		// mostly in order, sometimes out of order or replacing an existing data point
+		timestamp := int64(i)
+		if random.Intn(5) == 0 {
+			timestamp -= int64(random.Intn(maxPoints))
+		}
+		w.add(windowPoint(timestamp, math.Round(random.NormFloat64()*100)/10, int64(i)), maxPoints)
+		if random.Intn(20) == 0 {
+			w.deleteExpired(time.Unix(int64(i-maxPoints/2), 0))
+		}
+
+		values := make([]float64, w.len())
+		expectedVariation := 0.0
+		for j := range values {
+			values[j] = w.at(j).Value
+			if j > 0 {
+				require.Less(t, w.at(j-1).Timestamp, w.at(j).Timestamp)
+				expectedVariation += math.Abs(values[j] - values[j-1])
+			}
+		}
+		slices.Sort(values)
+		require.Equal(t, values, w.sorted)
+		require.InDelta(t, expectedVariation, w.totalVariation(), 1e-6)
+	}
+}
+
+func windowPoint(unix int64, value float64, expiresUnix int64) cachedDataPoint {
+	return cachedDataPoint{
+		DataPoint: DataPoint{Timestamp: pcommon.NewTimestampFromTime(time.Unix(unix, 0)), Value: value},
+		expires:   time.Unix(expiresUnix, 0),
+	}
+}
+
+func assertWindow(t *testing.T, w *window, expected []float64) {
+	values := make([]float64, 0, w.len())
+	for i := 0; i < w.len(); i++ {
+		values = append(values, w.at(i).Value)
+	}
+	assert.Equal(t, expected, values)
+
+	slices.Sort(expected)
+	assert.Equal(t, expected, w.sorted)
+}
@@ -15,47 +15,22 @@
 package sourceprocessor
 
 import (
-	"fmt"
-	"regexp"
 	"strings"
 
 	"go.opentelemetry.io/collector/pdata/pcommon"
 )
 
-var (
-	formatRegex *regexp.Regexp
-)
-
-func init() {
-	var err error
-	formatRegex, err = regexp.Compile(`\%\{([\w\.\/]+)\}`)
-	if err != nil {
-		panic("failed to parse regex: " + err.Error())
-	}
-}
-
 type attributeFiller struct {
 	name            string
-	compiledFormat  string
+	template        sourceTemplate
 	dashReplacement string
-	prefix          string
-	labels          []string
 }
 
 func extractFormat(format string, name string) attributeFiller {
-	labels := make([]string, 0)
-	matches := formatRegex.FindAllStringSubmatch(format, -1)
-	for _, matchset := range matches {
-		labels = append(labels, matchset[1])
-	}
-	template := formatRegex.ReplaceAllString(format, "%s")
-
 	return attributeFiller{
 		name:            name,
-		compiledFormat:  template,
+		template:        parseTemplateOrLiteral(format),
 		dashReplacement: "",
-		labels:          labels,
-		prefix:          "",
 	}
 }
 
@@ -86,35 +61,18 @@ func (f *attributeFiller) fillResourceOrUseAnnotation(atts *pcommon.Map, annotat
 func (f *attributeFiller) useAnnotation(atts *pcommon.Map, annotation pcommon.Value) bool {
 	annotationFiller := extractFormat(annotation.Str(), f.name)
 	annotationFiller.dashReplacement = f.dashReplacement
-	annotationFiller.compiledFormat = f.prefix + annotationFiller.compiledFormat
 	return annotationFiller.fillAttributes(atts)
 }
 
 func (f *attributeFiller) fillAttributes(atts *pcommon.Map) bool {
-	if len(f.compiledFormat) == 0 {
+	if f.template.isEmpty() {
 		return false
 	}
 
-	labelValues := f.resourceLabelValues(atts)
-	if labelValues != nil {
-		str := fmt.Sprintf(f.compiledFormat, labelValues...)
-		if f.dashReplacement != "" {
-			str = strings.ReplaceAll(str, "-", f.dashReplacement)
-		}
-		atts.PutStr(f.name, str)
-		return true
-	}
-	return false
-}
-
-func (f *attributeFiller) resourceLabelValues(atts *pcommon.Map) []interface{} {
-	arr := make([]interface{}, 0)
-	for _, label := range f.labels {
-		if value, found := atts.Get(label); found {
-			arr = append(arr, value.Str())
-		} else {
-			arr = append(arr, "undefined")
-		}
+	str := f.template.render(*atts)
+	if f.dashReplacement != "" {
+		str = strings.ReplaceAll(str, "-", f.dashReplacement)
 	}
-	return arr
+	atts.PutStr(f.name, str)
+	return true
 }
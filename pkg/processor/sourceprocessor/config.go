@@ -14,8 +14,21 @@
 
 package sourceprocessor
 
+import (
+	"fmt"
+	"regexp"
+
+	"go.opentelemetry.io/collector/component"
+)
+
 // Config defines configuration for Source processor.
 type Config struct {
+	// Mode is one of: auto, kubernetes, host.
+	// In the kubernetes mode, the top level source templates are used, while in the host mode,
+	// the templates from the Host section are. In the auto mode, which is the default, the host mode
+	// templates are used for resources without Kubernetes pod attributes.
+	Mode string `mapstructure:"mode"`
+
 	Collector                 string `mapstructure:"collector"`
 	SourceHost                string `mapstructure:"source_host"`
 	SourceName                string `mapstructure:"source_name"`
@@ -36,6 +49,91 @@ type Config struct {
 	PodTemplateHashKey        string `mapstructure:"pod_template_hash_key"`
 
 	ContainerAnnotations ContainerAnnotationsConfig `mapstructure:"container_annotations"`
+
+	// Host defines the source templates for resources not coming from Kubernetes.
+	Host HostConfig `mapstructure:"host"`
+
+	// SourceRules is an ordered list of rules, each supplying its own source templates
+	// for resources matching its conditions.
+	// The first matching rule is used. Resources not matching any rule use
+	// the templates from the top level configuration, or the Host section in the host mode,
+	// which also supply the templates not set in the matching rule.
+	SourceRules []SourceRuleConfig `mapstructure:"source_rules"`
+
+	// EnvelopeParsers is an ordered list of parsers unwrapping log records sent in an envelope,
+	// e.g. by a container runtime. The first parser recognizing the log body is used.
+	EnvelopeParsers []EnvelopeParserConfig `mapstructure:"envelope_parsers"`
+
+	// Cache configures caching of the source attributes and exclusion decisions per resource.
+	Cache CacheConfig `mapstructure:"cache"`
+
+	// SamplingRules is an ordered list of rules sampling and rate limiting log records per source category.
+	// The first rule matching the source category of the records is used.
+	SamplingRules []SamplingRuleConfig `mapstructure:"sampling_rules"`
+}
+
+// SamplingRuleConfig defines sampling and rate limiting of records for matching source categories.
+type SamplingRuleConfig struct {
+	// SourceCategory is a regex for the `_sourceCategory` of the records.
+	// A rule without it matches all source categories.
+	SourceCategory string `mapstructure:"source_category"`
+	// SamplingPercentage is the percentage of records kept, all records are kept if it's not set.
+	SamplingPercentage *float64 `mapstructure:"sampling_percentage"`
+	// RateLimit is the maximum number of log records per second for each of the source categories.
+	// Records aren't rate limited if it's not set.
+	RateLimit float64 `mapstructure:"rate_limit"`
+	// Burst is the maximum number of records sent at once, exceeding the rate limit.
+	// Defaults to the rate limit, i.e. one second worth of records.
+	Burst int `mapstructure:"burst"`
+}
+
+// CacheConfig configures caching of the processing results per resource.
+type CacheConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Size is the maximum number of cached resources, the least recently used are evicted first.
+	Size int `mapstructure:"size"`
+}
+
+// EnvelopeParserConfig defines a log envelope parser.
+type EnvelopeParserConfig struct {
+	// Type is one of: docker, cri, json.
+	Type string `mapstructure:"type"`
+
+	// The following fields are used by the json parser only.
+
+	// BodyField is the name of the field containing the log body.
+	BodyField string `mapstructure:"body_field"`
+	// AttributeFields are the names of fields added as record attributes.
+	AttributeFields []string `mapstructure:"attribute_fields"`
+	// TimestampField is the name of the field containing the record timestamp.
+	TimestampField string `mapstructure:"timestamp_field"`
+	// TimestampLayout is the Go time layout of the timestamp, RFC3339 by default.
+	// Numeric timestamps are treated as seconds since the epoch.
+	TimestampLayout string `mapstructure:"timestamp_layout"`
+}
+
+// HostConfig defines the source templates used in the host mode.
+type HostConfig struct {
+	SourceHost                string `mapstructure:"source_host"`
+	SourceName                string `mapstructure:"source_name"`
+	SourceCategory            string `mapstructure:"source_category"`
+	SourceCategoryPrefix      string `mapstructure:"source_category_prefix"`
+	SourceCategoryReplaceDash string `mapstructure:"source_category_replace_dash"`
+}
+
+// SourceRuleConfig defines source templates for resources matching its conditions.
+type SourceRuleConfig struct {
+	// Match is a mapping of resource attribute names to regexes for the attribute values.
+	// A resource matches the rule when all of the attributes are present and their values
+	// match the corresponding regexes. A rule without conditions matches all resources.
+	Match map[string]string `mapstructure:"match"`
+
+	// Templates used for resources matching the rule.
+	// Templates which are not set are taken from the top level configuration.
+	SourceHost           string `mapstructure:"source_host"`
+	SourceName           string `mapstructure:"source_name"`
+	SourceCategory       string `mapstructure:"source_category"`
+	SourceCategoryPrefix string `mapstructure:"source_category_prefix"`
 }
 
 type ContainerAnnotationsConfig struct {
@@ -43,3 +141,68 @@ type ContainerAnnotationsConfig struct {
 	ContainerNameKey string   `mapstructure:"container_name_key"`
 	Prefixes         []string `mapstructure:"prefixes"`
 }
+
+var _ component.Config = (*Config)(nil)
+
+// Validate checks if the processor configuration is valid
+func (cfg *Config) Validate() error {
+	switch cfg.Mode {
+	case modeAuto, modeKubernetes, modeHost:
+	default:
+		return fmt.Errorf("unknown mode %q, expected one of: %s, %s, %s", cfg.Mode, modeAuto, modeKubernetes, modeHost)
+	}
+
+	if err := validateTemplates(cfg.Host.SourceHost, cfg.Host.SourceName, cfg.Host.SourceCategoryPrefix+cfg.Host.SourceCategory); err != nil {
+		return fmt.Errorf("host: %w", err)
+	}
+
+	if err := validateTemplates(cfg.SourceHost, cfg.SourceName, cfg.SourceCategoryPrefix+cfg.SourceCategory); err != nil {
+		return err
+	}
+
+	for i, rule := range cfg.SourceRules {
+		for field, regex := range rule.Match {
+			if _, err := regexp.Compile(regex); err != nil {
+				return fmt.Errorf("source_rules[%d]: invalid regex for %s: %w", i, field, err)
+			}
+		}
+		if err := validateTemplates(rule.SourceHost, rule.SourceName, rule.SourceCategoryPrefix+rule.SourceCategory); err != nil {
+			return fmt.Errorf("source_rules[%d]: %w", i, err)
+		}
+	}
+
+	if cfg.Cache.Enabled && cfg.Cache.Size <= 0 {
+		return fmt.Errorf("cache.size must be positive, got %d", cfg.Cache.Size)
+	}
+
+	for i, rule := range cfg.SamplingRules {
+		if _, err := regexp.Compile(rule.SourceCategory); err != nil {
+			return fmt.Errorf("sampling_rules[%d]: invalid source_category regex: %w", i, err)
+		}
+		if rule.SamplingPercentage != nil && (*rule.SamplingPercentage < 0 || *rule.SamplingPercentage > 100) {
+			return fmt.Errorf("sampling_rules[%d]: sampling_percentage must be between 0 and 100, got %v", i, *rule.SamplingPercentage)
+		}
+		if rule.RateLimit < 0 {
+			return fmt.Errorf("sampling_rules[%d]: rate_limit can't be negative, got %v", i, rule.RateLimit)
+		}
+		if rule.Burst < 0 {
+			return fmt.Errorf("sampling_rules[%d]: burst can't be negative, got %d", i, rule.Burst)
+		}
+	}
+
+	for i, parserCfg := range cfg.EnvelopeParsers {
+		if _, err := newEnvelopeParser(parserCfg); err != nil {
+			return fmt.Errorf("envelope_parsers[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func validateTemplates(templates ...string) error {
+	for _, template := range templates {
+		if _, err := parseTemplate(template); err != nil {
+			return err
+		}
+	}
+	return nil
+}
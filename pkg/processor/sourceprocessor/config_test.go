@@ -46,6 +46,7 @@ func TestLoadConfig(t *testing.T) {
 	assert.True(t, ok)
 
 	assert.Equal(t, p2, &Config{
+		Mode:                      "auto",
 		Collector:                 "somecollector",
 		SourceHost:                "%{k8s.pod.hostname}",
 		SourceName:                "%{k8s.namespace.name}.%{k8s.pod.name}.%{k8s.container.name}/foo",
@@ -73,5 +74,115 @@ func TestLoadConfig(t *testing.T) {
 				"sumologic.com/",
 			},
 		},
+
+		Host: HostConfig{
+			SourceHost:                "%{host.name}",
+			SourceName:                "%{log.file.path}",
+			SourceCategory:            "%{service.name:-unknown}",
+			SourceCategoryPrefix:      "hosts/",
+			SourceCategoryReplaceDash: "_",
+		},
+
+		SourceRules: []SourceRuleConfig{
+			{
+				Match: map[string]string{
+					"k8s.namespace.name": "^kube-.*$",
+				},
+				SourceCategory: "system/%{k8s.namespace.name}",
+			},
+			{
+				Match: map[string]string{
+					"k8s.deployment.name": "^api$",
+					"k8s.container.name":  "^server$",
+				},
+				SourceName:           "api.%{k8s.container.name}",
+				SourceCategory:       "%{k8s.deployment.name}",
+				SourceCategoryPrefix: "apps/",
+			},
+		},
+
+		EnvelopeParsers: []EnvelopeParserConfig{
+			{Type: "cri"},
+			{
+				Type:            "json",
+				BodyField:       "message",
+				AttributeFields: []string{"level", "logger"},
+				TimestampField:  "ts",
+				TimestampLayout: "2006-01-02 15:04:05",
+			},
+		},
+
+		Cache: CacheConfig{
+			Enabled: true,
+			Size:    500,
+		},
+
+		SamplingRules: []SamplingRuleConfig{
+			{
+				SourceCategory:     "^kubernetes/noisy/",
+				SamplingPercentage: ptr(10.0),
+			},
+			{
+				SourceCategory: "^kubernetes/",
+				RateLimit:      100,
+				Burst:          200,
+			},
+		},
 	})
 }
+
+func TestConfigValidate(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	assert.NoError(t, cfg.Validate())
+
+	cfg.SourceRules = []SourceRuleConfig{
+		{
+			Match:          map[string]string{"k8s.namespace.name": "^kube-.*$"},
+			SourceCategory: "system",
+		},
+		{
+			Match:          map[string]string{"k8s.namespace.name": "kube-(.*"},
+			SourceCategory: "invalid",
+		},
+	}
+	assert.ErrorContains(t, cfg.Validate(), "source_rules[1]: invalid regex for k8s.namespace.name")
+
+	cfg.SourceRules[1].Match = nil
+	cfg.SourceRules[1].SourceName = "%{k8s.pod.name|unknown}"
+	assert.ErrorContains(t, cfg.Validate(), `source_rules[1]: invalid placeholder in template "%{k8s.pod.name|unknown}"`)
+
+	cfg.SourceRules = nil
+	cfg.SourceCategory = "%{k8s.namespace.name"
+	assert.ErrorContains(t, cfg.Validate(), "invalid placeholder in template")
+
+	cfg = createDefaultConfig().(*Config)
+	cfg.Mode = "cluster"
+	assert.ErrorContains(t, cfg.Validate(), `unknown mode "cluster", expected one of: auto, kubernetes, host`)
+
+	cfg = createDefaultConfig().(*Config)
+	cfg.Host.SourceName = "%{log.file.path"
+	assert.ErrorContains(t, cfg.Validate(), "host: invalid placeholder in template")
+
+	cfg = createDefaultConfig().(*Config)
+	cfg.EnvelopeParsers = []EnvelopeParserConfig{{Type: "cri"}, {Type: "xml"}}
+	assert.ErrorContains(t, cfg.Validate(), `envelope_parsers[1]: unknown envelope parser type "xml"`)
+
+	cfg.EnvelopeParsers = []EnvelopeParserConfig{{Type: "json"}}
+	assert.ErrorContains(t, cfg.Validate(), "envelope_parsers[0]: body_field is required for json envelope parser")
+
+	cfg = createDefaultConfig().(*Config)
+	cfg.Cache.Size = 0
+	assert.ErrorContains(t, cfg.Validate(), "cache.size must be positive, got 0")
+	cfg.Cache.Enabled = false
+	assert.NoError(t, cfg.Validate())
+
+	cfg = createDefaultConfig().(*Config)
+	cfg.SamplingRules = []SamplingRuleConfig{{SourceCategory: "("}}
+	assert.ErrorContains(t, cfg.Validate(), "sampling_rules[0]: invalid source_category regex")
+	cfg.SamplingRules = []SamplingRuleConfig{{SamplingPercentage: ptr(101.0)}}
+	assert.ErrorContains(t, cfg.Validate(), "sampling_rules[0]: sampling_percentage must be between 0 and 100, got 101")
+	cfg.SamplingRules = []SamplingRuleConfig{{RateLimit: -1}}
+	assert.ErrorContains(t, cfg.Validate(), "sampling_rules[0]: rate_limit can't be negative, got -1")
+	cfg.SamplingRules = []SamplingRuleConfig{{RateLimit: 1, Burst: -1}}
+	assert.ErrorContains(t, cfg.Validate(), "sampling_rules[0]: burst can't be negative, got -1")
+}
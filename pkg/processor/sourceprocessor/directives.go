@@ -0,0 +1,201 @@
+// Copyright 2026 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sourceprocessor
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+// Names of the directives, used in pod and namespace annotations prefixed with "sumologic.com/",
+// and in container-level annotations prefixed with "<prefix><container name>.".
+const (
+	dropRegexDirective             = "dropRegex"
+	multilineStartPatternDirective = "multilineStartPattern"
+	severityAttributeDirective     = "severityAttribute"
+	redactFieldsDirective          = "redactFields"
+
+	directiveAnnotationPrefix = "sumologic.com/"
+
+	redactedValue = "[REDACTED]"
+)
+
+// logDirectives are per-pod log processing directives set in annotations.
+type logDirectives struct {
+	// drop drops log records with bodies matching the regex
+	drop *regexp.Regexp
+	// multilineStart merges log records not matching the regex into the previous record
+	multilineStart *regexp.Regexp
+	// severityAttribute is the record attribute the severity is set from
+	severityAttribute string
+	// redactFields are the record attributes and body fields which values are redacted
+	redactFields []string
+}
+
+// directiveValue returns the value of the directive from the annotations.
+//
+// The value is retrieved from one of the following locations, listed in descending order of precedence:
+// - the container-level annotation (e.g. "k8s.pod.annotation.sumologic.com/container-name.dropRegex"),
+// - the pod-level annotation (e.g. "k8s.pod.annotation.sumologic.com/dropRegex"),
+// - the namespace-level annotation (e.g. "k8s.namespace.annotation.sumologic.com/dropRegex").
+func (sp *sourceProcessor) directiveValue(atts pcommon.Map, directive string) (string, bool) {
+	if sp.containerAnnotations.Enabled {
+		if containerName, ok := atts.Get(sp.containerAnnotations.ContainerNameKey); ok && containerName.Str() != "" {
+			for _, prefix := range sp.containerAnnotations.Prefixes {
+				annotation := fmt.Sprintf("%s%s.%s", prefix, containerName.Str(), directive)
+				if value, ok := getAnnotationAttributeValue(sp.keys.annotationPrefix, annotation, &atts); ok {
+					return value, true
+				}
+			}
+		}
+	}
+
+	annotation := directiveAnnotationPrefix + directive
+	if value, ok := getAnnotationAttributeValue(sp.keys.annotationPrefix, annotation, &atts); ok {
+		return value, true
+	}
+	return getAnnotationAttributeValue(sp.keys.namespaceAnnotationPrefix, annotation, &atts)
+}
+
+// logDirectives returns the log processing directives for the resource, or nil if there are none.
+func (sp *sourceProcessor) logDirectives(atts pcommon.Map) *logDirectives {
+	directives := &logDirectives{}
+	found := false
+
+	compileDirectiveRegex := func(directive string) *regexp.Regexp {
+		value, ok := sp.directiveValue(atts, directive)
+		if !ok || value == "" {
+			return nil
+		}
+		re, err := regexp.Compile(value)
+		if err != nil {
+			sp.logger.Warn("Ignoring invalid regex in annotation", zap.String("directive", directive), zap.Error(err))
+			return nil
+		}
+		found = true
+		return re
+	}
+	directives.drop = compileDirectiveRegex(dropRegexDirective)
+	directives.multilineStart = compileDirectiveRegex(multilineStartPatternDirective)
+
+	if value, ok := sp.directiveValue(atts, severityAttributeDirective); ok && value != "" {
+		directives.severityAttribute = value
+		found = true
+	}
+
+	if value, ok := sp.directiveValue(atts, redactFieldsDirective); ok {
+		for _, field := range strings.Split(value, ",") {
+			if field = strings.TrimSpace(field); field != "" {
+				directives.redactFields = append(directives.redactFields, field)
+				found = true
+			}
+		}
+	}
+
+	if !found {
+		return nil
+	}
+	return directives
+}
+
+// apply applies the directives to the log records.
+func (d *logDirectives) apply(records plog.LogRecordSlice) {
+	if d.drop != nil {
+		records.RemoveIf(func(record plog.LogRecord) bool {
+			return record.Body().Type() == pcommon.ValueTypeStr && d.drop.MatchString(record.Body().Str())
+		})
+	}
+
+	if d.multilineStart != nil {
+		mergeMultiline(records, d.multilineStart)
+	}
+
+	for i := 0; i < records.Len(); i++ {
+		record := records.At(i)
+		if d.severityAttribute != "" {
+			setSeverity(record, d.severityAttribute)
+		}
+		for _, field := range d.redactFields {
+			redact(record, field)
+		}
+	}
+}
+
+// mergeMultiline appends the bodies of log records not matching the start pattern
+// to the previous record, removing them. Records are merged within a single batch only.
+func mergeMultiline(records plog.LogRecordSlice, start *regexp.Regexp) {
+	var current plog.LogRecord
+	hasCurrent := false
+	records.RemoveIf(func(record plog.LogRecord) bool {
+		if record.Body().Type() != pcommon.ValueTypeStr {
+			hasCurrent = false
+			return false
+		}
+		body := record.Body().Str()
+		if hasCurrent && !start.MatchString(body) {
+			current.Body().SetStr(current.Body().Str() + "\n" + body)
+			return true
+		}
+		current, hasCurrent = record, true
+		return false
+	})
+}
+
+// setSeverity sets the record severity from the attribute.
+func setSeverity(record plog.LogRecord, attribute string) {
+	value, ok := record.Attributes().Get(attribute)
+	if !ok {
+		return
+	}
+	severityText := value.AsString()
+	record.SetSeverityText(severityText)
+	record.SetSeverityNumber(severityNumber(severityText))
+}
+
+func severityNumber(severity string) plog.SeverityNumber {
+	switch strings.ToLower(strings.TrimSpace(severity)) {
+	case "trace":
+		return plog.SeverityNumberTrace
+	case "debug":
+		return plog.SeverityNumberDebug
+	case "info", "information", "notice":
+		return plog.SeverityNumberInfo
+	case "warn", "warning":
+		return plog.SeverityNumberWarn
+	case "error", "err":
+		return plog.SeverityNumberError
+	case "fatal", "critical", "crit", "panic", "emergency", "alert":
+		return plog.SeverityNumberFatal
+	default:
+		return plog.SeverityNumberUnspecified
+	}
+}
+
+// redact replaces the value of the record attribute and the top level body field with the given name.
+func redact(record plog.LogRecord, field string) {
+	if _, ok := record.Attributes().Get(field); ok {
+		record.Attributes().PutStr(field, redactedValue)
+	}
+	if record.Body().Type() == pcommon.ValueTypeMap {
+		if _, ok := record.Body().Map().Get(field); ok {
+			record.Body().Map().PutStr(field, redactedValue)
+		}
+	}
+}
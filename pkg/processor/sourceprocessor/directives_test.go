@@ -0,0 +1,139 @@
+// Copyright 2026 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sourceprocessor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+func newLogsWithBodies(resourceAttrs map[string]string, bodies ...string) plog.Logs {
+	logs := plog.NewLogs()
+	rl := logs.ResourceLogs().AppendEmpty()
+	for k, v := range resourceAttrs {
+		rl.Resource().Attributes().PutStr(k, v)
+	}
+	records := rl.ScopeLogs().AppendEmpty().LogRecords()
+	for _, body := range bodies {
+		records.AppendEmpty().Body().SetStr(body)
+	}
+	return logs
+}
+
+func logBodies(logs plog.Logs) []string {
+	var bodies []string
+	records := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	for i := 0; i < records.Len(); i++ {
+		bodies = append(bodies, records.At(i).Body().AsString())
+	}
+	return bodies
+}
+
+func TestLogDirectivesDropRegex(t *testing.T) {
+	attributes := createK8sLabels()
+	attributes["pod_annotation_sumologic.com/dropRegex"] = "^DEBUG"
+	sp := newSourceProcessor(newProcessorCreateSettings(), createConfig())
+
+	logs, err := sp.ProcessLogs(context.Background(), newLogsWithBodies(attributes, "DEBUG a", "INFO b", "DEBUG c", "ERROR d"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"INFO b", "ERROR d"}, logBodies(logs))
+}
+
+func TestLogDirectivesMultiline(t *testing.T) {
+	attributes := createK8sLabels()
+	attributes["namespace_annotation_sumologic.com/multilineStartPattern"] = `^\d{4}-`
+	sp := newSourceProcessor(newProcessorCreateSettings(), createConfig())
+
+	logs, err := sp.ProcessLogs(context.Background(), newLogsWithBodies(attributes,
+		"  continuation without start",
+		"2021-01-01 panic",
+		"goroutine 1",
+		"  main.go:10",
+		"2021-01-01 recovered",
+	))
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"  continuation without start",
+		"2021-01-01 panic\ngoroutine 1\n  main.go:10",
+		"2021-01-01 recovered",
+	}, logBodies(logs))
+}
+
+func TestLogDirectivesSeverityAndRedaction(t *testing.T) {
+	attributes := createK8sLabels()
+	attributes["pod_annotation_sumologic.com/severityAttribute"] = "level"
+	attributes["pod_annotation_sumologic.com/redactFields"] = "password, token"
+	sp := newSourceProcessor(newProcessorCreateSettings(), createConfig())
+
+	logs := newLogsWithBodies(attributes, "", "")
+	records := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	records.At(0).Attributes().PutStr("level", "WARNING")
+	records.At(0).Attributes().PutStr("password", "secret")
+	body := records.At(1).Body().SetEmptyMap()
+	body.PutStr("token", "secret")
+	body.PutStr("user", "admin")
+
+	logs, err := sp.ProcessLogs(context.Background(), logs)
+	require.NoError(t, err)
+
+	records = logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	assert.Equal(t, "WARNING", records.At(0).SeverityText())
+	assert.Equal(t, plog.SeverityNumberWarn, records.At(0).SeverityNumber())
+	assert.Equal(t, map[string]any{"level": "WARNING", "password": "[REDACTED]"}, records.At(0).Attributes().AsRaw())
+	assert.Equal(t, plog.SeverityNumberUnspecified, records.At(1).SeverityNumber())
+	assert.Equal(t, map[string]any{"token": "[REDACTED]", "user": "admin"}, records.At(1).Body().Map().AsRaw())
+}
+
+func TestLogDirectivesPrecedence(t *testing.T) {
+	config := createConfig()
+	config.ContainerAnnotations.Enabled = true
+
+	attributes := createK8sLabels()
+	attributes["namespace_annotation_sumologic.com/dropRegex"] = "a"
+	attributes["pod_annotation_sumologic.com/dropRegex"] = "b"
+	sp := newSourceProcessor(newProcessorCreateSettings(), config)
+
+	logs, err := sp.ProcessLogs(context.Background(), newLogsWithBodies(attributes, "a", "b", "c"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "c"}, logBodies(logs))
+
+	attributes["pod_annotation_sumologic.com/container-1.dropRegex"] = "c"
+	logs, err = sp.ProcessLogs(context.Background(), newLogsWithBodies(attributes, "a", "b", "c"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, logBodies(logs))
+
+	// container-level annotations are used only when enabled
+	config.ContainerAnnotations.Enabled = false
+	sp = newSourceProcessor(newProcessorCreateSettings(), config)
+	logs, err = sp.ProcessLogs(context.Background(), newLogsWithBodies(attributes, "a", "b", "c"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "c"}, logBodies(logs))
+}
+
+func TestLogDirectivesInvalid(t *testing.T) {
+	attributes := createK8sLabels()
+	attributes["pod_annotation_sumologic.com/dropRegex"] = "("
+	sp := newSourceProcessor(newProcessorCreateSettings(), createConfig())
+
+	assert.Nil(t, sp.logDirectives(newLogsWithBodies(attributes).ResourceLogs().At(0).Resource().Attributes()))
+
+	logs, err := sp.ProcessLogs(context.Background(), newLogsWithBodies(attributes, "(", "a"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"(", "a"}, logBodies(logs))
+}
@@ -0,0 +1,187 @@
+// Copyright 2026 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sourceprocessor
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+const (
+	envelopeParserDocker = "docker"
+	envelopeParserCRI    = "cri"
+	envelopeParserJSON   = "json"
+
+	streamAttribute = "stream"
+	timeAttribute   = "time"
+	logtagAttribute = "logtag"
+)
+
+// envelopeParser unwraps log records sent in an envelope, e.g. by a container runtime,
+// extracting the actual log line into the body and the envelope fields into the record.
+type envelopeParser interface {
+	// parse parses the body and updates the record, returning false if the body
+	// isn't in the parser's format, in which case the record is left unchanged.
+	parse(body string, record plog.LogRecord) bool
+}
+
+// newEnvelopeParser creates an envelope parser from its configuration.
+func newEnvelopeParser(cfg EnvelopeParserConfig) (envelopeParser, error) {
+	switch cfg.Type {
+	case envelopeParserDocker:
+		return dockerEnvelopeParser{}, nil
+	case envelopeParserCRI:
+		return criEnvelopeParser{}, nil
+	case envelopeParserJSON:
+		if cfg.BodyField == "" {
+			return nil, fmt.Errorf("body_field is required for %s envelope parser", envelopeParserJSON)
+		}
+		layout := cfg.TimestampLayout
+		if layout == "" {
+			layout = time.RFC3339Nano
+		}
+		return jsonEnvelopeParser{
+			bodyField:       cfg.BodyField,
+			attributeFields: cfg.AttributeFields,
+			timestampField:  cfg.TimestampField,
+			timestampLayout: layout,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown envelope parser type %q, expected one of: %s, %s, %s",
+			cfg.Type, envelopeParserDocker, envelopeParserCRI, envelopeParserJSON)
+	}
+}
+
+// dockerLog represents log from k8s using docker log driver send by FluentBit
+type dockerLog struct {
+	Stream string
+	Time   string
+	Log    string
+}
+
+// dockerEnvelopeParser parses logs written by the docker json-file log driver,
+// e.g. `{"log": "message\n", "stream": "stdout", "time": "2021-01-01T00:00:00.000000000Z"}`.
+type dockerEnvelopeParser struct{}
+
+func (dockerEnvelopeParser) parse(body string, record plog.LogRecord) bool {
+	var dockerLog dockerLog
+	err := json.Unmarshal([]byte(body), &dockerLog)
+
+	// If there was any parsing error or any of the expected key have no value
+	// skip extraction and leave log unchanged
+	if err != nil || dockerLog.Stream == "" || dockerLog.Time == "" || dockerLog.Log == "" {
+		return false
+	}
+
+	record.Attributes().PutStr(streamAttribute, dockerLog.Stream)
+	setTimestamp(record, dockerLog.Time, time.RFC3339Nano)
+	record.Body().SetStr(strings.TrimSpace(dockerLog.Log))
+	return true
+}
+
+// criEnvelopeParser parses logs written by CRI-O and containerd,
+// e.g. `2021-01-01T00:00:00.000000000Z stdout F message`.
+type criEnvelopeParser struct{}
+
+func (criEnvelopeParser) parse(body string, record plog.LogRecord) bool {
+	parts := strings.SplitN(body, " ", 4)
+	if len(parts) < 3 {
+		return false
+	}
+	timestamp, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return false
+	}
+	stream, logtag := parts[1], parts[2]
+	if stream != "stdout" && stream != "stderr" {
+		return false
+	}
+	// F is a full line, P is a partial one, with the remaining tags reserved for future use
+	if !strings.HasPrefix(logtag, "F") && !strings.HasPrefix(logtag, "P") {
+		return false
+	}
+
+	message := ""
+	if len(parts) == 4 {
+		message = parts[3]
+	}
+
+	record.Attributes().PutStr(streamAttribute, stream)
+	record.Attributes().PutStr(logtagAttribute, logtag)
+	record.SetTimestamp(pcommon.NewTimestampFromTime(timestamp))
+	record.Body().SetStr(strings.TrimRight(message, "\r\n"))
+	return true
+}
+
+// jsonEnvelopeParser parses JSON logs with configurable fields.
+type jsonEnvelopeParser struct {
+	bodyField       string
+	attributeFields []string
+	timestampField  string
+	timestampLayout string
+}
+
+func (p jsonEnvelopeParser) parse(body string, record plog.LogRecord) bool {
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(body), &fields); err != nil {
+		return false
+	}
+	logBody, ok := fields[p.bodyField]
+	if !ok {
+		return false
+	}
+
+	for _, field := range p.attributeFields {
+		if value, ok := fields[field]; ok {
+			// values which can't be represented as attributes are skipped
+			_ = record.Attributes().PutEmpty(field).FromRaw(value)
+		}
+	}
+
+	if p.timestampField != "" {
+		switch timestamp := fields[p.timestampField].(type) {
+		case string:
+			setTimestamp(record, timestamp, p.timestampLayout)
+		case float64:
+			// numeric timestamps are seconds since the epoch
+			seconds := int64(timestamp)
+			nanoseconds := int64((timestamp - float64(seconds)) * float64(time.Second))
+			record.SetTimestamp(pcommon.NewTimestampFromTime(time.Unix(seconds, nanoseconds)))
+		}
+	}
+
+	if s, ok := logBody.(string); ok {
+		record.Body().SetStr(strings.TrimSpace(s))
+	} else if err := record.Body().FromRaw(logBody); err != nil {
+		record.Body().SetStr(fmt.Sprint(logBody))
+	}
+	return true
+}
+
+// setTimestamp parses the timestamp into the record timestamp. If it can't be parsed,
+// it's added as the `time` attribute instead, so that it isn't lost.
+func setTimestamp(record plog.LogRecord, timestamp string, layout string) {
+	t, err := time.Parse(layout, timestamp)
+	if err != nil {
+		record.Attributes().PutStr(timeAttribute, timestamp)
+		return
+	}
+	record.SetTimestamp(pcommon.NewTimestampFromTime(t))
+}
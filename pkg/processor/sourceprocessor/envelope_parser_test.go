@@ -0,0 +1,195 @@
+// Copyright 2026 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sourceprocessor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+func TestEnvelopeParsers(t *testing.T) {
+	testcases := []struct {
+		name               string
+		parser             EnvelopeParserConfig
+		body               string
+		expectedParsed     bool
+		expectedBody       any
+		expectedAttributes map[string]any
+		expectedTimestamp  time.Time
+	}{
+		{
+			name:               "docker",
+			parser:             EnvelopeParserConfig{Type: "docker"},
+			body:               `{"log": "test\n", "stream": "stdout", "time": "2021-01-02T03:04:05.123456789Z"}`,
+			expectedParsed:     true,
+			expectedBody:       "test",
+			expectedAttributes: map[string]any{"stream": "stdout"},
+			expectedTimestamp:  time.Date(2021, 1, 2, 3, 4, 5, 123456789, time.UTC),
+		},
+		{
+			name:               "docker with invalid time",
+			parser:             EnvelopeParserConfig{Type: "docker"},
+			body:               `{"log": "test\n", "stream": "stdout", "time": "2021"}`,
+			expectedParsed:     true,
+			expectedBody:       "test",
+			expectedAttributes: map[string]any{"stream": "stdout", "time": "2021"},
+		},
+		{
+			name:               "docker with missing fields",
+			parser:             EnvelopeParserConfig{Type: "docker"},
+			body:               `{"log": "test\n", "stream": "stdout"}`,
+			expectedParsed:     false,
+			expectedBody:       `{"log": "test\n", "stream": "stdout"}`,
+			expectedAttributes: map[string]any{},
+		},
+		{
+			name:               "cri full line",
+			parser:             EnvelopeParserConfig{Type: "cri"},
+			body:               "2021-01-02T03:04:05.123456789+01:00 stderr F some message with spaces\n",
+			expectedParsed:     true,
+			expectedBody:       "some message with spaces",
+			expectedAttributes: map[string]any{"stream": "stderr", "logtag": "F"},
+			expectedTimestamp:  time.Date(2021, 1, 2, 2, 4, 5, 123456789, time.UTC),
+		},
+		{
+			name:               "cri partial empty line",
+			parser:             EnvelopeParserConfig{Type: "cri"},
+			body:               "2021-01-02T03:04:05Z stdout P",
+			expectedParsed:     true,
+			expectedBody:       "",
+			expectedAttributes: map[string]any{"stream": "stdout", "logtag": "P"},
+			expectedTimestamp:  time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+		},
+		{
+			name:               "cri invalid stream",
+			parser:             EnvelopeParserConfig{Type: "cri"},
+			body:               "2021-01-02T03:04:05Z stdin F message",
+			expectedParsed:     false,
+			expectedBody:       "2021-01-02T03:04:05Z stdin F message",
+			expectedAttributes: map[string]any{},
+		},
+		{
+			name:               "cri not a timestamp",
+			parser:             EnvelopeParserConfig{Type: "cri"},
+			body:               "some log stdout F message",
+			expectedParsed:     false,
+			expectedBody:       "some log stdout F message",
+			expectedAttributes: map[string]any{},
+		},
+		{
+			name: "json",
+			parser: EnvelopeParserConfig{
+				Type:            "json",
+				BodyField:       "msg",
+				AttributeFields: []string{"level", "count", "missing"},
+				TimestampField:  "ts",
+				TimestampLayout: "2006-01-02 15:04:05",
+			},
+			body:               `{"msg": "test ", "level": "info", "count": 3, "ts": "2021-01-02 03:04:05", "other": "x"}`,
+			expectedParsed:     true,
+			expectedBody:       "test",
+			expectedAttributes: map[string]any{"level": "info", "count": float64(3)},
+			expectedTimestamp:  time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+		},
+		{
+			name: "json with numeric timestamp and structured body",
+			parser: EnvelopeParserConfig{
+				Type:           "json",
+				BodyField:      "msg",
+				TimestampField: "ts",
+			},
+			body:               `{"msg": {"key": "value"}, "ts": 1609556645.5}`,
+			expectedParsed:     true,
+			expectedBody:       map[string]any{"key": "value"},
+			expectedAttributes: map[string]any{},
+			expectedTimestamp:  time.Date(2021, 1, 2, 3, 4, 5, 500000000, time.UTC),
+		},
+		{
+			name:               "json without body field",
+			parser:             EnvelopeParserConfig{Type: "json", BodyField: "msg"},
+			body:               `{"log": "test"}`,
+			expectedParsed:     false,
+			expectedBody:       `{"log": "test"}`,
+			expectedAttributes: map[string]any{},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			parser, err := newEnvelopeParser(tc.parser)
+			require.NoError(t, err)
+
+			record := plog.NewLogRecord()
+			record.Body().SetStr(tc.body)
+
+			assert.Equal(t, tc.expectedParsed, parser.parse(tc.body, record))
+			assert.Equal(t, tc.expectedBody, record.Body().AsRaw())
+			assert.Equal(t, tc.expectedAttributes, record.Attributes().AsRaw())
+			if tc.expectedTimestamp.IsZero() {
+				assert.Equal(t, pcommon.Timestamp(0), record.Timestamp())
+			} else {
+				assert.Equal(t, tc.expectedTimestamp, record.Timestamp().AsTime())
+			}
+		})
+	}
+}
+
+func TestLogProcessorEnvelopeParsers(t *testing.T) {
+	config := createDefaultConfig().(*Config)
+	config.EnvelopeParsers = []EnvelopeParserConfig{
+		{Type: "cri"},
+		{Type: "docker"},
+		{Type: "json", BodyField: "message"},
+	}
+	sp := newSourceProcessor(newProcessorCreateSettings(), config)
+
+	inputLogs := plog.NewLogs()
+	records := inputLogs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	records.AppendEmpty().Body().SetStr("2021-01-02T03:04:05Z stdout F cri log")
+	records.AppendEmpty().Body().SetStr(`{"log": "docker log", "stream": "stdout", "time": "2021-01-02T03:04:05Z"}`)
+	records.AppendEmpty().Body().SetStr(`{"message": "json log"}`)
+	records.AppendEmpty().Body().SetStr("plain log")
+	records.AppendEmpty().Body().SetInt(1)
+
+	processedLogs, err := sp.ProcessLogs(context.Background(), inputLogs)
+	require.NoError(t, err)
+
+	processedRecords := processedLogs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 5, processedRecords.Len())
+	assert.Equal(t, "cri log", processedRecords.At(0).Body().Str())
+	assert.Equal(t, "docker log", processedRecords.At(1).Body().Str())
+	assert.Equal(t, "json log", processedRecords.At(2).Body().Str())
+	assert.Equal(t, "plain log", processedRecords.At(3).Body().Str())
+	assert.Equal(t, int64(1), processedRecords.At(4).Body().Int())
+
+	t.Run("disabled", func(t *testing.T) {
+		config.EnvelopeParsers = nil
+		sp := newSourceProcessor(newProcessorCreateSettings(), config)
+
+		inputLogs := newLogsDataWithLogs(nil, nil)
+		body := `{"log": "docker log", "stream": "stdout", "time": "2021-01-02T03:04:05Z"}`
+		inputLogs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Body().SetStr(body)
+
+		processedLogs, err := sp.ProcessLogs(context.Background(), inputLogs)
+		require.NoError(t, err)
+		assert.Equal(t, body, processedLogs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Body().Str())
+	})
+}
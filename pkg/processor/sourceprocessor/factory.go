@@ -27,6 +27,7 @@ const (
 	// The value of "type" key in configuration.
 	typeStr = "source"
 
+	defaultMode      = modeAuto
 	defaultCollector = ""
 
 	defaultSourceHost                = "%{k8s.pod.hostname}"
@@ -42,6 +43,14 @@ const (
 	defaultPodTemplateHashKey        = "k8s.pod.label.pod-template-hash"
 	defaultContainerNameKey          = "k8s.container.name"
 
+	defaultHostSourceHost                = "%{host.name}"
+	defaultHostSourceName                = "%{log.file.path,_SYSTEMD_UNIT,winlog.channel,service.name}"
+	defaultHostSourceCategory            = "%{service.name,_SYSTEMD_UNIT,winlog.channel}"
+	defaultHostSourceCategoryPrefix      = "host/"
+	defaultHostSourceCategoryReplaceDash = "-"
+
+	defaultCacheSize = 10000
+
 	stabilityLevel = component.StabilityLevelBeta
 )
 
@@ -63,6 +72,7 @@ func NewFactory() processor.Factory {
 // createDefaultConfig creates the default configuration for processor.
 func createDefaultConfig() component.Config {
 	return &Config{
+		Mode:                      defaultMode,
 		Collector:                 defaultCollector,
 		SourceHost:                defaultSourceHost,
 		SourceName:                defaultSourceName,
@@ -83,6 +93,23 @@ func createDefaultConfig() component.Config {
 				"sumologic.com/",
 			},
 		},
+
+		Host: HostConfig{
+			SourceHost:                defaultHostSourceHost,
+			SourceName:                defaultHostSourceName,
+			SourceCategory:            defaultHostSourceCategory,
+			SourceCategoryPrefix:      defaultHostSourceCategoryPrefix,
+			SourceCategoryReplaceDash: defaultHostSourceCategoryReplaceDash,
+		},
+
+		EnvelopeParsers: []EnvelopeParserConfig{
+			{Type: envelopeParserDocker},
+		},
+
+		Cache: CacheConfig{
+			Enabled: true,
+			Size:    defaultCacheSize,
+		},
 	}
 }
 
@@ -21,6 +21,7 @@ import (
 
 	"go.opencensus.io/stats"
 	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
 )
 
 func init() {
@@ -28,6 +29,11 @@ func init() {
 		viewResourceSpansProcessed,
 		viewRecordsFilteredOut,
 		viewRecordsFilteredIn,
+		viewCacheHits,
+		viewCacheMisses,
+		viewCacheEvictions,
+		viewCacheSize,
+		viewRecordsDropped,
 	)
 	if err != nil {
 		fmt.Printf("Error registering source processor's views: %v\n", err)
@@ -39,6 +45,14 @@ var (
 	mResouceSpansProcessed = stats.Int64("otelsvc/sumo/resource_spans_processed", "Number of record span packages processed", "1")
 	mRecordsFilteredOut    = stats.Int64("otelsvc/sumo/records_filtered_out", "Number of records filtered out", "1")
 	mRecordsFilteredIn     = stats.Int64("otelsvc/sumo/records_filtered_in", "Number of records filtered in", "1")
+	mCacheHits             = stats.Int64("otelsvc/sumo/source_cache_hits", "Number of resources found in the source processor cache", "1")
+	mCacheMisses           = stats.Int64("otelsvc/sumo/source_cache_misses", "Number of resources not found in the source processor cache", "1")
+	mCacheEvictions        = stats.Int64("otelsvc/sumo/source_cache_evictions", "Number of resources evicted from the source processor cache", "1")
+	mCacheSize             = stats.Int64("otelsvc/sumo/source_cache_size", "Number of resources in the source processor cache", "1")
+	mRecordsDropped        = stats.Int64("otelsvc/sumo/records_dropped", "Number of records dropped by sampling or rate limiting", "1")
+
+	tagSourceCategory = tag.MustNewKey("source_category")
+	tagReason         = tag.MustNewKey("reason")
 )
 
 var viewResourceSpansProcessed = &view.View{
@@ -62,6 +76,42 @@ var viewRecordsFilteredIn = &view.View{
 	Aggregation: view.Sum(),
 }
 
+var viewCacheHits = &view.View{
+	Name:        mCacheHits.Name(),
+	Description: mCacheHits.Description(),
+	Measure:     mCacheHits,
+	Aggregation: view.Sum(),
+}
+
+var viewCacheMisses = &view.View{
+	Name:        mCacheMisses.Name(),
+	Description: mCacheMisses.Description(),
+	Measure:     mCacheMisses,
+	Aggregation: view.Sum(),
+}
+
+var viewCacheEvictions = &view.View{
+	Name:        mCacheEvictions.Name(),
+	Description: mCacheEvictions.Description(),
+	Measure:     mCacheEvictions,
+	Aggregation: view.Sum(),
+}
+
+var viewCacheSize = &view.View{
+	Name:        mCacheSize.Name(),
+	Description: mCacheSize.Description(),
+	Measure:     mCacheSize,
+	Aggregation: view.LastValue(),
+}
+
+var viewRecordsDropped = &view.View{
+	Name:        mRecordsDropped.Name(),
+	Description: mRecordsDropped.Description(),
+	Measure:     mRecordsDropped,
+	TagKeys:     []tag.Key{tagSourceCategory, tagReason},
+	Aggregation: view.Sum(),
+}
+
 // RecordResourceSpansProcessed increments the metric that resource spans package was processed
 func RecordResourceSpansProcessed() {
 	stats.Record(context.Background(), mResouceSpansProcessed.M(int64(1)))
@@ -86,3 +136,35 @@ func RecordFilteredIn() {
 func RecordFilteredInN(n int) {
 	stats.Record(context.Background(), mRecordsFilteredIn.M(int64(n)))
 }
+
+// RecordCacheHit increments the metric that records resources found in the cache
+func RecordCacheHit() {
+	stats.Record(context.Background(), mCacheHits.M(int64(1)))
+}
+
+// RecordCacheMiss increments the metric that records resources not found in the cache
+func RecordCacheMiss() {
+	stats.Record(context.Background(), mCacheMisses.M(int64(1)))
+}
+
+// RecordCacheEviction increments the metric that records resources evicted from the cache
+func RecordCacheEviction() {
+	stats.Record(context.Background(), mCacheEvictions.M(int64(1)))
+}
+
+// RecordCacheSize records the number of resources in the cache
+func RecordCacheSize(size int) {
+	stats.Record(context.Background(), mCacheSize.M(int64(size)))
+}
+
+// RecordDroppedN increments the metric that records records dropped for the source category
+func RecordDroppedN(sourceCategory string, reason string, n int) {
+	_ = stats.RecordWithTags(
+		context.Background(),
+		[]tag.Mutator{
+			tag.Upsert(tagSourceCategory, sourceCategory),
+			tag.Upsert(tagReason, reason),
+		},
+		mRecordsDropped.M(int64(n)),
+	)
+}
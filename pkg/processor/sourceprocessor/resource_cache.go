@@ -0,0 +1,82 @@
+// Copyright 2026 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sourceprocessor
+
+import (
+	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/pdatautil"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+
+	"github.com/SumoLogic/sumologic-otel-collector/pkg/processor/sourceprocessor/observability"
+)
+
+// resourceCacheEntry holds the results of processing a resource with a particular set of attributes.
+type resourceCacheEntry struct {
+	filteredOut bool
+	// sampling is the sampling policy for the records of the resource, nil if they aren't limited
+	sampling *samplingPolicy
+	// logDirectives are the log processing directives from annotations, nil if there are none
+	logDirectives *logDirectives
+	// sourceCategory is the source category of the resource
+	sourceCategory string
+	// attributes are the attributes set by the processor, e.g. the source attributes
+	attributes map[string]string
+}
+
+// resourceCache caches the results of processing resources, keyed by a hash of their attributes.
+//
+// All of the resource attributes are hashed, as templates in annotations can refer to any of them.
+// Resources from the same pod or container have the same attributes, so in practice
+// the number of entries is proportional to the number of containers.
+type resourceCache struct {
+	cache *lru.Cache[[16]byte, resourceCacheEntry]
+}
+
+func newResourceCache(size int) (*resourceCache, error) {
+	cache, err := lru.NewWithEvict(size, func([16]byte, resourceCacheEntry) {
+		observability.RecordCacheEviction()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &resourceCache{cache: cache}, nil
+}
+
+// get returns the cached entry for the attributes, if there is one.
+func (c *resourceCache) get(key [16]byte) (resourceCacheEntry, bool) {
+	entry, ok := c.cache.Get(key)
+	if ok {
+		observability.RecordCacheHit()
+	} else {
+		observability.RecordCacheMiss()
+	}
+	return entry, ok
+}
+
+func (c *resourceCache) add(key [16]byte, entry resourceCacheEntry) {
+	c.cache.Add(key, entry)
+	observability.RecordCacheSize(c.cache.Len())
+}
+
+func resourceCacheKey(atts pcommon.Map) [16]byte {
+	return pdatautil.MapHash(atts)
+}
+
+// apply sets the cached attributes on the resource attributes.
+func (e resourceCacheEntry) apply(atts pcommon.Map) {
+	for key, value := range e.attributes {
+		atts.PutStr(key, value)
+	}
+}
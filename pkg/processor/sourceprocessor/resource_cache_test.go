@@ -0,0 +1,87 @@
+// Copyright 2026 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sourceprocessor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+func TestResourceCache(t *testing.T) {
+	config := createConfig()
+	config.Exclude = map[string]string{
+		"k8s.namespace.name": "^excluded$",
+	}
+	config.Cache = CacheConfig{Enabled: true, Size: 2}
+	// the excluded resource has no pod attributes, the top level templates are used for it in the kubernetes mode
+	config.Mode = modeKubernetes
+	sp := newSourceProcessor(newProcessorCreateSettings(), config)
+	require.NotNil(t, sp.cache)
+
+	process := func(attributes map[string]string) plog.Logs {
+		logs, err := sp.ProcessLogs(context.Background(), newLogsDataWithLogs(attributes, nil))
+		require.NoError(t, err)
+		return logs
+	}
+
+	// the first time the resource is processed, the result is cached
+	logs := process(k8sLabels)
+	assert.Equal(t, mergedK8sLabels, toStringMap(logs.ResourceLogs().At(0).Resource().Attributes().AsRaw()))
+	assert.Equal(t, 1, sp.cache.cache.Len())
+
+	// the cached result is the same as the processed one
+	logs = process(k8sLabels)
+	assert.Equal(t, mergedK8sLabels, toStringMap(logs.ResourceLogs().At(0).Resource().Attributes().AsRaw()))
+	assert.Equal(t, 1, sp.cache.cache.Len())
+
+	// resources with different attributes are cached separately, including the exclusion decision
+	excluded := map[string]string{"k8s.namespace.name": "excluded"}
+	logs = process(excluded)
+	assert.Equal(t, 0, logs.ResourceLogs().At(0).ScopeLogs().Len())
+	assert.Equal(t, 2, sp.cache.cache.Len())
+
+	logs = process(excluded)
+	assert.Equal(t, 0, logs.ResourceLogs().At(0).ScopeLogs().Len())
+	assertAttribute(t, logs.ResourceLogs().At(0).Resource().Attributes(), "_sourceCategory", "prefix/excluded/undefined")
+
+	// the cache size is bounded
+	process(limitedLabels)
+	assert.Equal(t, 2, sp.cache.cache.Len())
+	logs = process(limitedLabels)
+	assert.Equal(t, limitedLabelsWithMeta, toStringMap(logs.ResourceLogs().At(0).Resource().Attributes().AsRaw()))
+}
+
+func TestResourceCacheDisabled(t *testing.T) {
+	config := createConfig()
+	config.Cache.Enabled = false
+	sp := newSourceProcessor(newProcessorCreateSettings(), config)
+	assert.Nil(t, sp.cache)
+
+	logs, err := sp.ProcessLogs(context.Background(), newLogsDataWithLogs(k8sLabels, nil))
+	require.NoError(t, err)
+	assert.Equal(t, mergedK8sLabels, toStringMap(logs.ResourceLogs().At(0).Resource().Attributes().AsRaw()))
+}
+
+func toStringMap(m map[string]any) map[string]string {
+	result := make(map[string]string, len(m))
+	for k, v := range m {
+		result[k] = v.(string)
+	}
+	return result
+}
@@ -0,0 +1,252 @@
+// Copyright 2026 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sourceprocessor
+
+import (
+	"math"
+	"math/rand/v2"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.uber.org/zap"
+
+	"github.com/SumoLogic/sumologic-otel-collector/pkg/processor/sourceprocessor/observability"
+)
+
+const (
+	samplingPercentageAnnotation = "sumologic.com/samplingPercentage"
+	rateLimitAnnotation          = "sumologic.com/rateLimit"
+
+	// maxRateLimiters bounds the number of source categories and policies for which
+	// the rate limiting state is kept, the least recently used are evicted first.
+	maxRateLimiters = 10000
+
+	droppedReasonSampled     = "sampled"
+	droppedReasonRateLimited = "rate_limited"
+)
+
+// samplingPolicy limits the number of records of a source category.
+type samplingPolicy struct {
+	// samplingPercentage is the percentage of records kept, in the [0, 100] range
+	samplingPercentage float64
+	// rateLimit is the maximum number of records per second, 0 means no limit
+	rateLimit float64
+	burst     int
+}
+
+// samplingRule applies a sampling policy to source categories matching its regex.
+type samplingRule struct {
+	sourceCategory *regexp.Regexp
+	policy         samplingPolicy
+}
+
+func newSamplingRule(cfg SamplingRuleConfig) samplingRule {
+	policy := samplingPolicy{
+		samplingPercentage: 100,
+		rateLimit:          cfg.RateLimit,
+		burst:              cfg.Burst,
+	}
+	if cfg.SamplingPercentage != nil {
+		policy.samplingPercentage = *cfg.SamplingPercentage
+	}
+	return samplingRule{
+		sourceCategory: compileRegex(cfg.SourceCategory),
+		policy:         policy,
+	}
+}
+
+// limiterKey identifies the rate limiting state. It's kept per source category and policy,
+// so that records of the same source category limited by different annotations, e.g. of two pods,
+// are limited separately, instead of changing the limit of a shared state back and forth.
+type limiterKey struct {
+	sourceCategory string
+	rateLimit      float64
+	burst          int
+}
+
+// sampler drops records exceeding the sampling policies of their source categories.
+type sampler struct {
+	logger *zap.Logger
+	rules  []samplingRule
+	// limiters hold the rate limiting state per source category and policy
+	limiters *lru.Cache[limiterKey, *tokenBucket]
+	now      func() time.Time
+}
+
+func newSampler(cfg *Config, logger *zap.Logger) *sampler {
+	rules := make([]samplingRule, 0, len(cfg.SamplingRules))
+	for _, ruleCfg := range cfg.SamplingRules {
+		rules = append(rules, newSamplingRule(ruleCfg))
+	}
+	limiters, _ := lru.New[limiterKey, *tokenBucket](maxRateLimiters)
+	return &sampler{
+		logger:   logger,
+		rules:    rules,
+		limiters: limiters,
+		now:      time.Now,
+	}
+}
+
+// policy returns the sampling policy for the resource, or nil if its records shouldn't be limited.
+//
+// The policy is retrieved from one of the following locations, listed in descending order of precedence:
+// - the pod-level annotations (e.g. "k8s.pod.annotation.sumologic.com/rateLimit"),
+// - the namespace-level annotations (e.g. "k8s.namespace.annotation.sumologic.com/rateLimit"),
+// - the first sampling rule matching the source category.
+func (s *sampler) policy(atts pcommon.Map, annotationPrefix string, namespaceAnnotationPrefix string) *samplingPolicy {
+	for _, prefix := range []string{annotationPrefix, namespaceAnnotationPrefix} {
+		if policy, ok := s.policyFromAnnotations(prefix, atts); ok {
+			return policy
+		}
+	}
+
+	sourceCategory, ok := atts.Get(sourceCategoryKey)
+	if !ok {
+		return nil
+	}
+	for i := range s.rules {
+		rule := &s.rules[i]
+		if rule.sourceCategory == nil || rule.sourceCategory.MatchString(sourceCategory.Str()) {
+			return &rule.policy
+		}
+	}
+	return nil
+}
+
+func (s *sampler) policyFromAnnotations(prefix string, atts pcommon.Map) (*samplingPolicy, bool) {
+	policy := &samplingPolicy{samplingPercentage: 100}
+	found := false
+
+	if value, ok := getAnnotationAttributeValue(prefix, samplingPercentageAnnotation, &atts); ok {
+		percentage, err := strconv.ParseFloat(value, 64)
+		if err != nil || percentage < 0 || percentage > 100 {
+			s.logger.Debug("Ignoring invalid sampling percentage annotation", zap.String("value", value))
+		} else {
+			policy.samplingPercentage = percentage
+			found = true
+		}
+	}
+
+	if value, ok := getAnnotationAttributeValue(prefix, rateLimitAnnotation, &atts); ok {
+		rateLimit, err := strconv.ParseFloat(value, 64)
+		if err != nil || rateLimit < 0 {
+			s.logger.Debug("Ignoring invalid rate limit annotation", zap.String("value", value))
+		} else {
+			policy.rateLimit = rateLimit
+			found = true
+		}
+	}
+
+	return policy, found
+}
+
+// recordLimiter decides whether the records of a single resource are kept.
+type recordLimiter struct {
+	sampler        *sampler
+	policy         *samplingPolicy
+	sourceCategory string
+	limiter        *tokenBucket
+	dropped        map[string]int
+}
+
+// limiter returns a recordLimiter for the records of a resource with the given policy.
+func (s *sampler) limiter(policy *samplingPolicy, sourceCategory string) *recordLimiter {
+	l := &recordLimiter{
+		sampler:        s,
+		policy:         policy,
+		sourceCategory: sourceCategory,
+		dropped:        map[string]int{},
+	}
+	if policy.rateLimit > 0 {
+		l.limiter = s.tokenBucket(sourceCategory, policy)
+	}
+	return l
+}
+
+func (s *sampler) tokenBucket(sourceCategory string, policy *samplingPolicy) *tokenBucket {
+	burst := float64(policy.burst)
+	if burst <= 0 {
+		// allow bursts of up to one second worth of records by default
+		burst = math.Max(1, policy.rateLimit)
+	}
+
+	key := limiterKey{sourceCategory: sourceCategory, rateLimit: policy.rateLimit, burst: policy.burst}
+	if bucket, ok := s.limiters.Get(key); ok {
+		return bucket
+	}
+	bucket := newTokenBucket(policy.rateLimit, burst, s.now())
+	// another goroutine might have added a bucket in the meantime, use that one
+	if previous, ok, _ := s.limiters.PeekOrAdd(key, bucket); ok {
+		return previous
+	}
+	return bucket
+}
+
+// allow returns true if the record should be kept.
+func (l *recordLimiter) allow() bool {
+	if l.policy.samplingPercentage < 100 && rand.Float64()*100 >= l.policy.samplingPercentage {
+		l.dropped[droppedReasonSampled]++
+		return false
+	}
+	if l.limiter != nil && !l.limiter.allow(l.sampler.now()) {
+		l.dropped[droppedReasonRateLimited]++
+		return false
+	}
+	return true
+}
+
+// report records the number of dropped records.
+func (l *recordLimiter) report() {
+	for reason, count := range l.dropped {
+		observability.RecordDroppedN(l.sourceCategory, reason, count)
+	}
+}
+
+// tokenBucket is a rate limiter allowing `rate` records per second, with bursts of up to `burst` records.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst float64, now time.Time) *tokenBucket {
+	return &tokenBucket{
+		rate:   rate,
+		burst:  burst,
+		tokens: burst,
+		last:   now,
+	}
+}
+
+func (b *tokenBucket) allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(b.burst, b.tokens+elapsed.Seconds()*b.rate)
+		b.last = now
+	}
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
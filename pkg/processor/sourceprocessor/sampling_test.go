@@ -0,0 +1,255 @@
+// Copyright 2026 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sourceprocessor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Unix(0, 0)
+	bucket := newTokenBucket(2, 3, now)
+
+	// the initial burst is allowed
+	for i := 0; i < 3; i++ {
+		assert.True(t, bucket.allow(now))
+	}
+	assert.False(t, bucket.allow(now))
+
+	// tokens are refilled at the configured rate
+	now = now.Add(500 * time.Millisecond)
+	assert.True(t, bucket.allow(now))
+	assert.False(t, bucket.allow(now))
+
+	// up to the burst
+	now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		assert.True(t, bucket.allow(now))
+	}
+	assert.False(t, bucket.allow(now))
+}
+
+func TestSamplerPolicy(t *testing.T) {
+	config := createConfig()
+	config.SamplingRules = []SamplingRuleConfig{
+		{SourceCategory: "^prefix/noisy/", RateLimit: 10},
+		{SourceCategory: "^prefix/", SamplingPercentage: ptr(50.0)},
+	}
+	s := newSampler(config, zap.NewNop())
+
+	policy := func(attributes map[string]string) *samplingPolicy {
+		atts := pcommon.NewMap()
+		for k, v := range attributes {
+			atts.PutStr(k, v)
+		}
+		return s.policy(atts, config.AnnotationPrefix, config.NamespaceAnnotationPrefix)
+	}
+
+	assert.Equal(t, &samplingPolicy{samplingPercentage: 100, rateLimit: 10}, policy(map[string]string{
+		"_sourceCategory": "prefix/noisy/app",
+	}))
+	assert.Equal(t, &samplingPolicy{samplingPercentage: 50}, policy(map[string]string{
+		"_sourceCategory": "prefix/app",
+	}))
+	assert.Nil(t, policy(map[string]string{
+		"_sourceCategory": "other/app",
+	}))
+	assert.Nil(t, policy(map[string]string{}))
+
+	// namespace annotations take precedence over rules
+	assert.Equal(t, &samplingPolicy{samplingPercentage: 100, rateLimit: 5}, policy(map[string]string{
+		"_sourceCategory": "prefix/noisy/app",
+		"namespace_annotation_sumologic.com/rateLimit": "5",
+	}))
+	// pod annotations take precedence over namespace annotations
+	assert.Equal(t, &samplingPolicy{samplingPercentage: 10}, policy(map[string]string{
+		"_sourceCategory": "prefix/noisy/app",
+		"namespace_annotation_sumologic.com/rateLimit":    "5",
+		"pod_annotation_sumologic.com/samplingPercentage": "10",
+	}))
+	// invalid annotations are ignored
+	assert.Equal(t, &samplingPolicy{samplingPercentage: 100, rateLimit: 10}, policy(map[string]string{
+		"_sourceCategory": "prefix/noisy/app",
+		"pod_annotation_sumologic.com/samplingPercentage": "150",
+		"pod_annotation_sumologic.com/rateLimit":          "fast",
+	}))
+}
+
+func TestLogsSampling(t *testing.T) {
+	newLogs := func(n int, resourceAttrs map[string]string) plog.Logs {
+		logs := newLogsDataWithLogs(resourceAttrs, nil)
+		records := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+		for i := 1; i < n; i++ {
+			records.AppendEmpty().Body().SetStr("dummy log")
+		}
+		return logs
+	}
+
+	t.Run("rate limit per source category", func(t *testing.T) {
+		config := createConfig()
+		config.SamplingRules = []SamplingRuleConfig{
+			{SourceCategory: "^prefix/namespace#1/", RateLimit: 5, Burst: 10},
+		}
+		sp := newSourceProcessor(newProcessorCreateSettings(), config)
+		now := time.Unix(0, 0)
+		sp.sampler.now = func() time.Time { return now }
+
+		logs, err := sp.ProcessLogs(context.Background(), newLogs(15, k8sLabels))
+		require.NoError(t, err)
+		assert.Equal(t, 10, logs.LogRecordCount())
+
+		logs, err = sp.ProcessLogs(context.Background(), newLogs(15, k8sLabels))
+		require.NoError(t, err)
+		assert.Equal(t, 0, logs.LogRecordCount())
+
+		now = now.Add(time.Second)
+		logs, err = sp.ProcessLogs(context.Background(), newLogs(15, k8sLabels))
+		require.NoError(t, err)
+		assert.Equal(t, 5, logs.LogRecordCount())
+
+		// other source categories aren't limited
+		logs, err = sp.ProcessLogs(context.Background(), newLogs(15, limitedLabels))
+		require.NoError(t, err)
+		assert.Equal(t, 15, logs.LogRecordCount())
+	})
+
+	t.Run("rate limit from annotations of pods with the same source category", func(t *testing.T) {
+		config := createConfig()
+		sp := newSourceProcessor(newProcessorCreateSettings(), config)
+		now := time.Unix(0, 0)
+		sp.sampler.now = func() time.Time { return now }
+
+		slow := createK8sLabels()
+		slow["pod_annotation_sumologic.com/rateLimit"] = "2"
+		fast := createK8sLabels()
+		fast["pod_annotation_sumologic.com/rateLimit"] = "10"
+		fast["k8s.pod.uid"] = "pod-5678"
+
+		// each of the policies is applied separately, instead of the last one seen
+		for i := 0; i < 3; i++ {
+			logs, err := sp.ProcessLogs(context.Background(), newLogs(20, slow))
+			require.NoError(t, err)
+			assert.Equal(t, 2, logs.LogRecordCount())
+
+			logs, err = sp.ProcessLogs(context.Background(), newLogs(20, fast))
+			require.NoError(t, err)
+			assert.Equal(t, 10, logs.LogRecordCount())
+
+			now = now.Add(time.Second)
+		}
+	})
+
+	t.Run("sampling", func(t *testing.T) {
+		config := createConfig()
+		config.SamplingRules = []SamplingRuleConfig{
+			{SourceCategory: "^prefix/undefined/", SamplingPercentage: ptr(0.0)},
+			{SamplingPercentage: ptr(100.0)},
+		}
+		sp := newSourceProcessor(newProcessorCreateSettings(), config)
+
+		logs, err := sp.ProcessLogs(context.Background(), newLogs(100, limitedLabels))
+		require.NoError(t, err)
+		assert.Equal(t, 0, logs.LogRecordCount())
+
+		logs, err = sp.ProcessLogs(context.Background(), newLogs(100, k8sLabels))
+		require.NoError(t, err)
+		assert.Equal(t, 100, logs.LogRecordCount())
+	})
+
+	t.Run("sampling from annotation", func(t *testing.T) {
+		config := createConfig()
+		sp := newSourceProcessor(newProcessorCreateSettings(), config)
+
+		attributes := createK8sLabels()
+		attributes["pod_annotation_sumologic.com/samplingPercentage"] = "50"
+
+		logs, err := sp.ProcessLogs(context.Background(), newLogs(1000, attributes))
+		require.NoError(t, err)
+		assert.Greater(t, logs.LogRecordCount(), 300)
+		assert.Less(t, logs.LogRecordCount(), 700)
+	})
+}
+
+func TestLogsSamplingAfterDirectives(t *testing.T) {
+	attributes := createK8sLabels()
+	attributes["pod_annotation_sumologic.com/multilineStartPattern"] = `^\d{4}-`
+	attributes["pod_annotation_sumologic.com/dropRegex"] = "^2021-01-01 DEBUG"
+	attributes["pod_annotation_sumologic.com/rateLimit"] = "2"
+	sp := newSourceProcessor(newProcessorCreateSettings(), createConfig())
+	sp.sampler.now = func() time.Time { return time.Unix(0, 0) }
+
+	logs, err := sp.ProcessLogs(context.Background(), newLogsWithBodies(attributes,
+		"2021-01-01 DEBUG starting",
+		"2021-01-01 DEBUG connecting",
+		"2021-01-01 panic",
+		"goroutine 1",
+		"  main.go:10",
+		"2021-01-01 recovered",
+		"2021-01-01 exiting",
+	))
+	require.NoError(t, err)
+
+	// multiline messages are limited as a whole and dropped records don't use up the limit
+	assert.Equal(t, []string{
+		"2021-01-01 panic\ngoroutine 1\n  main.go:10",
+		"2021-01-01 recovered",
+	}, logBodies(logs))
+}
+
+func TestTracesAndMetricsNotSampled(t *testing.T) {
+	config := createConfig()
+	config.SamplingRules = []SamplingRuleConfig{
+		{RateLimit: 1, SamplingPercentage: ptr(0.0)},
+	}
+	sp := newSourceProcessor(newProcessorCreateSettings(), config)
+	sp.sampler.now = func() time.Time { return time.Unix(0, 0) }
+
+	traces := newTraceDataWithSpans(k8sLabels, nil)
+	traces.ResourceSpans().At(0).ScopeSpans().At(0).Spans().AppendEmpty()
+	traces, err := sp.ProcessTraces(context.Background(), traces)
+	require.NoError(t, err)
+	assert.Equal(t, 2, traces.SpanCount())
+
+	metrics := newMetricsDataWithMetrics(k8sLabels, 2)
+	metrics, err = sp.ProcessMetrics(context.Background(), metrics)
+	require.NoError(t, err)
+	assert.Equal(t, 2, metrics.MetricCount())
+}
+
+func newMetricsDataWithMetrics(resourceAttrs map[string]string, n int) pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	for k, v := range resourceAttrs {
+		rm.Resource().Attributes().PutStr(k, v)
+	}
+	metrics := rm.ScopeMetrics().AppendEmpty().Metrics()
+	for i := 0; i < n; i++ {
+		metrics.AppendEmpty().SetName("metric")
+	}
+	return md
+}
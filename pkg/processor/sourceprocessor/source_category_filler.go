@@ -26,7 +26,7 @@ import (
 type sourceCategoryFiller struct {
 	logger                       *zap.Logger
 	valueTemplate                string
-	templateAttributes           []string
+	template                     sourceTemplate
 	prefix                       string
 	dashReplacement              string
 	annotationPrefix             string
@@ -38,13 +38,10 @@ type sourceCategoryFiller struct {
 
 // newSourceCategoryFiller creates a new sourceCategoryFiller.
 func newSourceCategoryFiller(cfg *Config, logger *zap.Logger) sourceCategoryFiller {
-
-	templateAttributes := extractTemplateAttributes(cfg.SourceCategoryPrefix + cfg.SourceCategory)
-
 	return sourceCategoryFiller{
 		logger:                       logger,
 		valueTemplate:                cfg.SourceCategory,
-		templateAttributes:           templateAttributes,
+		template:                     parseTemplateOrLiteral(cfg.SourceCategoryPrefix + cfg.SourceCategory),
 		prefix:                       cfg.SourceCategoryPrefix,
 		dashReplacement:              cfg.SourceCategoryReplaceDash,
 		annotationPrefix:             cfg.AnnotationPrefix,
@@ -55,15 +52,6 @@ func newSourceCategoryFiller(cfg *Config, logger *zap.Logger) sourceCategoryFill
 	}
 }
 
-func extractTemplateAttributes(template string) []string {
-	attributeMatches := formatRegex.FindAllStringSubmatch(template, -1)
-	attributes := make([]string, 0, len(attributeMatches))
-	for _, matchset := range attributeMatches {
-		attributes = append(attributes, matchset[1])
-	}
-	return attributes
-}
-
 // fill takes a collection of attributes for a record and adds to it a new attribute with the source category for the record.
 //
 // The source category is retrieved from one of the following locations, listed in descending order of precedence:
@@ -78,7 +66,7 @@ func (f *sourceCategoryFiller) fill(attributes *pcommon.Map) {
 		return
 	}
 
-	var templateAttributes []string
+	var template sourceTemplate
 	doesUseAnnotation := false
 
 	// get sourceCategory and sourceCategoryPrefix from pod annotation
@@ -90,12 +78,12 @@ func (f *sourceCategoryFiller) fill(attributes *pcommon.Map) {
 	}
 
 	if doesUseAnnotation {
-		templateAttributes = extractTemplateAttributes(valueTemplate)
+		template = parseTemplateOrLiteral(valueTemplate)
 	} else {
-		templateAttributes = f.templateAttributes
+		template = f.template
 	}
 
-	sourceCategoryValue := f.replaceTemplateAttributes(valueTemplate, templateAttributes, attributes)
+	sourceCategoryValue := template.render(*attributes)
 
 	dashReplacement := f.getSourceCategoryDashReplacement(attributes)
 	sourceCategoryValue = strings.ReplaceAll(sourceCategoryValue, "-", dashReplacement)
@@ -160,23 +148,6 @@ func (f *sourceCategoryFiller) getSourceCategoryFromContainerAnnotation(attribut
 	return ""
 }
 
-func (f *sourceCategoryFiller) replaceTemplateAttributes(template string, templateAttributes []string, attributes *pcommon.Map) string {
-	replacerArgs := make([]string, len(templateAttributes)*2)
-	for i, templateAttribute := range templateAttributes {
-		attributeValue, found := attributes.Get(templateAttribute)
-		var attributeValueString string
-		if found {
-			attributeValueString = attributeValue.Str()
-		} else {
-			attributeValueString = "undefined"
-		}
-		replacerArgs[i*2] = fmt.Sprintf("%%{%s}", templateAttribute)
-		replacerArgs[i*2+1] = attributeValueString
-	}
-
-	return strings.NewReplacer(replacerArgs...).Replace(template)
-}
-
 func getAnnotationAttributeValue(annotationAttributePrefix string, annotation string, attributes *pcommon.Map) (string, bool) {
 	annotationAttribute, found := attributes.Get(annotationAttributePrefix + annotation)
 	if found {
@@ -28,9 +28,10 @@ func TestNewSourceCategoryFiller(t *testing.T) {
 
 	filler := newSourceCategoryFiller(cfg, zap.NewNop())
 
-	assert.Len(t, filler.templateAttributes, 2)
-	assert.Equal(t, "k8s.namespace.name", filler.templateAttributes[0])
-	assert.Equal(t, "k8s.pod.uid", filler.templateAttributes[1])
+	templateAttributes := filler.template.attributes()
+	assert.Len(t, templateAttributes, 2)
+	assert.Equal(t, "k8s.namespace.name", templateAttributes[0])
+	assert.Equal(t, "k8s.pod.uid", templateAttributes[1])
 }
 
 func TestFill(t *testing.T) {
@@ -16,7 +16,6 @@ package sourceprocessor
 
 import (
 	"context"
-	"encoding/json"
 	"log"
 	"regexp"
 	"strings"
@@ -40,22 +39,28 @@ type sourceKeys struct {
 	podTemplateHashKey        string
 }
 
-// dockerLog represents log from k8s using docker log driver send by FluentBit
-type dockerLog struct {
-	Stream string
-	Time   string
-	Log    string
-}
-
 type sourceProcessor struct {
-	logger               *zap.Logger
-	collector            string
-	sourceCategoryFiller sourceCategoryFiller
-	sourceNameFiller     attributeFiller
-	sourceHostFiller     attributeFiller
-
-	exclude map[string]*regexp.Regexp
-	keys    sourceKeys
+	logger    *zap.Logger
+	collector string
+
+	mode                 string
+	sourceRules          []sourceRule
+	kubernetesSourceRule sourceRule
+	// hostSourceRules are the source rules with the templates not set in them taken from the host mode
+	// configuration, in the same order as sourceRules
+	hostSourceRules []sourceRule
+	hostSourceRule  sourceRule
+
+	exclude         map[string]*regexp.Regexp
+	keys            sourceKeys
+	envelopeParsers []envelopeParser
+
+	cache   *resourceCache
+	sampler *sampler
+
+	containerAnnotations ContainerAnnotationsConfig
+	// processedKeys are the keys of attributes set by processResource
+	processedKeys []string
 }
 
 const (
@@ -70,6 +75,12 @@ const (
 	includeAnnotation = "sumologic.com/include"
 	excludeAnnotation = "sumologic.com/exclude"
 
+	modeAuto       = "auto"
+	modeKubernetes = "kubernetes"
+	modeHost       = "host"
+
+	kubernetesPodAttributePrefix = "k8s.pod."
+
 	collectorKey      = "_collector"
 	sourceCategoryKey = "_sourceCategory"
 	sourceHostKey     = "_sourceHost"
@@ -105,14 +116,43 @@ func newSourceProcessor(set processor.Settings, cfg *Config) *sourceProcessor {
 		}
 	}
 
+	envelopeParsers := make([]envelopeParser, 0, len(cfg.EnvelopeParsers))
+	for _, parserCfg := range cfg.EnvelopeParsers {
+		parser, err := newEnvelopeParser(parserCfg)
+		if err != nil {
+			set.Logger.Error("Skipping invalid envelope parser", zap.Error(err))
+			continue
+		}
+		envelopeParsers = append(envelopeParsers, parser)
+	}
+
+	var cache *resourceCache
+	if cfg.Cache.Enabled {
+		var err error
+		if cache, err = newResourceCache(cfg.Cache.Size); err != nil {
+			set.Logger.Error("Failed to create resource cache, continuing without it", zap.Error(err))
+		}
+	}
+
 	return &sourceProcessor{
-		logger:               set.Logger,
-		collector:            cfg.Collector,
-		keys:                 keys,
-		sourceHostFiller:     createSourceHostFiller(cfg),
-		sourceCategoryFiller: newSourceCategoryFiller(cfg, set.Logger),
-		sourceNameFiller:     createSourceNameFiller(cfg),
+		logger:      set.Logger,
+		collector:   cfg.Collector,
+		keys:        keys,
+		mode:        cfg.Mode,
+		sourceRules: newSourceRules(cfg, set.Logger),
+
+		kubernetesSourceRule: newSourceRule(cfg, SourceRuleConfig{}, set.Logger),
+		hostSourceRules:      newHostSourceRules(cfg, set.Logger),
+		hostSourceRule:       newHostSourceRule(cfg, set.Logger),
 		exclude:              exclude,
+		envelopeParsers:      envelopeParsers,
+		cache:                cache,
+		sampler:              newSampler(cfg, set.Logger),
+
+		containerAnnotations: cfg.ContainerAnnotations,
+		processedKeys: []string{
+			keys.podNameKey, collectorKey, sourceHostKey, sourceCategoryKey, sourceNameKey,
+		},
 	}
 }
 
@@ -123,9 +163,6 @@ func (sp *sourceProcessor) fillOtherMeta(atts pcommon.Map) {
 }
 
 func (sp *sourceProcessor) isFilteredOut(atts pcommon.Map) bool {
-	// TODO: This is quite inefficient when done for each package (ore even more so, span) separately.
-	// It should be moved to K8S Meta Processor and done once per new pod/changed pod
-
 	isFiltered, useAnnotation := sp.isFilteredOutUsingAnnotation(atts, sp.annotationAttribute)
 	if useAnnotation {
 		return isFiltered
@@ -189,8 +226,7 @@ func (sp *sourceProcessor) ProcessTraces(ctx context.Context, td ptrace.Traces)
 		observability.RecordResourceSpansProcessed()
 
 		rs := rss.At(i)
-		res := sp.processResource(rs.Resource())
-		atts := res.Attributes()
+		entry := sp.processResourceAndFilter(rs.Resource())
 
 		ss := rs.ScopeSpans()
 		totalSpans := 0
@@ -199,7 +235,7 @@ func (sp *sourceProcessor) ProcessTraces(ctx context.Context, td ptrace.Traces)
 			totalSpans += ils.Spans().Len()
 		}
 
-		if sp.isFilteredOut(atts) {
+		if entry.filteredOut {
 			rs.ScopeSpans().RemoveIf(func(ptrace.ScopeSpans) bool { return true })
 			observability.RecordFilteredOutN(totalSpans)
 		} else {
@@ -216,10 +252,8 @@ func (sp *sourceProcessor) ProcessMetrics(ctx context.Context, md pmetric.Metric
 
 	for i := 0; i < rss.Len(); i++ {
 		rs := rss.At(i)
-		res := sp.processResource(rs.Resource())
-		atts := res.Attributes()
-
-		if sp.isFilteredOut(atts) {
+		entry := sp.processResourceAndFilter(rs.Resource())
+		if entry.filteredOut {
 			rs.ScopeMetrics().RemoveIf(func(pmetric.ScopeMetrics) bool { return true })
 		}
 	}
@@ -231,72 +265,120 @@ func (sp *sourceProcessor) ProcessMetrics(ctx context.Context, md pmetric.Metric
 func (sp *sourceProcessor) ProcessLogs(ctx context.Context, md plog.Logs) (plog.Logs, error) {
 	rss := md.ResourceLogs()
 
-	var dockerLog dockerLog
-
 	for i := 0; i < rss.Len(); i++ {
 		rs := rss.At(i)
-		res := sp.processResource(rs.Resource())
-		atts := res.Attributes()
-
-		if sp.isFilteredOut(atts) {
+		entry := sp.processResourceAndFilter(rs.Resource())
+		if entry.filteredOut {
 			rs.ScopeLogs().RemoveIf(func(plog.ScopeLogs) bool { return true })
 		}
 
-		// Due to fluent-bit configuration for sumologic kubernetes collection,
-		// logs from kubernetes with docker log driver are send as json with
-		// `log`, `stream` and `time` keys.
-		// We would like to extract `time` and `stream` to record level attributes
-		// and treat `log` as body of the log
-		//
-		// Related issue: https://github.com/SumoLogic/sumologic-kubernetes-collection/issues/1758
-		// ToDo: remove this functionality when the issue is resolved
-
+		// records are sampled only once they are unwrapped, merged and filtered by directives,
+		// so that multiline messages are kept whole and dropped records don't count towards the limits
 		sls := rs.ScopeLogs()
 		for j := 0; j < sls.Len(); j++ {
-			sl := sls.At(j)
-			logs := sl.LogRecords()
-			for k := 0; k < logs.Len(); k++ {
-				log := logs.At(k)
-				if log.Body().Type() == pcommon.ValueTypeStr {
-					err := json.Unmarshal([]byte(log.Body().Str()), &dockerLog)
-
-					// If there was any parsing error or any of the expected key have no value
-					// skip extraction and leave log unchanged
-					if err != nil || dockerLog.Stream == "" || dockerLog.Time == "" || dockerLog.Log == "" {
-						continue
-					}
-
-					// Extract `stream` and `time` as record level attributes
-					log.Attributes().PutStr("stream", dockerLog.Stream)
-					log.Attributes().PutStr("time", dockerLog.Time)
-
-					// Set log body to `log` content
-					log.Body().SetStr(strings.TrimSpace(dockerLog.Log))
+			logs := sls.At(j).LogRecords()
+			if len(sp.envelopeParsers) > 0 {
+				for k := 0; k < logs.Len(); k++ {
+					sp.parseEnvelope(logs.At(k))
 				}
 			}
+			if entry.logDirectives != nil {
+				entry.logDirectives.apply(logs)
+			}
+		}
+
+		if limiter := sp.limiter(entry); limiter != nil {
+			for j := 0; j < sls.Len(); j++ {
+				sls.At(j).LogRecords().RemoveIf(func(plog.LogRecord) bool { return !limiter.allow() })
+			}
+			limiter.report()
 		}
 	}
 
 	return md, nil
 }
 
+// parseEnvelope unwraps the log record using the first envelope parser recognizing its body.
+func (sp *sourceProcessor) parseEnvelope(log plog.LogRecord) {
+	if log.Body().Type() != pcommon.ValueTypeStr {
+		return
+	}
+	body := log.Body().Str()
+	for _, parser := range sp.envelopeParsers {
+		if parser.parse(body, log) {
+			return
+		}
+	}
+}
+
+// processResourceAndFilter processes the resource and returns the result, including whether its records
+// should be filtered out. Results are cached, so that resources with the same attributes are processed only once.
+func (sp *sourceProcessor) processResourceAndFilter(res pcommon.Resource) resourceCacheEntry {
+	atts := res.Attributes()
+	if sp.cache == nil {
+		sp.processResource(res)
+		return sp.newResourceCacheEntry(atts)
+	}
+
+	key := resourceCacheKey(atts)
+	if entry, ok := sp.cache.get(key); ok {
+		entry.apply(atts)
+		return entry
+	}
+
+	sp.processResource(res)
+	entry := sp.newResourceCacheEntry(atts)
+	entry.attributes = make(map[string]string, len(sp.processedKeys))
+	for _, key := range sp.processedKeys {
+		if value, ok := atts.Get(key); ok && value.Type() == pcommon.ValueTypeStr {
+			entry.attributes[key] = value.Str()
+		}
+	}
+	sp.cache.add(key, entry)
+	return entry
+}
+
+// newResourceCacheEntry creates the result of processing a resource with the processed attributes.
+func (sp *sourceProcessor) newResourceCacheEntry(atts pcommon.Map) resourceCacheEntry {
+	entry := resourceCacheEntry{
+		filteredOut: sp.isFilteredOut(atts),
+	}
+	if !entry.filteredOut {
+		entry.sampling = sp.sampler.policy(atts, sp.keys.annotationPrefix, sp.keys.namespaceAnnotationPrefix)
+		entry.logDirectives = sp.logDirectives(atts)
+	}
+	if sourceCategory, ok := atts.Get(sourceCategoryKey); ok {
+		entry.sourceCategory = sourceCategory.Str()
+	}
+	return entry
+}
+
+// limiter returns a limiter for log records of the processed resource, or nil if they aren't limited.
+func (sp *sourceProcessor) limiter(entry resourceCacheEntry) *recordLimiter {
+	if entry.filteredOut || entry.sampling == nil {
+		return nil
+	}
+	return sp.sampler.limiter(entry.sampling, entry.sourceCategory)
+}
+
 // processResource performs multiple actions on resource in the following order:
 //   - enrich pod name, so it can be used in templates
 //   - set metadata (collector name), so it can be used in templates as well
-//   - fills source attributes based on config or annotations
+//   - fills source attributes based on annotations or the first matching source rule
 func (sp *sourceProcessor) processResource(res pcommon.Resource) pcommon.Resource {
 	atts := res.Attributes()
 
 	sp.enrichPodName(&atts)
 	sp.fillOtherMeta(atts)
 
-	sp.sourceHostFiller.fillResourceOrUseAnnotation(&atts,
+	rule := sp.matchSourceRule(atts)
+	rule.sourceHostFiller.fillResourceOrUseAnnotation(&atts,
 		sp.annotationAttribute(sourceHostSpecialAnnotation),
 		sp.NamespaceAnnotationAttribute(sourceHostSpecialAnnotation),
 	)
-	sp.sourceCategoryFiller.fill(&atts)
+	rule.sourceCategoryFiller.fill(&atts)
 
-	sp.sourceNameFiller.fillResourceOrUseAnnotation(&atts,
+	rule.sourceNameFiller.fillResourceOrUseAnnotation(&atts,
 		sp.annotationAttribute(sourceNameSpecialAnnotation),
 		sp.NamespaceAnnotationAttribute(sourceNameSpecialAnnotation),
 	)
@@ -304,6 +386,44 @@ func (sp *sourceProcessor) processResource(res pcommon.Resource) pcommon.Resourc
 	return res
 }
 
+// matchSourceRule returns the first source rule matching the attributes.
+// If none of them matches, the rule with the top level configuration is returned.
+// For resources not coming from Kubernetes, the host mode configuration is used instead
+// of the top level one, both for templates not set in the matching rule and when none matches.
+func (sp *sourceProcessor) matchSourceRule(atts pcommon.Map) *sourceRule {
+	rules, defaultRule := sp.sourceRules, &sp.kubernetesSourceRule
+	if sp.isHostResource(atts) {
+		rules, defaultRule = sp.hostSourceRules, &sp.hostSourceRule
+	}
+	for i := range rules {
+		if rules[i].matches(atts) {
+			return &rules[i]
+		}
+	}
+	return defaultRule
+}
+
+// isHostResource returns true if the resource should be processed in the host mode.
+// In the auto mode, resources without any Kubernetes pod attributes are processed in the host mode.
+func (sp *sourceProcessor) isHostResource(atts pcommon.Map) bool {
+	switch sp.mode {
+	case modeHost:
+		return true
+	case modeKubernetes:
+		return false
+	}
+
+	if _, ok := atts.Get(sp.keys.podKey); ok {
+		return false
+	}
+	isKubernetes := false
+	atts.Range(func(k string, _ pcommon.Value) bool {
+		isKubernetes = strings.HasPrefix(k, kubernetesPodAttributePrefix)
+		return !isKubernetes
+	})
+	return !isKubernetes
+}
+
 // Start is invoked during service startup.
 func (*sourceProcessor) Start(_context context.Context, _host component.Host) error {
 	return nil
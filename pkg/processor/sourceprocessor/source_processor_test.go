@@ -175,6 +175,8 @@ func TestLogsSourceHostKey(t *testing.T) {
 
 	t.Run("works using existing resource attribute", func(t *testing.T) {
 		config := NewFactory().CreateDefaultConfig().(*Config)
+		// systemd logs have no pod attributes, so the top level templates are only used in the kubernetes mode
+		config.Mode = modeKubernetes
 		config.SourceName = "will-it-work-%{_HOSTNAME}"
 		config.SourceHost = "%{_HOSTNAME}"
 
@@ -207,6 +209,7 @@ func TestLogsSourceHostKey(t *testing.T) {
 
 	t.Run("does not work using record attribute", func(t *testing.T) {
 		config := NewFactory().CreateDefaultConfig().(*Config)
+		config.Mode = modeKubernetes
 		config.SourceName = "will-it-work-%{_CMDLINE}"
 		config.SourceHost = "%{_CMDLINE}"
 
@@ -658,6 +661,273 @@ func TestSourceCategoryTemplateWithCustomAttribute(t *testing.T) {
 	})
 }
 
+func TestSourceRules(t *testing.T) {
+	config := createDefaultConfig().(*Config)
+	config.SourceRules = []SourceRuleConfig{
+		{
+			Match: map[string]string{
+				"k8s.namespace.name": "^kube-.*$",
+			},
+			SourceCategory: "system/%{k8s.namespace.name}",
+		},
+		{
+			Match: map[string]string{
+				"k8s.namespace.name":  "^namespace-1$",
+				"k8s.deployment.name": "^api$",
+			},
+			SourceName:           "api.%{k8s.container.name}",
+			SourceCategory:       "%{k8s.deployment.name}",
+			SourceCategoryPrefix: "apps/",
+		},
+		{
+			Match: map[string]string{
+				"k8s.namespace.name": "^namespace-1$",
+			},
+			SourceHost: "host-%{k8s.namespace.name}",
+		},
+	}
+	sp := newSourceProcessor(newProcessorCreateSettings(), config)
+
+	testcases := []struct {
+		name                   string
+		attributes             map[string]string
+		expectedSourceHost     string
+		expectedSourceName     string
+		expectedSourceCategory string
+	}{
+		{
+			name: "first rule",
+			attributes: map[string]string{
+				"k8s.namespace.name": "kube-system",
+				"k8s.pod.name":       "coredns-5db86d8867-sdqlj",
+				"k8s.container.name": "coredns",
+			},
+			expectedSourceHost:     "undefined",
+			expectedSourceName:     "kube-system.coredns-5db86d8867-sdqlj.coredns",
+			expectedSourceCategory: "kubernetes/system/kube/system",
+		},
+		{
+			name: "all conditions must match",
+			attributes: map[string]string{
+				"k8s.namespace.name":  "namespace-1",
+				"k8s.deployment.name": "api",
+				"k8s.pod.name":        "api-5db86d8867-sdqlj",
+				"k8s.container.name":  "server",
+			},
+			expectedSourceHost:     "undefined",
+			expectedSourceName:     "api.server",
+			expectedSourceCategory: "apps/api",
+		},
+		{
+			name: "first matching rule is used",
+			attributes: map[string]string{
+				"k8s.namespace.name":  "namespace-1",
+				"k8s.deployment.name": "worker",
+				"k8s.pod.name":        "worker-sdqlj",
+				"k8s.container.name":  "worker",
+			},
+			expectedSourceHost:     "host-namespace-1",
+			expectedSourceName:     "namespace-1.worker-sdqlj.worker",
+			expectedSourceCategory: "kubernetes/namespace/1/worker",
+		},
+		{
+			name: "no rule matches",
+			attributes: map[string]string{
+				"k8s.namespace.name": "namespace-2",
+				"k8s.pod.name":       "pod-sdqlj",
+				"k8s.container.name": "container",
+			},
+			expectedSourceHost:     "undefined",
+			expectedSourceName:     "namespace-2.pod-sdqlj.container",
+			expectedSourceCategory: "kubernetes/namespace/2/pod",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			processedTraces, err := sp.ProcessTraces(context.Background(), newTraceData(tc.attributes))
+			require.NoError(t, err)
+
+			attributes := processedTraces.ResourceSpans().At(0).Resource().Attributes()
+			assertAttribute(t, attributes, "_sourceHost", tc.expectedSourceHost)
+			assertAttribute(t, attributes, "_sourceName", tc.expectedSourceName)
+			assertAttribute(t, attributes, "_sourceCategory", tc.expectedSourceCategory)
+		})
+	}
+
+	t.Run("annotations take precedence over rules", func(t *testing.T) {
+		attributes := map[string]string{
+			"k8s.namespace.name":                              "kube-system",
+			"k8s.pod.annotation.sumologic.com/sourceCategory": "annotated",
+		}
+		processedTraces, err := sp.ProcessTraces(context.Background(), newTraceData(attributes))
+		require.NoError(t, err)
+
+		assertAttribute(t, processedTraces.ResourceSpans().At(0).Resource().Attributes(), "_sourceCategory", "kubernetes/annotated")
+	})
+}
+
+func TestHostMode(t *testing.T) {
+	testcases := []struct {
+		name                   string
+		mode                   string
+		attributes             map[string]string
+		expectedSourceHost     string
+		expectedSourceName     string
+		expectedSourceCategory string
+	}{
+		{
+			name: "file",
+			mode: "auto",
+			attributes: map[string]string{
+				"host.name":     "my-host",
+				"log.file.path": "/var/log/my-app/app.log",
+			},
+			expectedSourceHost:     "my-host",
+			expectedSourceName:     "/var/log/my-app/app.log",
+			expectedSourceCategory: "host/undefined",
+		},
+		{
+			name: "systemd unit",
+			mode: "auto",
+			attributes: map[string]string{
+				"host.name":     "my-host",
+				"_SYSTEMD_UNIT": "docker.service",
+			},
+			expectedSourceHost:     "my-host",
+			expectedSourceName:     "docker.service",
+			expectedSourceCategory: "host/docker.service",
+		},
+		{
+			name: "windows event channel",
+			mode: "auto",
+			attributes: map[string]string{
+				"host.name":      "my-host",
+				"winlog.channel": "Security",
+			},
+			expectedSourceHost:     "my-host",
+			expectedSourceName:     "Security",
+			expectedSourceCategory: "host/Security",
+		},
+		{
+			name: "service",
+			mode: "auto",
+			attributes: map[string]string{
+				"host.name":     "my-host",
+				"service.name":  "my-service",
+				"log.file.path": "/var/log/my-service.log",
+			},
+			expectedSourceHost:     "my-host",
+			expectedSourceName:     "/var/log/my-service.log",
+			expectedSourceCategory: "host/my-service",
+		},
+		{
+			name:                   "kubernetes resource in auto mode",
+			mode:                   "auto",
+			attributes:             createK8sLabels(),
+			expectedSourceHost:     "undefined",
+			expectedSourceName:     "namespace-1.pod-5db86d8867-sdqlj.container-1",
+			expectedSourceCategory: "prefix/namespace#1/pod",
+		},
+		{
+			name: "resource without pod attributes in auto mode",
+			mode: "auto",
+			attributes: map[string]string{
+				"host.name":          "my-host",
+				"k8s.namespace.name": "namespace-1",
+				"_SYSTEMD_UNIT":      "kubelet.service",
+			},
+			expectedSourceHost:     "my-host",
+			expectedSourceName:     "kubelet.service",
+			expectedSourceCategory: "host/kubelet.service",
+		},
+		{
+			name: "host mode",
+			mode: "host",
+			attributes: map[string]string{
+				"host.name":          "my-host",
+				"service.name":       "my-service",
+				"k8s.namespace.name": "namespace-1",
+			},
+			expectedSourceHost:     "my-host",
+			expectedSourceName:     "my-service",
+			expectedSourceCategory: "host/my-service",
+		},
+		{
+			name: "kubernetes mode",
+			mode: "kubernetes",
+			attributes: map[string]string{
+				"host.name":    "my-host",
+				"service.name": "my-service",
+			},
+			expectedSourceHost:     "undefined",
+			expectedSourceName:     "undefined.undefined.undefined",
+			expectedSourceCategory: "prefix/undefined/undefined",
+		},
+	}
+
+	assert.Equal(t, modeAuto, createConfig().Mode)
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := createConfig()
+			config.Mode = tc.mode
+			sp := newSourceProcessor(newProcessorCreateSettings(), config)
+
+			processedTraces, err := sp.ProcessTraces(context.Background(), newTraceData(tc.attributes))
+			require.NoError(t, err)
+
+			attributes := processedTraces.ResourceSpans().At(0).Resource().Attributes()
+			assertAttribute(t, attributes, "_sourceHost", tc.expectedSourceHost)
+			assertAttribute(t, attributes, "_sourceName", tc.expectedSourceName)
+			assertAttribute(t, attributes, "_sourceCategory", tc.expectedSourceCategory)
+		})
+	}
+
+	t.Run("source rules take precedence", func(t *testing.T) {
+		config := createConfig()
+		config.Mode = "host"
+		config.SourceRules = []SourceRuleConfig{
+			{
+				Match:          map[string]string{"service.name": "^my-service$"},
+				SourceCategory: "services/%{service.name}",
+			},
+		}
+		sp := newSourceProcessor(newProcessorCreateSettings(), config)
+
+		processedTraces, err := sp.ProcessTraces(context.Background(), newTraceData(map[string]string{"service.name": "my-service"}))
+		require.NoError(t, err)
+
+		attributes := processedTraces.ResourceSpans().At(0).Resource().Attributes()
+		assertAttribute(t, attributes, "_sourceCategory", "host/services/my-service")
+	})
+
+	t.Run("templates not set in source rules are taken from the mode of the resource", func(t *testing.T) {
+		config := createConfig()
+		config.Mode = "auto"
+		config.SourceRules = []SourceRuleConfig{
+			{SourceCategory: "custom"},
+		}
+		sp := newSourceProcessor(newProcessorCreateSettings(), config)
+
+		processedTraces, err := sp.ProcessTraces(context.Background(), newTraceData(map[string]string{
+			"host.name":     "my-host",
+			"_SYSTEMD_UNIT": "docker.service",
+		}))
+		require.NoError(t, err)
+		attributes := processedTraces.ResourceSpans().At(0).Resource().Attributes()
+		assertAttribute(t, attributes, "_sourceHost", "my-host")
+		assertAttribute(t, attributes, "_sourceName", "docker.service")
+		assertAttribute(t, attributes, "_sourceCategory", "host/custom")
+
+		processedTraces, err = sp.ProcessTraces(context.Background(), newTraceData(createK8sLabels()))
+		require.NoError(t, err)
+		attributes = processedTraces.ResourceSpans().At(0).Resource().Attributes()
+		assertAttribute(t, attributes, "_sourceName", "namespace-1.pod-5db86d8867-sdqlj.container-1")
+		assertAttribute(t, attributes, "_sourceCategory", "prefix/custom")
+	})
+}
+
 func assertAttribute(t *testing.T, attributes pcommon.Map, attributeName string, expectedValue string) {
 	value, exists := attributes.Get(attributeName)
 
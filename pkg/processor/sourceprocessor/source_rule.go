@@ -0,0 +1,105 @@
+// Copyright 2026 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sourceprocessor
+
+import (
+	"regexp"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.uber.org/zap"
+)
+
+// sourceRule fills source attributes of resources matching its conditions.
+type sourceRule struct {
+	match                map[string]*regexp.Regexp
+	sourceHostFiller     attributeFiller
+	sourceNameFiller     attributeFiller
+	sourceCategoryFiller sourceCategoryFiller
+}
+
+// newSourceRule creates a sourceRule from the rule configuration.
+// Templates which are not set in the rule are taken from the top level configuration.
+func newSourceRule(cfg *Config, ruleCfg SourceRuleConfig, logger *zap.Logger) sourceRule {
+	ruleConfig := *cfg
+	if ruleCfg.SourceHost != "" {
+		ruleConfig.SourceHost = ruleCfg.SourceHost
+	}
+	if ruleCfg.SourceName != "" {
+		ruleConfig.SourceName = ruleCfg.SourceName
+	}
+	if ruleCfg.SourceCategory != "" {
+		ruleConfig.SourceCategory = ruleCfg.SourceCategory
+	}
+	if ruleCfg.SourceCategoryPrefix != "" {
+		ruleConfig.SourceCategoryPrefix = ruleCfg.SourceCategoryPrefix
+	}
+
+	match := make(map[string]*regexp.Regexp, len(ruleCfg.Match))
+	for field, regexStr := range ruleCfg.Match {
+		if r := compileRegex(regexStr); r != nil {
+			match[field] = r
+		}
+	}
+
+	return sourceRule{
+		match:                match,
+		sourceHostFiller:     createSourceHostFiller(&ruleConfig),
+		sourceNameFiller:     createSourceNameFiller(&ruleConfig),
+		sourceCategoryFiller: newSourceCategoryFiller(&ruleConfig, logger),
+	}
+}
+
+// newSourceRules creates the configured source rules.
+func newSourceRules(cfg *Config, logger *zap.Logger) []sourceRule {
+	rules := make([]sourceRule, 0, len(cfg.SourceRules))
+	for _, ruleCfg := range cfg.SourceRules {
+		rules = append(rules, newSourceRule(cfg, ruleCfg, logger))
+	}
+	return rules
+}
+
+// newHostSourceRules creates the configured source rules for resources processed in the host mode.
+// Templates which are not set in a rule are taken from the host mode configuration.
+func newHostSourceRules(cfg *Config, logger *zap.Logger) []sourceRule {
+	return newSourceRules(hostModeConfig(cfg), logger)
+}
+
+// newHostSourceRule creates a rule without conditions using the host mode templates.
+func newHostSourceRule(cfg *Config, logger *zap.Logger) sourceRule {
+	return newSourceRule(hostModeConfig(cfg), SourceRuleConfig{}, logger)
+}
+
+// hostModeConfig returns a copy of the configuration with the top level templates
+// replaced by the host mode ones.
+func hostModeConfig(cfg *Config) *Config {
+	hostConfig := *cfg
+	hostConfig.SourceHost = cfg.Host.SourceHost
+	hostConfig.SourceName = cfg.Host.SourceName
+	hostConfig.SourceCategory = cfg.Host.SourceCategory
+	hostConfig.SourceCategoryPrefix = cfg.Host.SourceCategoryPrefix
+	hostConfig.SourceCategoryReplaceDash = cfg.Host.SourceCategoryReplaceDash
+	return &hostConfig
+}
+
+// matches returns true if all of the rule's attributes are present
+// and their values match the corresponding regexes.
+func (r *sourceRule) matches(atts pcommon.Map) bool {
+	for field, re := range r.match {
+		if _, ok := matchFieldByRegex(atts, field, re); !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,327 @@
+// Copyright 2026 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sourceprocessor
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+// undefinedValue replaces placeholders for which none of the attributes were found
+// and no default value was provided.
+const undefinedValue = "undefined"
+
+// sourceTemplate is a parsed template with `%{...}` placeholders.
+//
+// A placeholder has the following syntax:
+//
+//	%{attr1,attr2:-default|function1|function2(arg1, arg2)}
+//
+// The value of the first of the comma separated attributes that is present and not empty is used.
+// When none of them is, the default value after `:-` is used. Without a default, the result
+// is empty if any of the attributes is present and `undefined` otherwise.
+// The value is then transformed by the functions, in order.
+type sourceTemplate struct {
+	parts []templatePart
+}
+
+// templatePart is either a literal string or a placeholder.
+type templatePart struct {
+	literal     string
+	placeholder *templatePlaceholder
+}
+
+type templatePlaceholder struct {
+	attributes   []string
+	defaultValue string
+	hasDefault   bool
+	functions    []templateFunction
+}
+
+type templateFunction func(string) string
+
+// parseTemplate parses the template, returning an error for malformed placeholders.
+func parseTemplate(template string) (sourceTemplate, error) {
+	var parts []templatePart
+	rest := template
+	for {
+		start := strings.Index(rest, "%{")
+		if start < 0 {
+			break
+		}
+		if start > 0 {
+			parts = append(parts, templatePart{literal: rest[:start]})
+		}
+
+		p := &placeholderParser{input: rest[start+2:]}
+		placeholder, err := p.parse()
+		if err != nil {
+			return sourceTemplate{}, fmt.Errorf("invalid placeholder in template %q: %w", template, err)
+		}
+		parts = append(parts, templatePart{placeholder: placeholder})
+		rest = p.input[p.pos:]
+	}
+	if rest != "" {
+		parts = append(parts, templatePart{literal: rest})
+	}
+	return sourceTemplate{parts: parts}, nil
+}
+
+// parseTemplateOrLiteral parses the template, falling back to using it as a literal string
+// if it's malformed, e.g. when it comes from an annotation.
+func parseTemplateOrLiteral(template string) sourceTemplate {
+	t, err := parseTemplate(template)
+	if err != nil {
+		return sourceTemplate{parts: []templatePart{{literal: template}}}
+	}
+	return t
+}
+
+// attributes returns the names of attributes used in the template, in order.
+func (t sourceTemplate) attributes() []string {
+	var attributes []string
+	for _, part := range t.parts {
+		if part.placeholder != nil {
+			attributes = append(attributes, part.placeholder.attributes...)
+		}
+	}
+	return attributes
+}
+
+// isEmpty returns true if rendering the template always results in an empty string.
+func (t sourceTemplate) isEmpty() bool {
+	return len(t.parts) == 0
+}
+
+// render replaces placeholders in the template with values based on the attributes.
+func (t sourceTemplate) render(atts pcommon.Map) string {
+	var sb strings.Builder
+	for _, part := range t.parts {
+		if part.placeholder == nil {
+			sb.WriteString(part.literal)
+			continue
+		}
+		sb.WriteString(part.placeholder.render(atts))
+	}
+	return sb.String()
+}
+
+func (p *templatePlaceholder) render(atts pcommon.Map) string {
+	value, found := "", false
+	for _, attribute := range p.attributes {
+		if v, ok := atts.Get(attribute); ok {
+			value, found = v.AsString(), true
+			if value != "" {
+				break
+			}
+		}
+	}
+	switch {
+	case value != "":
+	case p.hasDefault:
+		value = p.defaultValue
+	case !found:
+		value = undefinedValue
+	}
+
+	for _, f := range p.functions {
+		value = f(value)
+	}
+	return value
+}
+
+// placeholderParser parses a single placeholder, starting right after `%{`.
+type placeholderParser struct {
+	input string
+	pos   int
+}
+
+func (p *placeholderParser) parse() (*templatePlaceholder, error) {
+	placeholder := &templatePlaceholder{}
+
+	for {
+		name := p.readWhile(isAttributeNameChar)
+		if name == "" {
+			return nil, fmt.Errorf("expected attribute name at %q", p.input[p.pos:])
+		}
+		placeholder.attributes = append(placeholder.attributes, name)
+		if !p.consume(",") {
+			break
+		}
+	}
+
+	if p.consume(":-") {
+		placeholder.defaultValue = p.readWhile(func(r rune) bool { return r != '|' && r != '}' })
+		placeholder.hasDefault = true
+	}
+
+	p.skipSpaces()
+	for p.consume("|") {
+		p.skipSpaces()
+		f, err := p.parseFunction()
+		if err != nil {
+			return nil, err
+		}
+		placeholder.functions = append(placeholder.functions, f)
+		p.skipSpaces()
+	}
+
+	if !p.consume("}") {
+		return nil, fmt.Errorf("expected \"}\" at %q", p.input[p.pos:])
+	}
+	return placeholder, nil
+}
+
+func (p *placeholderParser) parseFunction() (templateFunction, error) {
+	name := p.readWhile(func(r rune) bool { return unicode.IsLetter(r) || r == '_' })
+
+	var args []string
+	if p.consume("(") {
+		for {
+			p.skipSpaces()
+			if p.consume(")") {
+				break
+			}
+			arg, err := p.parseArgument()
+			if err != nil {
+				return nil, fmt.Errorf("function %s: %w", name, err)
+			}
+			args = append(args, arg)
+			p.skipSpaces()
+			if p.consume(",") {
+				continue
+			}
+			if !p.consume(")") {
+				return nil, fmt.Errorf("function %s: expected \",\" or \")\" at %q", name, p.input[p.pos:])
+			}
+			break
+		}
+	}
+
+	return newTemplateFunction(name, args)
+}
+
+// parseArgument parses a double quoted string or a number.
+func (p *placeholderParser) parseArgument() (string, error) {
+	if !p.consume(`"`) {
+		arg := p.readWhile(func(r rune) bool { return unicode.IsDigit(r) || r == '-' })
+		if arg == "" {
+			return "", fmt.Errorf("expected argument at %q", p.input[p.pos:])
+		}
+		return arg, nil
+	}
+
+	var sb strings.Builder
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		p.pos++
+		switch {
+		case c == '"':
+			return sb.String(), nil
+		case c == '\\' && p.pos < len(p.input):
+			sb.WriteByte(p.input[p.pos])
+			p.pos++
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return "", fmt.Errorf("unterminated string argument")
+}
+
+func (p *placeholderParser) consume(s string) bool {
+	if strings.HasPrefix(p.input[p.pos:], s) {
+		p.pos += len(s)
+		return true
+	}
+	return false
+}
+
+func (p *placeholderParser) readWhile(f func(rune) bool) string {
+	start := p.pos
+	for p.pos < len(p.input) {
+		r, size := utf8.DecodeRuneInString(p.input[p.pos:])
+		if !f(r) {
+			break
+		}
+		p.pos += size
+	}
+	return p.input[start:p.pos]
+}
+
+func (p *placeholderParser) skipSpaces() {
+	p.readWhile(func(r rune) bool { return r == ' ' })
+}
+
+func isAttributeNameChar(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.' || r == '/' || r == '-'
+}
+
+// newTemplateFunction creates a template function with the given name and arguments.
+func newTemplateFunction(name string, args []string) (templateFunction, error) {
+	switch name {
+	case "lower":
+		if len(args) != 0 {
+			return nil, fmt.Errorf("function lower takes no arguments")
+		}
+		return strings.ToLower, nil
+	case "upper":
+		if len(args) != 0 {
+			return nil, fmt.Errorf("function upper takes no arguments")
+		}
+		return strings.ToUpper, nil
+	case "trim":
+		switch len(args) {
+		case 0:
+			return strings.TrimSpace, nil
+		case 1:
+			cutset := args[0]
+			return func(s string) string { return strings.Trim(s, cutset) }, nil
+		default:
+			return nil, fmt.Errorf("function trim takes at most 1 argument")
+		}
+	case "replace":
+		if len(args) != 2 {
+			return nil, fmt.Errorf("function replace takes 2 arguments: regex and replacement")
+		}
+		re, err := regexp.Compile(args[0])
+		if err != nil {
+			return nil, fmt.Errorf("function replace: %w", err)
+		}
+		replacement := args[1]
+		return func(s string) string { return re.ReplaceAllString(s, replacement) }, nil
+	case "truncate":
+		if len(args) != 1 {
+			return nil, fmt.Errorf("function truncate takes 1 argument: maximum length")
+		}
+		length, err := strconv.Atoi(args[0])
+		if err != nil || length < 0 {
+			return nil, fmt.Errorf("function truncate: invalid length %q", args[0])
+		}
+		return func(s string) string {
+			if runes := []rune(s); len(runes) > length {
+				return string(runes[:length])
+			}
+			return s
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown function %q", name)
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2026 Sumo Logic, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sourceprocessor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+func TestTemplateRender(t *testing.T) {
+	attrs := pcommon.NewMap()
+	attrs.PutStr("k8s.namespace.name", "Namespace-1")
+	attrs.PutStr("k8s.pod.name", "  pod-5db86d8867-sdqlj  ")
+	attrs.PutStr("k8s.deployment.name", "")
+	attrs.PutStr("k8s.pod.label.app", "my_app")
+	attrs.PutInt("k8s.pod.restarts", 3)
+
+	testcases := []struct {
+		template string
+		expected string
+	}{
+		{template: "", expected: ""},
+		{template: "no placeholders", expected: "no placeholders"},
+		{template: "%{k8s.namespace.name}/%{k8s.pod.label.app}", expected: "Namespace-1/my_app"},
+		{template: "%{k8s.pod.restarts}", expected: "3"},
+		{template: "%{missing}", expected: "undefined"},
+		{template: "%{k8s.deployment.name}", expected: ""},
+		{template: "%{missing:-fallback}", expected: "fallback"},
+		{template: "%{k8s.deployment.name:-fallback}", expected: "fallback"},
+		{template: "%{missing:-}", expected: ""},
+		{template: "%{k8s.namespace.name:-fallback}", expected: "Namespace-1"},
+		{template: "%{k8s.deployment.name,k8s.pod.label.app}", expected: "my_app"},
+		{template: "%{missing,k8s.deployment.name}", expected: ""},
+		{template: "%{missing,other:-none}", expected: "none"},
+		{template: "%{k8s.namespace.name|lower}", expected: "namespace-1"},
+		{template: "%{k8s.namespace.name|upper}", expected: "NAMESPACE-1"},
+		{template: "%{k8s.pod.name|trim}", expected: "pod-5db86d8867-sdqlj"},
+		{template: `%{k8s.pod.label.app|trim("_my")}`, expected: "app"},
+		{template: `%{k8s.pod.name | trim | replace("-[a-z0-9]+-[a-z0-9]+$", "")}`, expected: "pod"},
+		{template: `%{k8s.pod.label.app|replace("(\\w+)_(\\w+)", "${2}.${1}")}`, expected: "app.my"},
+		{template: `%{k8s.pod.label.app|replace("}", "")}`, expected: "my_app"},
+		{template: "%{k8s.namespace.name|truncate(4)}", expected: "Name"},
+		{template: "%{k8s.namespace.name|truncate(40)}", expected: "Namespace-1"},
+		{template: "%{missing:-Default|lower|truncate(3)}", expected: "def"},
+		{template: "a/%{k8s.namespace.name|lower}/%{missing:-b}/c", expected: "a/namespace-1/b/c"},
+		{template: "%{missing:-café}", expected: "café"},
+		{template: "%{missing:-Żółw|lower}", expected: "żółw"},
+		{template: `%{k8s.pod.label.app|replace("_", "→")}`, expected: "my→app"},
+		{template: `%{missing:-ünïcode| trim("ü") |truncate(4)}`, expected: "nïco"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.template, func(t *testing.T) {
+			template, err := parseTemplate(tc.template)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, template.render(attrs))
+		})
+	}
+}
+
+func TestTemplateAttributes(t *testing.T) {
+	template, err := parseTemplate("%{a}/%{b,c:-d|lower}/%{e}")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c", "e"}, template.attributes())
+}
+
+func TestTemplateParseErrors(t *testing.T) {
+	testcases := []struct {
+		template      string
+		expectedError string
+	}{
+		{template: "%{}", expectedError: "expected attribute name"},
+		{template: "%{a,}", expectedError: "expected attribute name"},
+		{template: "%{a", expectedError: `expected "}"`},
+		{template: "%{a b}", expectedError: `expected "}"`},
+		{template: "%{a|unknown}", expectedError: `unknown function "unknown"`},
+		{template: "%{a|lower(1)}", expectedError: "function lower takes no arguments"},
+		{template: `%{a|replace("x")}`, expectedError: "function replace takes 2 arguments"},
+		{template: `%{a|replace("(", "")}`, expectedError: "function replace: error parsing regexp"},
+		{template: `%{a|replace("x", "y}`, expectedError: "unterminated string argument"},
+		{template: "%{a|truncate(x)}", expectedError: "expected argument"},
+		{template: "%{a|truncate(-1)}", expectedError: "invalid length"},
+		{template: "%{a|truncate(1}", expectedError: `expected "," or ")"`},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.template, func(t *testing.T) {
+			_, err := parseTemplate(tc.template)
+			assert.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestTemplateOrLiteral(t *testing.T) {
+	template := parseTemplateOrLiteral("%{a b}")
+	assert.Equal(t, "%{a b}", template.render(pcommon.NewMap()))
+}